@@ -49,6 +49,10 @@ func main() {
 			fmt.Printf("\tkeys:\t%d\n", v.GetNumElements())
 			fmt.Printf("\tstart:\t%s\n", hex.EncodeToString(v.GetFirstKey()))
 			fmt.Printf("\tend:\t%s\n", hex.EncodeToString(v.GetLastKey()))
+			fmt.Printf("\tblocks:\t%d (%d bytes, %d uncompressed, %s)\n",
+				v.GetNumBlocks(), v.GetCompressedBytes(), v.GetUncompressedBytes(), v.GetCodec())
+			fmt.Printf("\tload:\t%s\n", v.GetLoadMethod())
+			fmt.Printf("\tbloom:\t%s\n", v.GetBloom())
 		}
 	}
 }
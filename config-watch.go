@@ -0,0 +1,112 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/curator-go/curator"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// How long to wait before re-watching a zookeeper node after failing to read it.
+const configWatchRetry = 30 * time.Second
+
+/*
+A ConfigWatcher re-fetches the -config-json URL, on an interval or when a zookeeper node changes,
+and, if the collection specs differ from those it last applied, reloads the collections to match.
+A config that is invalid or fails to load changes nothing: the previous collections keep serving
+and the config is retried on the next check.
+*/
+type ConfigWatcher struct {
+	url      string
+	reloader *Reloader
+
+	// Held while checking, so only one check runs at a time.
+	sync.Mutex
+	// The specs most recently applied, or nil if none have been yet.
+	applied *CollectionSpecList
+}
+
+func NewConfigWatcher(url string, reloader *Reloader) *ConfigWatcher {
+	return &ConfigWatcher{url: url, reloader: reloader}
+}
+
+// Check fetches the config and reloads the collections if it changed, returning nil if it didn't.
+func (w *ConfigWatcher) Check() (*ReloadResult, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	specs, err := FetchCollectionSpecs(w.url)
+	if err != nil {
+		return nil, err
+	}
+	if w.applied != nil && reflect.DeepEqual(w.applied, specs) {
+		return nil, nil
+	}
+
+	configs, err := ConfigsFromSpecs(specs)
+	if err != nil {
+		return nil, err
+	}
+	res, err := w.reloader.Apply(configs)
+	if err != nil {
+		return nil, err
+	}
+	w.applied = specs
+	return res, nil
+}
+
+// check checks the config, logging and returning false if it couldn't be applied.
+func (w *ConfigWatcher) check(why string) bool {
+	if _, err := w.Check(); err != nil {
+		log.Printf("[ConfigWatcher] Not applying config (%s): %v\n", why, err)
+		return false
+	}
+	return true
+}
+
+// Poll checks the config every interval, forever.
+func (w *ConfigWatcher) Poll(interval time.Duration) {
+	log.Println("[ConfigWatcher] Polling config every", interval)
+	for range time.Tick(interval) {
+		w.check("polled")
+	}
+}
+
+/*
+Watch checks the config whenever the zookeeper node at path changes, forever. A change that can't
+be applied is retried every configWatchRetry until it is, since the node may not change again.
+*/
+func (w *ConfigWatcher) Watch(client curator.CuratorFramework, path string) {
+	log.Println("[ConfigWatcher] Watching zookeeper node", path)
+
+	changed := make(chan struct{}, 1)
+	watcher := curator.NewWatcher(func(*zk.Event) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	pending := false
+	for {
+		// Watches only fire once, so each change needs a new one.
+		if _, err := client.GetData().UsingWatcher(watcher).ForPath(path); err != nil {
+			log.Printf("[ConfigWatcher] Error watching %s: %v\n", path, err)
+			time.Sleep(configWatchRetry)
+			continue
+		}
+		// Checking after setting the watch means changes made while checking aren't missed.
+		for pending && !w.check("zookeeper node changed") {
+			time.Sleep(configWatchRetry)
+		}
+		pending = false
+
+		<-changed
+		pending = true
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/foursquare/quiver/hfile"
+	"github.com/stretchr/testify/assert"
+)
+
+// A configServer serves whatever config it was last given.
+type configServer struct {
+	sync.Mutex
+	body string
+}
+
+func (c *configServer) set(specs ...SingleCollectionSpec) {
+	buf, _ := json.Marshal(CollectionSpecList{specs})
+	c.Lock()
+	c.body = string(buf)
+	c.Unlock()
+}
+
+func (c *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.Lock()
+	defer c.Unlock()
+	w.Write([]byte(c.body))
+}
+
+func TestConfigWatcher(t *testing.T) {
+	dir := reloadTestFiles(t, "a", "b")
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+
+	cfgs := &configServer{}
+	srv := httptest.NewServer(cfgs)
+	defer srv.Close()
+
+	cfgs.set(SingleCollectionSpec{Collection: "a", Capacity: 1, Url: a})
+	configs, err := ConfigsFromJsonUrl(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := hfile.LoadCollections(configs, "", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl := NewReloader(NewRpcShared(cs), nil, configs, nil)
+	rl.grace = 0
+	w := NewConfigWatcher(srv.URL, rl)
+
+	res, err := w.Check()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, res.Unchanged, "the first check compares against what is served")
+
+	res, err = w.Check()
+	assert.Nil(t, err)
+	assert.Nil(t, res, "an unchanged config shouldn't be reloaded")
+
+	cfgs.set(SingleCollectionSpec{Collection: "a", Capacity: 2, Url: a}, SingleCollectionSpec{Collection: "a", Capacity: 2, Partition: 1, Url: b})
+	res, err = w.Check()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a/1"}, res.Added)
+	assert.Equal(t, []string{"a/0"}, res.Changed)
+	served := rl.shared.CollectionSet()
+	assert.Len(t, served.Collections, 2)
+
+	// Bad configs change nothing, and are retried.
+	for _, bad := range [][]SingleCollectionSpec{
+		{{Collection: "a", Capacity: 2, Url: a}, {Collection: "a", Capacity: 2, Url: b}},
+		{{Collection: "a", Capacity: 2, Partition: 2, Url: a}},
+		{{Capacity: 1, Url: a}},
+		{{Collection: "a", Capacity: 1, Url: filepath.Join(dir, "missing")}},
+		{},
+	} {
+		cfgs.set(bad...)
+		for i := 0; i < 2; i++ {
+			res, err = w.Check()
+			assert.NotNil(t, err, bad)
+			assert.Nil(t, res)
+			assert.True(t, served == rl.shared.CollectionSet(), bad)
+		}
+	}
+
+	cfgs.Lock()
+	cfgs.body = "{not json"
+	cfgs.Unlock()
+	_, err = w.Check()
+	assert.NotNil(t, err)
+
+	// Rolling back to a config seen before works too.
+	cfgs.set(SingleCollectionSpec{Collection: "a", Capacity: 1, Url: a})
+	res, err = w.Check()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a/0"}, res.Changed)
+	assert.Equal(t, []string{"a/1"}, res.Removed)
+	assert.Len(t, rl.shared.CollectionSet().Collections, 1)
+}
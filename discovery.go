@@ -14,7 +14,12 @@ import (
 )
 
 type Registrations struct {
-	existing []*discovery.ServiceDiscovery
+	// Keyed by each collection's discovery path and partition.
+	existing map[string]*discovery.ServiceDiscovery
+
+	// Between Join and Leave, when changes to the served collections should be (un)registered.
+	joined   bool
+	hostname string
 
 	zk curator.CuratorFramework
 
@@ -25,18 +30,22 @@ func (r *Registrations) Connect() {
 	if Settings.zk == "" {
 		log.Fatal("Specified discovery path but not zk?", Settings.zk)
 	}
+	r.zk = connectZookeeper()
+}
 
+func connectZookeeper() curator.CuratorFramework {
 	retryPolicy := curator.NewExponentialBackoffRetry(time.Second, 3, 15*time.Second)
 
-	r.zk = curator.NewClientTimeout(Settings.zk, 15*time.Second, 15*time.Second, retryPolicy)
+	zk := curator.NewClientTimeout(Settings.zk, 15*time.Second, 15*time.Second, retryPolicy)
 
-	if err := r.zk.Start(); err != nil {
+	if err := zk.Start(); err != nil {
 		log.Fatal(err)
-	} else if err := r.zk.ZookeeperClient().BlockUntilConnectedOrTimedOut(); err != nil {
+	} else if err := zk.ZookeeperClient().BlockUntilConnectedOrTimedOut(); err != nil {
 		log.Fatal(err)
 	} else {
-		log.Println("Connected to zookeeper: ", r.zk.ZookeeperClient().Connected())
+		log.Println("Connected to zookeeper: ", zk.ZookeeperClient().Connected())
 	}
+	return zk
 }
 
 func (r *Registrations) Join(hostname, base string, configs []*hfile.CollectionConfig, wait time.Duration) {
@@ -51,39 +60,93 @@ func (r *Registrations) Join(hostname, base string, configs []*hfile.CollectionC
 	r.Lock()
 	defer r.Unlock()
 
+	r.joined = true
+	r.hostname = hostname
 	for _, i := range configs {
-		sfunc := i.ShardFunction
-		capacity := i.TotalPartitions
-
-		if len(sfunc) < 1 {
-			capacity = "1"
-			sfunc = "_"
+		if err := r.register(i); err != nil {
+			log.Fatal(err)
 		}
+	}
+}
 
-		base := fmt.Sprintf("%s/%s/%s", i.ParentName, sfunc, capacity)
+/*
+Update registers newly served collections and unregisters those no longer served, if joined. A
+collection that fails to register is logged and left out, to be tried again on the next update.
+*/
+func (r *Registrations) Update(configs []*hfile.CollectionConfig) {
+	r.Lock()
+	defer r.Unlock()
 
-		disco := discovery.NewServiceDiscovery(r.zk, curator.JoinPath(Settings.discoveryPath, base))
-		if err := disco.MaintainRegistrations(); err != nil {
-			log.Fatal(err)
+	if !r.joined {
+		return
+	}
+
+	served := make(map[string]bool, len(configs))
+	for _, i := range configs {
+		served[registrationKey(i)] = true
+		if err := r.register(i); err != nil {
+			log.Printf("Error joining service discovery for %s: %v\n", registrationKey(i), err)
 		}
-		r.existing = append(r.existing, disco)
+	}
+	for key, disco := range r.existing {
+		if !served[key] {
+			log.Println("Leaving service discovery for", key)
+			disco.UnregisterAll()
+			delete(r.existing, key)
+		}
+	}
+}
 
-		s := discovery.NewSimpleServiceInstance(i.Partition, hostname, Settings.port)
-		disco.Register(s)
+func registrationPath(i *hfile.CollectionConfig) string {
+	sfunc := i.ShardFunction
+	capacity := i.TotalPartitions
 
-		if Settings.rpcPort > 0 {
-			raw := discovery.NewSimpleServiceInstance(fmt.Sprintf("%st", i.Partition), hostname, Settings.rpcPort)
-			disco.Register(raw)
-		}
+	if len(sfunc) < 1 {
+		capacity = "1"
+		sfunc = "_"
+	}
+
+	return fmt.Sprintf("%s/%s/%s", i.ParentName, sfunc, capacity)
+}
+
+func registrationKey(i *hfile.CollectionConfig) string {
+	return registrationPath(i) + "#" + i.Partition
+}
+
+// register registers the collection, unless it already is. Callers must hold the lock.
+func (r *Registrations) register(i *hfile.CollectionConfig) error {
+	key := registrationKey(i)
+	if _, ok := r.existing[key]; ok {
+		return nil
+	}
+
+	disco := discovery.NewServiceDiscovery(r.zk, curator.JoinPath(Settings.discoveryPath, registrationPath(i)))
+	if err := disco.MaintainRegistrations(); err != nil {
+		return err
+	}
+	if r.existing == nil {
+		r.existing = make(map[string]*discovery.ServiceDiscovery)
+	}
+	r.existing[key] = disco
+
+	s := discovery.NewSimpleServiceInstance(i.Partition, r.hostname, Settings.port)
+	disco.Register(s)
+
+	if Settings.rpcPort > 0 {
+		raw := discovery.NewSimpleServiceInstance(fmt.Sprintf("%st", i.Partition), r.hostname, Settings.rpcPort)
+		disco.Register(raw)
 	}
+	return nil
 }
 
 func (r *Registrations) Leave() {
 	r.Lock()
 	defer r.Unlock()
+	r.joined = false
 	for _, reg := range r.existing {
 		reg.UnregisterAll()
 	}
+	r.existing = nil
 }
 
 func (r *Registrations) Close() {
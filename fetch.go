@@ -25,25 +25,26 @@ type SingleCollectionSpec struct {
 	Path          string
 	Url           string
 	Ondemand      bool
+	BlockCacheMb  *int
 }
 
-func getCollectionConfig(args []string) []*hfile.CollectionConfig {
+func getCollectionConfig(args []string) ([]*hfile.CollectionConfig, error) {
 	if Settings.configJsonUrl != "" {
 		if len(args) > 0 {
-			log.Fatalln("Only one of command-line collection specs or json config may be used.")
+			return nil, fmt.Errorf("Only one of command-line collection specs or json config may be used.")
 		}
 		return ConfigsFromJsonUrl(Settings.configJsonUrl)
 	}
 	return ConfigsFromCommandline(args)
 }
 
-func ConfigsFromCommandline(args []string) []*hfile.CollectionConfig {
+func ConfigsFromCommandline(args []string) ([]*hfile.CollectionConfig, error) {
 	configs := make([]*hfile.CollectionConfig, len(args))
 
 	for i, pair := range args {
 		nameAndPath := strings.SplitN(pair, "=", 2)
 		if len(nameAndPath) != 2 {
-			log.Fatal("collections must be specified in the form 'name=path'")
+			return nil, fmt.Errorf("collections must be specified in the form 'name=path'")
 		}
 
 		name, sfunc, total, part := nameAndPath[0], "_", "1", "0"
@@ -73,33 +74,59 @@ func ConfigsFromCommandline(args []string) []*hfile.CollectionConfig {
 			ShardFunction:   sfunc,
 			Partition:       part,
 			TotalPartitions: total,
+			BlockCacheSize:  Settings.blockCacheMb * 1024 * 1024,
 		}
 	}
 
-	return configs
+	return configs, nil
 }
 
-func ConfigsFromJsonUrl(url string) []*hfile.CollectionConfig {
+func ConfigsFromJsonUrl(url string) ([]*hfile.CollectionConfig, error) {
+	specs, err := FetchCollectionSpecs(url)
+	if err != nil {
+		return nil, err
+	}
+	return ConfigsFromSpecs(specs)
+}
+
+func FetchCollectionSpecs(url string) (*CollectionSpecList, error) {
 	log.Printf("[ConfigsFromJsonUrl] Fetching config from %s...\n", url)
 
 	res, err := http.Get(url)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("HTTP error fetching config (%s): %s", url, res.Status)
+	}
 
-	var specs CollectionSpecList
-	if err := json.NewDecoder(res.Body).Decode(&specs); err != nil {
-		log.Fatal(err)
+	specs := new(CollectionSpecList)
+	if err := json.NewDecoder(res.Body).Decode(specs); err != nil {
+		return nil, fmt.Errorf("bad config json from %s: %v", url, err)
 	}
 
 	log.Printf("[ConfigsFromJsonUrl] Found %d collections.\n", len(specs.Collections))
+	return specs, nil
+}
 
-	ret := make([]*hfile.CollectionConfig, len(specs.Collections))
-	for i, spec := range specs.Collections {
+// ConfigsFromSpecs validates the specs, returning the configs of those with a Url.
+func ConfigsFromSpecs(specs *CollectionSpecList) ([]*hfile.CollectionConfig, error) {
+	ret := make([]*hfile.CollectionConfig, 0, len(specs.Collections))
+	seen := make(map[string]bool, len(specs.Collections))
+	for _, spec := range specs.Collections {
 		if spec.Url != "" {
 			name := fmt.Sprintf("%s/%d", spec.Collection, spec.Partition)
 
+			if spec.Collection == "" {
+				return nil, fmt.Errorf("config for %s has no collection name", spec.Url)
+			} else if spec.Partition < 0 || (spec.Capacity > 0 && spec.Partition >= spec.Capacity) {
+				return nil, fmt.Errorf("%s: partition %d is out of range for capacity %d", name, spec.Partition, spec.Capacity)
+			} else if seen[name] {
+				return nil, fmt.Errorf("%s is configured more than once", name)
+			}
+			seen[name] = true
+
 			loadMethod := hfile.CopiedToMem
 
 			if Settings.onDisk {
@@ -112,7 +139,12 @@ func ConfigsFromJsonUrl(url string) []*hfile.CollectionConfig {
 				loadMethod = hfile.OnDisk
 			}
 
-			ret[i] = &hfile.CollectionConfig{
+			blockCacheMb := Settings.blockCacheMb
+			if spec.BlockCacheMb != nil {
+				blockCacheMb = *spec.BlockCacheMb
+			}
+
+			ret = append(ret, &hfile.CollectionConfig{
 				Name:            name,
 				SourcePath:      spec.Url,
 				LocalPath:       "",
@@ -122,7 +154,8 @@ func ConfigsFromJsonUrl(url string) []*hfile.CollectionConfig {
 				ShardFunction:   spec.Function,
 				Partition:       fmt.Sprintf("%d", spec.Partition),
 				TotalPartitions: fmt.Sprintf("%d", spec.Capacity),
-			}
+				BlockCacheSize:  blockCacheMb * 1024 * 1024,
+			})
 		}
 	}
 
@@ -134,5 +167,5 @@ func ConfigsFromJsonUrl(url string) []*hfile.CollectionConfig {
 			log.Printf("\t%s", cfg.Name)
 		}
 	}
-	return ret
+	return ret, nil
 }
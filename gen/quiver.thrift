@@ -51,6 +51,8 @@ struct MultiHFileSplitKeyRequest {
   1: optional string hfileName
   2: optional list<binary> retired_sortedPrefixes
   3: optional list<binary> retired_sortedSuffixes
+  // Keys to look up are formed by joining one part from each segment, for every combination of parts.
+  // Servers may reject requests with too many combinations.
   4: optional list<list<binary>> splitKey
 }
 
@@ -72,6 +74,8 @@ struct IteratorRequest {
   4: optional i32 skipKeys
   5: optional i32 responseLimit
   6: optional binary endKey
+  // Iterate in descending key order: start at or before lastKey (or at the last key) and stop before endKey.
+  7: optional bool reverse
 }
 
 struct IteratorResponse {
@@ -90,11 +94,24 @@ struct HFileInfo {
 
   // Some random keys found in the server's hfiles.
   6: optional list<binary> randomKeys
+
+  7: optional i32 numBlocks
+  // Bytes taken up by the data blocks, as stored (possibly compressed) and uncompressed.
+  8: optional i64 compressedBytes
+  9: optional i64 uncompressedBytes
+  // The compression codec (e.g. "snappy"), as HBase names it.
+  10: optional string codec
+  // How the server loaded the hfile (CopiedToMem, MemlockFile or OnDisk).
+  11: optional string loadMethod
+  // The server's bloom filter: "none", "precomputed", "calculated" or "disabled".
+  12: optional string bloom
+  // The hfile's FileInfo fields, with non-printable values escaped.
+  13: optional map<string, string> fileInfo
 }
 
 struct InfoRequest {
-  // Number of random keys to return. Use judiciously: finding evenly-distributed random keys may require
-  // a full scan on the hfile, which may (in some implementations) block other readers.
+  // Number of random keys to return. Keys are sampled from randomly chosen blocks, so only those blocks
+  // are read, but the sample is only as evenly distributed as the hfile's blocks are evenly sized.
   1: optional string hfileName
   2: optional i64 numRandomKeys
 }
@@ -1814,6 +1814,7 @@ type IteratorRequest struct {
 	SkipKeys      *int32  `thrift:"skipKeys,4" json:"skipKeys"`
 	ResponseLimit *int32  `thrift:"responseLimit,5" json:"responseLimit"`
 	EndKey        []byte  `thrift:"endKey,6" json:"endKey"`
+	Reverse       *bool   `thrift:"reverse,7" json:"reverse"`
 }
 
 func NewIteratorRequest() *IteratorRequest {
@@ -1867,6 +1868,15 @@ var IteratorRequest_EndKey_DEFAULT []byte
 func (p *IteratorRequest) GetEndKey() []byte {
 	return p.EndKey
 }
+
+var IteratorRequest_Reverse_DEFAULT bool
+
+func (p *IteratorRequest) GetReverse() bool {
+	if !p.IsSetReverse() {
+		return IteratorRequest_Reverse_DEFAULT
+	}
+	return *p.Reverse
+}
 func (p *IteratorRequest) IsSetHfileName() bool {
 	return p.HfileName != nil
 }
@@ -1891,6 +1901,10 @@ func (p *IteratorRequest) IsSetEndKey() bool {
 	return p.EndKey != nil
 }
 
+func (p *IteratorRequest) IsSetReverse() bool {
+	return p.Reverse != nil
+}
+
 func (p *IteratorRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return fmt.Errorf("%T read error: %s", p, err)
@@ -1928,6 +1942,10 @@ func (p *IteratorRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField6(iprot); err != nil {
 				return err
 			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -1997,6 +2015,15 @@ func (p *IteratorRequest) ReadField6(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *IteratorRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return fmt.Errorf("error reading field 7: %s", err)
+	} else {
+		p.Reverse = &v
+	}
+	return nil
+}
+
 func (p *IteratorRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("IteratorRequest"); err != nil {
 		return fmt.Errorf("%T write struct begin error: %s", p, err)
@@ -2019,6 +2046,9 @@ func (p *IteratorRequest) Write(oprot thrift.TProtocol) error {
 	if err := p.writeField6(oprot); err != nil {
 		return err
 	}
+	if err := p.writeField7(oprot); err != nil {
+		return err
+	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return fmt.Errorf("write field stop error: %s", err)
 	}
@@ -2118,6 +2148,21 @@ func (p *IteratorRequest) writeField6(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *IteratorRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetReverse() {
+		if err := oprot.WriteFieldBegin("reverse", thrift.BOOL, 7); err != nil {
+			return fmt.Errorf("%T write field begin error 7:reverse: %s", p, err)
+		}
+		if err := oprot.WriteBool(bool(*p.Reverse)); err != nil {
+			return fmt.Errorf("%T.reverse (7) field write error: %s", p, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 7:reverse: %s", p, err)
+		}
+	}
+	return err
+}
+
 func (p *IteratorRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -2328,12 +2373,19 @@ func (p *IteratorResponse) String() string {
 }
 
 type HFileInfo struct {
-	Name        *string  `thrift:"name,1" json:"name"`
-	Path        *string  `thrift:"path,2" json:"path"`
-	NumElements *int64   `thrift:"numElements,3" json:"numElements"`
-	FirstKey    []byte   `thrift:"firstKey,4" json:"firstKey"`
-	LastKey     []byte   `thrift:"lastKey,5" json:"lastKey"`
-	RandomKeys  [][]byte `thrift:"randomKeys,6" json:"randomKeys"`
+	Name              *string           `thrift:"name,1" json:"name"`
+	Path              *string           `thrift:"path,2" json:"path"`
+	NumElements       *int64            `thrift:"numElements,3" json:"numElements"`
+	FirstKey          []byte            `thrift:"firstKey,4" json:"firstKey"`
+	LastKey           []byte            `thrift:"lastKey,5" json:"lastKey"`
+	RandomKeys        [][]byte          `thrift:"randomKeys,6" json:"randomKeys"`
+	NumBlocks         *int32            `thrift:"numBlocks,7" json:"numBlocks"`
+	CompressedBytes   *int64            `thrift:"compressedBytes,8" json:"compressedBytes"`
+	UncompressedBytes *int64            `thrift:"uncompressedBytes,9" json:"uncompressedBytes"`
+	Codec             *string           `thrift:"codec,10" json:"codec"`
+	LoadMethod        *string           `thrift:"loadMethod,11" json:"loadMethod"`
+	Bloom             *string           `thrift:"bloom,12" json:"bloom"`
+	FileInfo          map[string]string `thrift:"fileInfo,13" json:"fileInfo"`
 }
 
 func NewHFileInfo() *HFileInfo {
@@ -2384,6 +2436,66 @@ var HFileInfo_RandomKeys_DEFAULT [][]byte
 func (p *HFileInfo) GetRandomKeys() [][]byte {
 	return p.RandomKeys
 }
+
+var HFileInfo_NumBlocks_DEFAULT int32
+
+func (p *HFileInfo) GetNumBlocks() int32 {
+	if !p.IsSetNumBlocks() {
+		return HFileInfo_NumBlocks_DEFAULT
+	}
+	return *p.NumBlocks
+}
+
+var HFileInfo_CompressedBytes_DEFAULT int64
+
+func (p *HFileInfo) GetCompressedBytes() int64 {
+	if !p.IsSetCompressedBytes() {
+		return HFileInfo_CompressedBytes_DEFAULT
+	}
+	return *p.CompressedBytes
+}
+
+var HFileInfo_UncompressedBytes_DEFAULT int64
+
+func (p *HFileInfo) GetUncompressedBytes() int64 {
+	if !p.IsSetUncompressedBytes() {
+		return HFileInfo_UncompressedBytes_DEFAULT
+	}
+	return *p.UncompressedBytes
+}
+
+var HFileInfo_Codec_DEFAULT string
+
+func (p *HFileInfo) GetCodec() string {
+	if !p.IsSetCodec() {
+		return HFileInfo_Codec_DEFAULT
+	}
+	return *p.Codec
+}
+
+var HFileInfo_LoadMethod_DEFAULT string
+
+func (p *HFileInfo) GetLoadMethod() string {
+	if !p.IsSetLoadMethod() {
+		return HFileInfo_LoadMethod_DEFAULT
+	}
+	return *p.LoadMethod
+}
+
+var HFileInfo_Bloom_DEFAULT string
+
+func (p *HFileInfo) GetBloom() string {
+	if !p.IsSetBloom() {
+		return HFileInfo_Bloom_DEFAULT
+	}
+	return *p.Bloom
+}
+
+var HFileInfo_FileInfo_DEFAULT map[string]string
+
+func (p *HFileInfo) GetFileInfo() map[string]string {
+	return p.FileInfo
+}
 func (p *HFileInfo) IsSetName() bool {
 	return p.Name != nil
 }
@@ -2408,6 +2520,34 @@ func (p *HFileInfo) IsSetRandomKeys() bool {
 	return p.RandomKeys != nil
 }
 
+func (p *HFileInfo) IsSetNumBlocks() bool {
+	return p.NumBlocks != nil
+}
+
+func (p *HFileInfo) IsSetCompressedBytes() bool {
+	return p.CompressedBytes != nil
+}
+
+func (p *HFileInfo) IsSetUncompressedBytes() bool {
+	return p.UncompressedBytes != nil
+}
+
+func (p *HFileInfo) IsSetCodec() bool {
+	return p.Codec != nil
+}
+
+func (p *HFileInfo) IsSetLoadMethod() bool {
+	return p.LoadMethod != nil
+}
+
+func (p *HFileInfo) IsSetBloom() bool {
+	return p.Bloom != nil
+}
+
+func (p *HFileInfo) IsSetFileInfo() bool {
+	return p.FileInfo != nil
+}
+
 func (p *HFileInfo) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return fmt.Errorf("%T read error: %s", p, err)
@@ -2445,6 +2585,34 @@ func (p *HFileInfo) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField6(iprot); err != nil {
 				return err
 			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+		case 9:
+			if err := p.ReadField9(iprot); err != nil {
+				return err
+			}
+		case 10:
+			if err := p.ReadField10(iprot); err != nil {
+				return err
+			}
+		case 11:
+			if err := p.ReadField11(iprot); err != nil {
+				return err
+			}
+		case 12:
+			if err := p.ReadField12(iprot); err != nil {
+				return err
+			}
+		case 13:
+			if err := p.ReadField13(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -2527,6 +2695,88 @@ func (p *HFileInfo) ReadField6(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *HFileInfo) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return fmt.Errorf("error reading field 7: %s", err)
+	} else {
+		p.NumBlocks = &v
+	}
+	return nil
+}
+
+func (p *HFileInfo) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return fmt.Errorf("error reading field 8: %s", err)
+	} else {
+		p.CompressedBytes = &v
+	}
+	return nil
+}
+
+func (p *HFileInfo) ReadField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return fmt.Errorf("error reading field 9: %s", err)
+	} else {
+		p.UncompressedBytes = &v
+	}
+	return nil
+}
+
+func (p *HFileInfo) ReadField10(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return fmt.Errorf("error reading field 10: %s", err)
+	} else {
+		p.Codec = &v
+	}
+	return nil
+}
+
+func (p *HFileInfo) ReadField11(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return fmt.Errorf("error reading field 11: %s", err)
+	} else {
+		p.LoadMethod = &v
+	}
+	return nil
+}
+
+func (p *HFileInfo) ReadField12(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return fmt.Errorf("error reading field 12: %s", err)
+	} else {
+		p.Bloom = &v
+	}
+	return nil
+}
+
+func (p *HFileInfo) ReadField13(iprot thrift.TProtocol) error {
+	_, _, size, err := iprot.ReadMapBegin()
+	if err != nil {
+		return fmt.Errorf("error reading map begin: %s", err)
+	}
+	tMap := make(map[string]string, size)
+	p.FileInfo = tMap
+	for i := 0; i < size; i++ {
+		var _key19 string
+		if v, err := iprot.ReadString(); err != nil {
+			return fmt.Errorf("error reading field 0: %s", err)
+		} else {
+			_key19 = v
+		}
+		var _val20 string
+		if v, err := iprot.ReadString(); err != nil {
+			return fmt.Errorf("error reading field 0: %s", err)
+		} else {
+			_val20 = v
+		}
+		p.FileInfo[_key19] = _val20
+	}
+	if err := iprot.ReadMapEnd(); err != nil {
+		return fmt.Errorf("error reading map end: %s", err)
+	}
+	return nil
+}
+
 func (p *HFileInfo) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("HFileInfo"); err != nil {
 		return fmt.Errorf("%T write struct begin error: %s", p, err)
@@ -2549,6 +2799,27 @@ func (p *HFileInfo) Write(oprot thrift.TProtocol) error {
 	if err := p.writeField6(oprot); err != nil {
 		return err
 	}
+	if err := p.writeField7(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField8(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField9(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField10(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField11(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField12(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField13(oprot); err != nil {
+		return err
+	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return fmt.Errorf("write field stop error: %s", err)
 	}
@@ -2656,6 +2927,122 @@ func (p *HFileInfo) writeField6(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *HFileInfo) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetNumBlocks() {
+		if err := oprot.WriteFieldBegin("numBlocks", thrift.I32, 7); err != nil {
+			return fmt.Errorf("%T write field begin error 7:numBlocks: %s", p, err)
+		}
+		if err := oprot.WriteI32(int32(*p.NumBlocks)); err != nil {
+			return fmt.Errorf("%T.numBlocks (7) field write error: %s", p, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 7:numBlocks: %s", p, err)
+		}
+	}
+	return err
+}
+
+func (p *HFileInfo) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetCompressedBytes() {
+		if err := oprot.WriteFieldBegin("compressedBytes", thrift.I64, 8); err != nil {
+			return fmt.Errorf("%T write field begin error 8:compressedBytes: %s", p, err)
+		}
+		if err := oprot.WriteI64(int64(*p.CompressedBytes)); err != nil {
+			return fmt.Errorf("%T.compressedBytes (8) field write error: %s", p, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 8:compressedBytes: %s", p, err)
+		}
+	}
+	return err
+}
+
+func (p *HFileInfo) writeField9(oprot thrift.TProtocol) (err error) {
+	if p.IsSetUncompressedBytes() {
+		if err := oprot.WriteFieldBegin("uncompressedBytes", thrift.I64, 9); err != nil {
+			return fmt.Errorf("%T write field begin error 9:uncompressedBytes: %s", p, err)
+		}
+		if err := oprot.WriteI64(int64(*p.UncompressedBytes)); err != nil {
+			return fmt.Errorf("%T.uncompressedBytes (9) field write error: %s", p, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 9:uncompressedBytes: %s", p, err)
+		}
+	}
+	return err
+}
+
+func (p *HFileInfo) writeField10(oprot thrift.TProtocol) (err error) {
+	if p.IsSetCodec() {
+		if err := oprot.WriteFieldBegin("codec", thrift.STRING, 10); err != nil {
+			return fmt.Errorf("%T write field begin error 10:codec: %s", p, err)
+		}
+		if err := oprot.WriteString(string(*p.Codec)); err != nil {
+			return fmt.Errorf("%T.codec (10) field write error: %s", p, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 10:codec: %s", p, err)
+		}
+	}
+	return err
+}
+
+func (p *HFileInfo) writeField11(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLoadMethod() {
+		if err := oprot.WriteFieldBegin("loadMethod", thrift.STRING, 11); err != nil {
+			return fmt.Errorf("%T write field begin error 11:loadMethod: %s", p, err)
+		}
+		if err := oprot.WriteString(string(*p.LoadMethod)); err != nil {
+			return fmt.Errorf("%T.loadMethod (11) field write error: %s", p, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 11:loadMethod: %s", p, err)
+		}
+	}
+	return err
+}
+
+func (p *HFileInfo) writeField12(oprot thrift.TProtocol) (err error) {
+	if p.IsSetBloom() {
+		if err := oprot.WriteFieldBegin("bloom", thrift.STRING, 12); err != nil {
+			return fmt.Errorf("%T write field begin error 12:bloom: %s", p, err)
+		}
+		if err := oprot.WriteString(string(*p.Bloom)); err != nil {
+			return fmt.Errorf("%T.bloom (12) field write error: %s", p, err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 12:bloom: %s", p, err)
+		}
+	}
+	return err
+}
+
+func (p *HFileInfo) writeField13(oprot thrift.TProtocol) (err error) {
+	if p.IsSetFileInfo() {
+		if err := oprot.WriteFieldBegin("fileInfo", thrift.MAP, 13); err != nil {
+			return fmt.Errorf("%T write field begin error 13:fileInfo: %s", p, err)
+		}
+		if err := oprot.WriteMapBegin(thrift.STRING, thrift.STRING, len(p.FileInfo)); err != nil {
+			return fmt.Errorf("error writing map begin: %s", err)
+		}
+		for k, v := range p.FileInfo {
+			if err := oprot.WriteString(string(k)); err != nil {
+				return fmt.Errorf("%T. (0) field write error: %s", p, err)
+			}
+			if err := oprot.WriteString(string(v)); err != nil {
+				return fmt.Errorf("%T. (0) field write error: %s", p, err)
+			}
+		}
+		if err := oprot.WriteMapEnd(); err != nil {
+			return fmt.Errorf("error writing map end: %s", err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return fmt.Errorf("%T write field end error 13:fileInfo: %s", p, err)
+		}
+	}
+	return err
+}
+
 func (p *HFileInfo) String() string {
 	if p == nil {
 		return "<nil>"
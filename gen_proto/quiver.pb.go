@@ -39,7 +39,7 @@ func (m *SingleHFileKeyRequest) Reset()         { *m = SingleHFileKeyRequest{} }
 func (m *SingleHFileKeyRequest) String() string { return proto.CompactTextString(m) }
 func (*SingleHFileKeyRequest) ProtoMessage()    {}
 func (*SingleHFileKeyRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_quiver_79d5e3bb209066e5, []int{0}
+	return fileDescriptor_quiver_89989ecbc010be09, []int{0}
 }
 func (m *SingleHFileKeyRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SingleHFileKeyRequest.Unmarshal(m, b)
@@ -99,7 +99,7 @@ func (m *SingleHFileKeyResponse) Reset()         { *m = SingleHFileKeyResponse{}
 func (m *SingleHFileKeyResponse) String() string { return proto.CompactTextString(m) }
 func (*SingleHFileKeyResponse) ProtoMessage()    {}
 func (*SingleHFileKeyResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_quiver_79d5e3bb209066e5, []int{1}
+	return fileDescriptor_quiver_89989ecbc010be09, []int{1}
 }
 func (m *SingleHFileKeyResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SingleHFileKeyResponse.Unmarshal(m, b)
@@ -133,10 +133,969 @@ func (m *SingleHFileKeyResponse) GetKeyCount() int32 {
 	return 0
 }
 
+type ValueList struct {
+	Values               [][]byte `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValueList) Reset()         { *m = ValueList{} }
+func (m *ValueList) String() string { return proto.CompactTextString(m) }
+func (*ValueList) ProtoMessage()    {}
+func (*ValueList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{2}
+}
+func (m *ValueList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValueList.Unmarshal(m, b)
+}
+func (m *ValueList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValueList.Marshal(b, m, deterministic)
+}
+func (dst *ValueList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValueList.Merge(dst, src)
+}
+func (m *ValueList) XXX_Size() int {
+	return xxx_messageInfo_ValueList.Size(m)
+}
+func (m *ValueList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValueList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValueList proto.InternalMessageInfo
+
+func (m *ValueList) GetValues() [][]byte {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type MultiHFileKeyResponse struct {
+	// A map from index in the request's sorted_keys to that key's values.
+	// A missing index means that key had no value in the served hfile.
+	Values               map[int32]*ValueList `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	KeyCount             int32                `protobuf:"varint,2,opt,name=key_count,json=keyCount,proto3" json:"key_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *MultiHFileKeyResponse) Reset()         { *m = MultiHFileKeyResponse{} }
+func (m *MultiHFileKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*MultiHFileKeyResponse) ProtoMessage()    {}
+func (*MultiHFileKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{3}
+}
+func (m *MultiHFileKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiHFileKeyResponse.Unmarshal(m, b)
+}
+func (m *MultiHFileKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiHFileKeyResponse.Marshal(b, m, deterministic)
+}
+func (dst *MultiHFileKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiHFileKeyResponse.Merge(dst, src)
+}
+func (m *MultiHFileKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_MultiHFileKeyResponse.Size(m)
+}
+func (m *MultiHFileKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiHFileKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultiHFileKeyResponse proto.InternalMessageInfo
+
+func (m *MultiHFileKeyResponse) GetValues() map[int32]*ValueList {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *MultiHFileKeyResponse) GetKeyCount() int32 {
+	if m != nil {
+		return m.KeyCount
+	}
+	return 0
+}
+
+// A key and all of its values (proto3 maps can't have bytes keys).
+type KeyValues struct {
+	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Values               [][]byte `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyValues) Reset()         { *m = KeyValues{} }
+func (m *KeyValues) String() string { return proto.CompactTextString(m) }
+func (*KeyValues) ProtoMessage()    {}
+func (*KeyValues) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{4}
+}
+func (m *KeyValues) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyValues.Unmarshal(m, b)
+}
+func (m *KeyValues) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyValues.Marshal(b, m, deterministic)
+}
+func (dst *KeyValues) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyValues.Merge(dst, src)
+}
+func (m *KeyValues) XXX_Size() int {
+	return xxx_messageInfo_KeyValues.Size(m)
+}
+func (m *KeyValues) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyValues.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyValues proto.InternalMessageInfo
+
+func (m *KeyValues) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *KeyValues) GetValues() [][]byte {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type PrefixRequest struct {
+	HfileName            string   `protobuf:"bytes,1,opt,name=hfile_name,json=hfileName,proto3" json:"hfile_name,omitempty"`
+	SortedKeys           [][]byte `protobuf:"bytes,2,rep,name=sorted_keys,json=sortedKeys,proto3" json:"sorted_keys,omitempty"`
+	LastKey              []byte   `protobuf:"bytes,3,opt,name=last_key,json=lastKey,proto3" json:"last_key,omitempty"`
+	ValueLimit           int32    `protobuf:"varint,4,opt,name=value_limit,json=valueLimit,proto3" json:"value_limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PrefixRequest) Reset()         { *m = PrefixRequest{} }
+func (m *PrefixRequest) String() string { return proto.CompactTextString(m) }
+func (*PrefixRequest) ProtoMessage()    {}
+func (*PrefixRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{5}
+}
+func (m *PrefixRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PrefixRequest.Unmarshal(m, b)
+}
+func (m *PrefixRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PrefixRequest.Marshal(b, m, deterministic)
+}
+func (dst *PrefixRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrefixRequest.Merge(dst, src)
+}
+func (m *PrefixRequest) XXX_Size() int {
+	return xxx_messageInfo_PrefixRequest.Size(m)
+}
+func (m *PrefixRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrefixRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrefixRequest proto.InternalMessageInfo
+
+func (m *PrefixRequest) GetHfileName() string {
+	if m != nil {
+		return m.HfileName
+	}
+	return ""
+}
+
+func (m *PrefixRequest) GetSortedKeys() [][]byte {
+	if m != nil {
+		return m.SortedKeys
+	}
+	return nil
+}
+
+func (m *PrefixRequest) GetLastKey() []byte {
+	if m != nil {
+		return m.LastKey
+	}
+	return nil
+}
+
+func (m *PrefixRequest) GetValueLimit() int32 {
+	if m != nil {
+		return m.ValueLimit
+	}
+	return 0
+}
+
+type PrefixResponse struct {
+	// Sorted by key.
+	Values               []*KeyValues `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	LastKey              []byte       `protobuf:"bytes,2,opt,name=last_key,json=lastKey,proto3" json:"last_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *PrefixResponse) Reset()         { *m = PrefixResponse{} }
+func (m *PrefixResponse) String() string { return proto.CompactTextString(m) }
+func (*PrefixResponse) ProtoMessage()    {}
+func (*PrefixResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{6}
+}
+func (m *PrefixResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PrefixResponse.Unmarshal(m, b)
+}
+func (m *PrefixResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PrefixResponse.Marshal(b, m, deterministic)
+}
+func (dst *PrefixResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrefixResponse.Merge(dst, src)
+}
+func (m *PrefixResponse) XXX_Size() int {
+	return xxx_messageInfo_PrefixResponse.Size(m)
+}
+func (m *PrefixResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrefixResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrefixResponse proto.InternalMessageInfo
+
+func (m *PrefixResponse) GetValues() []*KeyValues {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *PrefixResponse) GetLastKey() []byte {
+	if m != nil {
+		return m.LastKey
+	}
+	return nil
+}
+
+type SplitKeySegment struct {
+	Parts                [][]byte `protobuf:"bytes,1,rep,name=parts,proto3" json:"parts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SplitKeySegment) Reset()         { *m = SplitKeySegment{} }
+func (m *SplitKeySegment) String() string { return proto.CompactTextString(m) }
+func (*SplitKeySegment) ProtoMessage()    {}
+func (*SplitKeySegment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{7}
+}
+func (m *SplitKeySegment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SplitKeySegment.Unmarshal(m, b)
+}
+func (m *SplitKeySegment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SplitKeySegment.Marshal(b, m, deterministic)
+}
+func (dst *SplitKeySegment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SplitKeySegment.Merge(dst, src)
+}
+func (m *SplitKeySegment) XXX_Size() int {
+	return xxx_messageInfo_SplitKeySegment.Size(m)
+}
+func (m *SplitKeySegment) XXX_DiscardUnknown() {
+	xxx_messageInfo_SplitKeySegment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SplitKeySegment proto.InternalMessageInfo
+
+func (m *SplitKeySegment) GetParts() [][]byte {
+	if m != nil {
+		return m.Parts
+	}
+	return nil
+}
+
+type MultiHFileSplitKeyRequest struct {
+	HfileName string `protobuf:"bytes,1,opt,name=hfile_name,json=hfileName,proto3" json:"hfile_name,omitempty"`
+	// Keys to look up are formed by joining one part from each segment, for every combination of parts.
+	// Servers may reject requests with too many combinations.
+	SplitKey             []*SplitKeySegment `protobuf:"bytes,2,rep,name=split_key,json=splitKey,proto3" json:"split_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *MultiHFileSplitKeyRequest) Reset()         { *m = MultiHFileSplitKeyRequest{} }
+func (m *MultiHFileSplitKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*MultiHFileSplitKeyRequest) ProtoMessage()    {}
+func (*MultiHFileSplitKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{8}
+}
+func (m *MultiHFileSplitKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiHFileSplitKeyRequest.Unmarshal(m, b)
+}
+func (m *MultiHFileSplitKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiHFileSplitKeyRequest.Marshal(b, m, deterministic)
+}
+func (dst *MultiHFileSplitKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiHFileSplitKeyRequest.Merge(dst, src)
+}
+func (m *MultiHFileSplitKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_MultiHFileSplitKeyRequest.Size(m)
+}
+func (m *MultiHFileSplitKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiHFileSplitKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultiHFileSplitKeyRequest proto.InternalMessageInfo
+
+func (m *MultiHFileSplitKeyRequest) GetHfileName() string {
+	if m != nil {
+		return m.HfileName
+	}
+	return ""
+}
+
+func (m *MultiHFileSplitKeyRequest) GetSplitKey() []*SplitKeySegment {
+	if m != nil {
+		return m.SplitKey
+	}
+	return nil
+}
+
+type KeyToValuesResponse struct {
+	// Sorted by key.
+	Values               []*KeyValues `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *KeyToValuesResponse) Reset()         { *m = KeyToValuesResponse{} }
+func (m *KeyToValuesResponse) String() string { return proto.CompactTextString(m) }
+func (*KeyToValuesResponse) ProtoMessage()    {}
+func (*KeyToValuesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{9}
+}
+func (m *KeyToValuesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyToValuesResponse.Unmarshal(m, b)
+}
+func (m *KeyToValuesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyToValuesResponse.Marshal(b, m, deterministic)
+}
+func (dst *KeyToValuesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyToValuesResponse.Merge(dst, src)
+}
+func (m *KeyToValuesResponse) XXX_Size() int {
+	return xxx_messageInfo_KeyToValuesResponse.Size(m)
+}
+func (m *KeyToValuesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyToValuesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyToValuesResponse proto.InternalMessageInfo
+
+func (m *KeyToValuesResponse) GetValues() []*KeyValues {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type KeyValueItem struct {
+	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                []byte   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyValueItem) Reset()         { *m = KeyValueItem{} }
+func (m *KeyValueItem) String() string { return proto.CompactTextString(m) }
+func (*KeyValueItem) ProtoMessage()    {}
+func (*KeyValueItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{10}
+}
+func (m *KeyValueItem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyValueItem.Unmarshal(m, b)
+}
+func (m *KeyValueItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyValueItem.Marshal(b, m, deterministic)
+}
+func (dst *KeyValueItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyValueItem.Merge(dst, src)
+}
+func (m *KeyValueItem) XXX_Size() int {
+	return xxx_messageInfo_KeyValueItem.Size(m)
+}
+func (m *KeyValueItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyValueItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyValueItem proto.InternalMessageInfo
+
+func (m *KeyValueItem) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *KeyValueItem) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type IteratorRequest struct {
+	HfileName string `protobuf:"bytes,1,opt,name=hfile_name,json=hfileName,proto3" json:"hfile_name,omitempty"`
+	// Only return keys, with empty values.
+	KeysOnly bool `protobuf:"varint,2,opt,name=keys_only,json=keysOnly,proto3" json:"keys_only,omitempty"`
+	// last_key and skip_keys combined informs where to continue with the next batch of iterator request
+	// To continue from where previously left off, seek to the position of last_key and then skip forward skip_keys of keys.
+	LastKey  []byte `protobuf:"bytes,3,opt,name=last_key,json=lastKey,proto3" json:"last_key,omitempty"`
+	SkipKeys int32  `protobuf:"varint,4,opt,name=skip_keys,json=skipKeys,proto3" json:"skip_keys,omitempty"`
+	// Required.
+	ResponseLimit int32  `protobuf:"varint,5,opt,name=response_limit,json=responseLimit,proto3" json:"response_limit,omitempty"`
+	EndKey        []byte `protobuf:"bytes,6,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+	// Iterate in descending key order: start at or before last_key (or at the last key) and stop before end_key.
+	Reverse              bool     `protobuf:"varint,7,opt,name=reverse,proto3" json:"reverse,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IteratorRequest) Reset()         { *m = IteratorRequest{} }
+func (m *IteratorRequest) String() string { return proto.CompactTextString(m) }
+func (*IteratorRequest) ProtoMessage()    {}
+func (*IteratorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{11}
+}
+func (m *IteratorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IteratorRequest.Unmarshal(m, b)
+}
+func (m *IteratorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IteratorRequest.Marshal(b, m, deterministic)
+}
+func (dst *IteratorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IteratorRequest.Merge(dst, src)
+}
+func (m *IteratorRequest) XXX_Size() int {
+	return xxx_messageInfo_IteratorRequest.Size(m)
+}
+func (m *IteratorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_IteratorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IteratorRequest proto.InternalMessageInfo
+
+func (m *IteratorRequest) GetHfileName() string {
+	if m != nil {
+		return m.HfileName
+	}
+	return ""
+}
+
+func (m *IteratorRequest) GetKeysOnly() bool {
+	if m != nil {
+		return m.KeysOnly
+	}
+	return false
+}
+
+func (m *IteratorRequest) GetLastKey() []byte {
+	if m != nil {
+		return m.LastKey
+	}
+	return nil
+}
+
+func (m *IteratorRequest) GetSkipKeys() int32 {
+	if m != nil {
+		return m.SkipKeys
+	}
+	return 0
+}
+
+func (m *IteratorRequest) GetResponseLimit() int32 {
+	if m != nil {
+		return m.ResponseLimit
+	}
+	return 0
+}
+
+func (m *IteratorRequest) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+func (m *IteratorRequest) GetReverse() bool {
+	if m != nil {
+		return m.Reverse
+	}
+	return false
+}
+
+type IteratorResponse struct {
+	Values               []*KeyValueItem `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	LastKey              []byte          `protobuf:"bytes,2,opt,name=last_key,json=lastKey,proto3" json:"last_key,omitempty"`
+	SkipKeys             int32           `protobuf:"varint,3,opt,name=skip_keys,json=skipKeys,proto3" json:"skip_keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *IteratorResponse) Reset()         { *m = IteratorResponse{} }
+func (m *IteratorResponse) String() string { return proto.CompactTextString(m) }
+func (*IteratorResponse) ProtoMessage()    {}
+func (*IteratorResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{12}
+}
+func (m *IteratorResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IteratorResponse.Unmarshal(m, b)
+}
+func (m *IteratorResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IteratorResponse.Marshal(b, m, deterministic)
+}
+func (dst *IteratorResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IteratorResponse.Merge(dst, src)
+}
+func (m *IteratorResponse) XXX_Size() int {
+	return xxx_messageInfo_IteratorResponse.Size(m)
+}
+func (m *IteratorResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_IteratorResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IteratorResponse proto.InternalMessageInfo
+
+func (m *IteratorResponse) GetValues() []*KeyValueItem {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *IteratorResponse) GetLastKey() []byte {
+	if m != nil {
+		return m.LastKey
+	}
+	return nil
+}
+
+func (m *IteratorResponse) GetSkipKeys() int32 {
+	if m != nil {
+		return m.SkipKeys
+	}
+	return 0
+}
+
+type HFileInfo struct {
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path        string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	NumElements int64  `protobuf:"varint,3,opt,name=num_elements,json=numElements,proto3" json:"num_elements,omitempty"`
+	// The first and last keys in the hfile.
+	FirstKey []byte `protobuf:"bytes,4,opt,name=first_key,json=firstKey,proto3" json:"first_key,omitempty"`
+	LastKey  []byte `protobuf:"bytes,5,opt,name=last_key,json=lastKey,proto3" json:"last_key,omitempty"`
+	// Some random keys found in the hfile.
+	RandomKeys [][]byte `protobuf:"bytes,6,rep,name=random_keys,json=randomKeys,proto3" json:"random_keys,omitempty"`
+	NumBlocks  int32    `protobuf:"varint,7,opt,name=num_blocks,json=numBlocks,proto3" json:"num_blocks,omitempty"`
+	// Bytes taken up by the data blocks, as stored (possibly compressed) and uncompressed.
+	CompressedBytes   int64 `protobuf:"varint,8,opt,name=compressed_bytes,json=compressedBytes,proto3" json:"compressed_bytes,omitempty"`
+	UncompressedBytes int64 `protobuf:"varint,9,opt,name=uncompressed_bytes,json=uncompressedBytes,proto3" json:"uncompressed_bytes,omitempty"`
+	// The compression codec (e.g. "snappy"), as HBase names it.
+	Codec string `protobuf:"bytes,10,opt,name=codec,proto3" json:"codec,omitempty"`
+	// How the server loaded the hfile (CopiedToMem, MemlockFile or OnDisk).
+	LoadMethod string `protobuf:"bytes,11,opt,name=load_method,json=loadMethod,proto3" json:"load_method,omitempty"`
+	// The server's bloom filter: "none", "precomputed", "calculated" or "disabled".
+	Bloom string `protobuf:"bytes,12,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	// The hfile's FileInfo fields, with non-printable values escaped.
+	FileInfo             map[string]string `protobuf:"bytes,13,rep,name=file_info,json=fileInfo,proto3" json:"file_info,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *HFileInfo) Reset()         { *m = HFileInfo{} }
+func (m *HFileInfo) String() string { return proto.CompactTextString(m) }
+func (*HFileInfo) ProtoMessage()    {}
+func (*HFileInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{13}
+}
+func (m *HFileInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HFileInfo.Unmarshal(m, b)
+}
+func (m *HFileInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HFileInfo.Marshal(b, m, deterministic)
+}
+func (dst *HFileInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HFileInfo.Merge(dst, src)
+}
+func (m *HFileInfo) XXX_Size() int {
+	return xxx_messageInfo_HFileInfo.Size(m)
+}
+func (m *HFileInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_HFileInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HFileInfo proto.InternalMessageInfo
+
+func (m *HFileInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *HFileInfo) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *HFileInfo) GetNumElements() int64 {
+	if m != nil {
+		return m.NumElements
+	}
+	return 0
+}
+
+func (m *HFileInfo) GetFirstKey() []byte {
+	if m != nil {
+		return m.FirstKey
+	}
+	return nil
+}
+
+func (m *HFileInfo) GetLastKey() []byte {
+	if m != nil {
+		return m.LastKey
+	}
+	return nil
+}
+
+func (m *HFileInfo) GetRandomKeys() [][]byte {
+	if m != nil {
+		return m.RandomKeys
+	}
+	return nil
+}
+
+func (m *HFileInfo) GetNumBlocks() int32 {
+	if m != nil {
+		return m.NumBlocks
+	}
+	return 0
+}
+
+func (m *HFileInfo) GetCompressedBytes() int64 {
+	if m != nil {
+		return m.CompressedBytes
+	}
+	return 0
+}
+
+func (m *HFileInfo) GetUncompressedBytes() int64 {
+	if m != nil {
+		return m.UncompressedBytes
+	}
+	return 0
+}
+
+func (m *HFileInfo) GetCodec() string {
+	if m != nil {
+		return m.Codec
+	}
+	return ""
+}
+
+func (m *HFileInfo) GetLoadMethod() string {
+	if m != nil {
+		return m.LoadMethod
+	}
+	return ""
+}
+
+func (m *HFileInfo) GetBloom() string {
+	if m != nil {
+		return m.Bloom
+	}
+	return ""
+}
+
+func (m *HFileInfo) GetFileInfo() map[string]string {
+	if m != nil {
+		return m.FileInfo
+	}
+	return nil
+}
+
+type ScanRequest struct {
+	HfileName string `protobuf:"bytes,1,opt,name=hfile_name,json=hfileName,proto3" json:"hfile_name,omitempty"`
+	// Start at this key (inclusive), or the first key if empty.
+	StartKey []byte `protobuf:"bytes,2,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
+	// Stop before this key (exclusive), or at the last key if empty.
+	EndKey []byte `protobuf:"bytes,3,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+	// Only return keys, with empty values.
+	KeysOnly bool `protobuf:"varint,4,opt,name=keys_only,json=keysOnly,proto3" json:"keys_only,omitempty"`
+	// Stop after this many pairs, or 0 for no limit.
+	Limit                int64    `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScanRequest) Reset()         { *m = ScanRequest{} }
+func (m *ScanRequest) String() string { return proto.CompactTextString(m) }
+func (*ScanRequest) ProtoMessage()    {}
+func (*ScanRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{14}
+}
+func (m *ScanRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScanRequest.Unmarshal(m, b)
+}
+func (m *ScanRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScanRequest.Marshal(b, m, deterministic)
+}
+func (dst *ScanRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScanRequest.Merge(dst, src)
+}
+func (m *ScanRequest) XXX_Size() int {
+	return xxx_messageInfo_ScanRequest.Size(m)
+}
+func (m *ScanRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScanRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScanRequest proto.InternalMessageInfo
+
+func (m *ScanRequest) GetHfileName() string {
+	if m != nil {
+		return m.HfileName
+	}
+	return ""
+}
+
+func (m *ScanRequest) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *ScanRequest) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+func (m *ScanRequest) GetKeysOnly() bool {
+	if m != nil {
+		return m.KeysOnly
+	}
+	return false
+}
+
+func (m *ScanRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type PrefixScanRequest struct {
+	HfileName string   `protobuf:"bytes,1,opt,name=hfile_name,json=hfileName,proto3" json:"hfile_name,omitempty"`
+	Prefixes  [][]byte `protobuf:"bytes,2,rep,name=prefixes,proto3" json:"prefixes,omitempty"`
+	// Skip keys before this one, e.g. to resume an interrupted scan after the last key received.
+	StartKey []byte `protobuf:"bytes,3,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
+	// Stop before this key (exclusive), or at the last key if empty.
+	EndKey   []byte `protobuf:"bytes,4,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+	KeysOnly bool   `protobuf:"varint,5,opt,name=keys_only,json=keysOnly,proto3" json:"keys_only,omitempty"`
+	// Stop after this many pairs, or 0 for no limit.
+	Limit                int64    `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PrefixScanRequest) Reset()         { *m = PrefixScanRequest{} }
+func (m *PrefixScanRequest) String() string { return proto.CompactTextString(m) }
+func (*PrefixScanRequest) ProtoMessage()    {}
+func (*PrefixScanRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{15}
+}
+func (m *PrefixScanRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PrefixScanRequest.Unmarshal(m, b)
+}
+func (m *PrefixScanRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PrefixScanRequest.Marshal(b, m, deterministic)
+}
+func (dst *PrefixScanRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrefixScanRequest.Merge(dst, src)
+}
+func (m *PrefixScanRequest) XXX_Size() int {
+	return xxx_messageInfo_PrefixScanRequest.Size(m)
+}
+func (m *PrefixScanRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrefixScanRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrefixScanRequest proto.InternalMessageInfo
+
+func (m *PrefixScanRequest) GetHfileName() string {
+	if m != nil {
+		return m.HfileName
+	}
+	return ""
+}
+
+func (m *PrefixScanRequest) GetPrefixes() [][]byte {
+	if m != nil {
+		return m.Prefixes
+	}
+	return nil
+}
+
+func (m *PrefixScanRequest) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *PrefixScanRequest) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+func (m *PrefixScanRequest) GetKeysOnly() bool {
+	if m != nil {
+		return m.KeysOnly
+	}
+	return false
+}
+
+func (m *PrefixScanRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type InfoRequest struct {
+	// The collection to describe, along with any under it (e.g. "name/part-1"), or empty for all of them.
+	HfileName string `protobuf:"bytes,1,opt,name=hfile_name,json=hfileName,proto3" json:"hfile_name,omitempty"`
+	// Number of random keys to return, for ScanCollectionAndSampleKeys. Keys are sampled from randomly chosen blocks, so only
+	// those blocks are read, but the sample is only as evenly distributed as the hfile's blocks are evenly sized.
+	NumRandomKeys        int64    `protobuf:"varint,2,opt,name=num_random_keys,json=numRandomKeys,proto3" json:"num_random_keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InfoRequest) Reset()         { *m = InfoRequest{} }
+func (m *InfoRequest) String() string { return proto.CompactTextString(m) }
+func (*InfoRequest) ProtoMessage()    {}
+func (*InfoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{16}
+}
+func (m *InfoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InfoRequest.Unmarshal(m, b)
+}
+func (m *InfoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InfoRequest.Marshal(b, m, deterministic)
+}
+func (dst *InfoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InfoRequest.Merge(dst, src)
+}
+func (m *InfoRequest) XXX_Size() int {
+	return xxx_messageInfo_InfoRequest.Size(m)
+}
+func (m *InfoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_InfoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InfoRequest proto.InternalMessageInfo
+
+func (m *InfoRequest) GetHfileName() string {
+	if m != nil {
+		return m.HfileName
+	}
+	return ""
+}
+
+func (m *InfoRequest) GetNumRandomKeys() int64 {
+	if m != nil {
+		return m.NumRandomKeys
+	}
+	return 0
+}
+
+type InfoResponse struct {
+	Infos                []*HFileInfo `protobuf:"bytes,1,rep,name=infos,proto3" json:"infos,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *InfoResponse) Reset()         { *m = InfoResponse{} }
+func (m *InfoResponse) String() string { return proto.CompactTextString(m) }
+func (*InfoResponse) ProtoMessage()    {}
+func (*InfoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_quiver_89989ecbc010be09, []int{17}
+}
+func (m *InfoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InfoResponse.Unmarshal(m, b)
+}
+func (m *InfoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InfoResponse.Marshal(b, m, deterministic)
+}
+func (dst *InfoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InfoResponse.Merge(dst, src)
+}
+func (m *InfoResponse) XXX_Size() int {
+	return xxx_messageInfo_InfoResponse.Size(m)
+}
+func (m *InfoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_InfoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InfoResponse proto.InternalMessageInfo
+
+func (m *InfoResponse) GetInfos() []*HFileInfo {
+	if m != nil {
+		return m.Infos
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*SingleHFileKeyRequest)(nil), "foursquare.quiver.client.SingleHFileKeyRequest")
 	proto.RegisterType((*SingleHFileKeyResponse)(nil), "foursquare.quiver.client.SingleHFileKeyResponse")
 	proto.RegisterMapType((map[int32][]byte)(nil), "foursquare.quiver.client.SingleHFileKeyResponse.ValuesEntry")
+	proto.RegisterType((*ValueList)(nil), "foursquare.quiver.client.ValueList")
+	proto.RegisterType((*MultiHFileKeyResponse)(nil), "foursquare.quiver.client.MultiHFileKeyResponse")
+	proto.RegisterMapType((map[int32]*ValueList)(nil), "foursquare.quiver.client.MultiHFileKeyResponse.ValuesEntry")
+	proto.RegisterType((*KeyValues)(nil), "foursquare.quiver.client.KeyValues")
+	proto.RegisterType((*PrefixRequest)(nil), "foursquare.quiver.client.PrefixRequest")
+	proto.RegisterType((*PrefixResponse)(nil), "foursquare.quiver.client.PrefixResponse")
+	proto.RegisterType((*SplitKeySegment)(nil), "foursquare.quiver.client.SplitKeySegment")
+	proto.RegisterType((*MultiHFileSplitKeyRequest)(nil), "foursquare.quiver.client.MultiHFileSplitKeyRequest")
+	proto.RegisterType((*KeyToValuesResponse)(nil), "foursquare.quiver.client.KeyToValuesResponse")
+	proto.RegisterType((*KeyValueItem)(nil), "foursquare.quiver.client.KeyValueItem")
+	proto.RegisterType((*IteratorRequest)(nil), "foursquare.quiver.client.IteratorRequest")
+	proto.RegisterType((*IteratorResponse)(nil), "foursquare.quiver.client.IteratorResponse")
+	proto.RegisterType((*HFileInfo)(nil), "foursquare.quiver.client.HFileInfo")
+	proto.RegisterMapType((map[string]string)(nil), "foursquare.quiver.client.HFileInfo.FileInfoEntry")
+	proto.RegisterType((*ScanRequest)(nil), "foursquare.quiver.client.ScanRequest")
+	proto.RegisterType((*PrefixScanRequest)(nil), "foursquare.quiver.client.PrefixScanRequest")
+	proto.RegisterType((*InfoRequest)(nil), "foursquare.quiver.client.InfoRequest")
+	proto.RegisterType((*InfoResponse)(nil), "foursquare.quiver.client.InfoResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -152,6 +1111,16 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type QuiverServiceClient interface {
 	GetValuesSingle(ctx context.Context, in *SingleHFileKeyRequest, opts ...grpc.CallOption) (*SingleHFileKeyResponse, error)
+	GetValuesMulti(ctx context.Context, in *SingleHFileKeyRequest, opts ...grpc.CallOption) (*MultiHFileKeyResponse, error)
+	GetValuesForPrefixes(ctx context.Context, in *PrefixRequest, opts ...grpc.CallOption) (*PrefixResponse, error)
+	GetValuesMultiSplitKeys(ctx context.Context, in *MultiHFileSplitKeyRequest, opts ...grpc.CallOption) (*KeyToValuesResponse, error)
+	GetIterator(ctx context.Context, in *IteratorRequest, opts ...grpc.CallOption) (*IteratorResponse, error)
+	GetInfo(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
+	ScanCollectionAndSampleKeys(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
+	// Streams every key-value pair in a range, in key order, without paging.
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (QuiverService_ScanClient, error)
+	// Streams every key-value pair whose key starts with any of the prefixes, in key order, without paging.
+	ScanPrefixes(ctx context.Context, in *PrefixScanRequest, opts ...grpc.CallOption) (QuiverService_ScanPrefixesClient, error)
 }
 
 type quiverServiceClient struct {
@@ -171,9 +1140,137 @@ func (c *quiverServiceClient) GetValuesSingle(ctx context.Context, in *SingleHFi
 	return out, nil
 }
 
+func (c *quiverServiceClient) GetValuesMulti(ctx context.Context, in *SingleHFileKeyRequest, opts ...grpc.CallOption) (*MultiHFileKeyResponse, error) {
+	out := new(MultiHFileKeyResponse)
+	err := c.cc.Invoke(ctx, "/foursquare.quiver.client.QuiverService/GetValuesMulti", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quiverServiceClient) GetValuesForPrefixes(ctx context.Context, in *PrefixRequest, opts ...grpc.CallOption) (*PrefixResponse, error) {
+	out := new(PrefixResponse)
+	err := c.cc.Invoke(ctx, "/foursquare.quiver.client.QuiverService/GetValuesForPrefixes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quiverServiceClient) GetValuesMultiSplitKeys(ctx context.Context, in *MultiHFileSplitKeyRequest, opts ...grpc.CallOption) (*KeyToValuesResponse, error) {
+	out := new(KeyToValuesResponse)
+	err := c.cc.Invoke(ctx, "/foursquare.quiver.client.QuiverService/GetValuesMultiSplitKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quiverServiceClient) GetIterator(ctx context.Context, in *IteratorRequest, opts ...grpc.CallOption) (*IteratorResponse, error) {
+	out := new(IteratorResponse)
+	err := c.cc.Invoke(ctx, "/foursquare.quiver.client.QuiverService/GetIterator", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quiverServiceClient) GetInfo(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error) {
+	out := new(InfoResponse)
+	err := c.cc.Invoke(ctx, "/foursquare.quiver.client.QuiverService/GetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quiverServiceClient) ScanCollectionAndSampleKeys(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error) {
+	out := new(InfoResponse)
+	err := c.cc.Invoke(ctx, "/foursquare.quiver.client.QuiverService/ScanCollectionAndSampleKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quiverServiceClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (QuiverService_ScanClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_QuiverService_serviceDesc.Streams[0], "/foursquare.quiver.client.QuiverService/Scan", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &quiverServiceScanClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type QuiverService_ScanClient interface {
+	Recv() (*KeyValueItem, error)
+	grpc.ClientStream
+}
+
+type quiverServiceScanClient struct {
+	grpc.ClientStream
+}
+
+func (x *quiverServiceScanClient) Recv() (*KeyValueItem, error) {
+	m := new(KeyValueItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *quiverServiceClient) ScanPrefixes(ctx context.Context, in *PrefixScanRequest, opts ...grpc.CallOption) (QuiverService_ScanPrefixesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_QuiverService_serviceDesc.Streams[1], "/foursquare.quiver.client.QuiverService/ScanPrefixes", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &quiverServiceScanPrefixesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type QuiverService_ScanPrefixesClient interface {
+	Recv() (*KeyValueItem, error)
+	grpc.ClientStream
+}
+
+type quiverServiceScanPrefixesClient struct {
+	grpc.ClientStream
+}
+
+func (x *quiverServiceScanPrefixesClient) Recv() (*KeyValueItem, error) {
+	m := new(KeyValueItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // QuiverServiceServer is the server API for QuiverService service.
 type QuiverServiceServer interface {
 	GetValuesSingle(context.Context, *SingleHFileKeyRequest) (*SingleHFileKeyResponse, error)
+	GetValuesMulti(context.Context, *SingleHFileKeyRequest) (*MultiHFileKeyResponse, error)
+	GetValuesForPrefixes(context.Context, *PrefixRequest) (*PrefixResponse, error)
+	GetValuesMultiSplitKeys(context.Context, *MultiHFileSplitKeyRequest) (*KeyToValuesResponse, error)
+	GetIterator(context.Context, *IteratorRequest) (*IteratorResponse, error)
+	GetInfo(context.Context, *InfoRequest) (*InfoResponse, error)
+	ScanCollectionAndSampleKeys(context.Context, *InfoRequest) (*InfoResponse, error)
+	// Streams every key-value pair in a range, in key order, without paging.
+	Scan(*ScanRequest, QuiverService_ScanServer) error
+	// Streams every key-value pair whose key starts with any of the prefixes, in key order, without paging.
+	ScanPrefixes(*PrefixScanRequest, QuiverService_ScanPrefixesServer) error
 }
 
 func RegisterQuiverServiceServer(s *grpc.Server, srv QuiverServiceServer) {
@@ -198,6 +1295,156 @@ func _QuiverService_GetValuesSingle_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _QuiverService_GetValuesMulti_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SingleHFileKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuiverServiceServer).GetValuesMulti(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foursquare.quiver.client.QuiverService/GetValuesMulti",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuiverServiceServer).GetValuesMulti(ctx, req.(*SingleHFileKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuiverService_GetValuesForPrefixes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrefixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuiverServiceServer).GetValuesForPrefixes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foursquare.quiver.client.QuiverService/GetValuesForPrefixes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuiverServiceServer).GetValuesForPrefixes(ctx, req.(*PrefixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuiverService_GetValuesMultiSplitKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiHFileSplitKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuiverServiceServer).GetValuesMultiSplitKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foursquare.quiver.client.QuiverService/GetValuesMultiSplitKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuiverServiceServer).GetValuesMultiSplitKeys(ctx, req.(*MultiHFileSplitKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuiverService_GetIterator_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IteratorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuiverServiceServer).GetIterator(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foursquare.quiver.client.QuiverService/GetIterator",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuiverServiceServer).GetIterator(ctx, req.(*IteratorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuiverService_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuiverServiceServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foursquare.quiver.client.QuiverService/GetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuiverServiceServer).GetInfo(ctx, req.(*InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuiverService_ScanCollectionAndSampleKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuiverServiceServer).ScanCollectionAndSampleKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/foursquare.quiver.client.QuiverService/ScanCollectionAndSampleKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuiverServiceServer).ScanCollectionAndSampleKeys(ctx, req.(*InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuiverService_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuiverServiceServer).Scan(m, &quiverServiceScanServer{stream})
+}
+
+type QuiverService_ScanServer interface {
+	Send(*KeyValueItem) error
+	grpc.ServerStream
+}
+
+type quiverServiceScanServer struct {
+	grpc.ServerStream
+}
+
+func (x *quiverServiceScanServer) Send(m *KeyValueItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _QuiverService_ScanPrefixes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PrefixScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuiverServiceServer).ScanPrefixes(m, &quiverServiceScanPrefixesServer{stream})
+}
+
+type QuiverService_ScanPrefixesServer interface {
+	Send(*KeyValueItem) error
+	grpc.ServerStream
+}
+
+type quiverServiceScanPrefixesServer struct {
+	grpc.ServerStream
+}
+
+func (x *quiverServiceScanPrefixesServer) Send(m *KeyValueItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _QuiverService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "foursquare.quiver.client.QuiverService",
 	HandlerType: (*QuiverServiceServer)(nil),
@@ -206,35 +1453,122 @@ var _QuiverService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetValuesSingle",
 			Handler:    _QuiverService_GetValuesSingle_Handler,
 		},
+		{
+			MethodName: "GetValuesMulti",
+			Handler:    _QuiverService_GetValuesMulti_Handler,
+		},
+		{
+			MethodName: "GetValuesForPrefixes",
+			Handler:    _QuiverService_GetValuesForPrefixes_Handler,
+		},
+		{
+			MethodName: "GetValuesMultiSplitKeys",
+			Handler:    _QuiverService_GetValuesMultiSplitKeys_Handler,
+		},
+		{
+			MethodName: "GetIterator",
+			Handler:    _QuiverService_GetIterator_Handler,
+		},
+		{
+			MethodName: "GetInfo",
+			Handler:    _QuiverService_GetInfo_Handler,
+		},
+		{
+			MethodName: "ScanCollectionAndSampleKeys",
+			Handler:    _QuiverService_ScanCollectionAndSampleKeys_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Scan",
+			Handler:       _QuiverService_Scan_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ScanPrefixes",
+			Handler:       _QuiverService_ScanPrefixes_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "gen_proto/quiver.proto",
 }
 
-func init() { proto.RegisterFile("gen_proto/quiver.proto", fileDescriptor_quiver_79d5e3bb209066e5) }
-
-var fileDescriptor_quiver_79d5e3bb209066e5 = []byte{
-	// 346 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x92, 0xcf, 0x4a, 0x2b, 0x31,
-	0x14, 0xc6, 0x6f, 0x3a, 0xb7, 0xa5, 0x73, 0xda, 0xcb, 0x2d, 0xb9, 0xd7, 0x32, 0x54, 0xc4, 0xa1,
-	0xab, 0xd9, 0x38, 0x95, 0xba, 0x51, 0x71, 0x27, 0xfe, 0x81, 0x8a, 0x62, 0x2a, 0x6e, 0x87, 0xb1,
-	0x9e, 0xd6, 0xd0, 0x34, 0x33, 0x4d, 0x32, 0x03, 0x79, 0x02, 0x9f, 0xc4, 0x57, 0xf1, 0xb9, 0x64,
-	0x32, 0x5d, 0x88, 0x28, 0xe8, 0x2e, 0xe7, 0x3b, 0x9c, 0xef, 0xfc, 0xbe, 0x24, 0xd0, 0x5f, 0xa0,
-	0x4c, 0x72, 0x95, 0x99, 0x6c, 0xb4, 0x2e, 0x78, 0x89, 0x2a, 0x76, 0x05, 0x0d, 0xe6, 0x59, 0xa1,
-	0xf4, 0xba, 0x48, 0x15, 0xc6, 0x9b, 0xc6, 0x4c, 0x70, 0x94, 0x66, 0xf8, 0x42, 0x60, 0x6b, 0xca,
-	0xe5, 0x42, 0xe0, 0xe5, 0x39, 0x17, 0x38, 0x41, 0xcb, 0x70, 0x5d, 0xa0, 0x36, 0x74, 0x07, 0xe0,
-	0x69, 0xce, 0x05, 0x26, 0x32, 0x5d, 0x61, 0x40, 0x42, 0x12, 0xf9, 0xcc, 0x77, 0xca, 0x75, 0xba,
-	0x42, 0xba, 0x0b, 0x1d, 0x9d, 0x29, 0x83, 0x8f, 0xc9, 0x12, 0xad, 0x0e, 0x1a, 0xa1, 0x17, 0x75,
-	0x19, 0xd4, 0xd2, 0x04, 0xad, 0xa6, 0x7b, 0xf0, 0x2f, 0x47, 0x55, 0x75, 0x93, 0x32, 0x15, 0x05,
-	0x26, 0x82, 0xaf, 0xb8, 0x09, 0xbc, 0x90, 0x44, 0x4d, 0xd6, 0xcb, 0x51, 0x4d, 0xd0, 0xde, 0x57,
-	0x8d, 0xab, 0x4a, 0xaf, 0xd6, 0xcd, 0xb2, 0x42, 0x9a, 0x24, 0x93, 0xc2, 0x06, 0xbf, 0x43, 0x12,
-	0xb5, 0x99, 0xef, 0x94, 0x1b, 0x29, 0xec, 0xf0, 0x95, 0x40, 0xff, 0x23, 0xa7, 0xce, 0x33, 0xa9,
-	0x91, 0xde, 0x41, 0xcb, 0x2d, 0xd0, 0x01, 0x09, 0xbd, 0xa8, 0x33, 0x3e, 0x89, 0xbf, 0x4a, 0x1b,
-	0x7f, 0xee, 0x10, 0x3b, 0x0c, 0x7d, 0x26, 0x8d, 0xb2, 0x6c, 0xe3, 0x45, 0xb7, 0xc1, 0xaf, 0xd0,
-	0x1d, 0x41, 0xd0, 0x70, 0xd0, 0xed, 0x25, 0xda, 0xd3, 0xaa, 0x1e, 0x1c, 0x41, 0xe7, 0xdd, 0x0c,
-	0xed, 0x81, 0xb7, 0x44, 0xeb, 0xee, 0xa8, 0xc9, 0xaa, 0x23, 0xfd, 0x0f, 0x4d, 0xe7, 0xe3, 0x26,
-	0xbb, 0xac, 0x2e, 0x8e, 0x1b, 0x87, 0x64, 0xfc, 0x4c, 0xe0, 0xcf, 0xad, 0x83, 0x9a, 0xa2, 0x2a,
-	0xf9, 0x0c, 0x69, 0x09, 0x7f, 0x2f, 0xd0, 0xd4, 0x7e, 0x35, 0x20, 0x1d, 0x7d, 0x3f, 0x82, 0x7b,
-	0xac, 0xc1, 0xfe, 0x4f, 0x33, 0x0f, 0x7f, 0x3d, 0xb4, 0xdc, 0xdf, 0x38, 0x78, 0x0b, 0x00, 0x00,
-	0xff, 0xff, 0xbb, 0xb4, 0xf9, 0x49, 0x35, 0x02, 0x00, 0x00,
+func init() { proto.RegisterFile("gen_proto/quiver.proto", fileDescriptor_quiver_89989ecbc010be09) }
+
+var fileDescriptor_quiver_89989ecbc010be09 = []byte{
+	// 1171 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0xcf, 0x6e, 0xdb, 0x46,
+	0x13, 0x37, 0xf5, 0x9f, 0x23, 0x29, 0x76, 0x36, 0x4e, 0xc2, 0xc8, 0x08, 0x3e, 0x7f, 0x0c, 0xe2,
+	0x28, 0x2d, 0x2c, 0xa7, 0x0e, 0x5a, 0xd4, 0x75, 0x51, 0xa0, 0x09, 0xe2, 0xd4, 0x70, 0x93, 0xa6,
+	0x94, 0x51, 0xa0, 0x3d, 0x54, 0xa0, 0xa5, 0x91, 0x4d, 0x88, 0x5c, 0xca, 0xdc, 0xa5, 0x50, 0x9e,
+	0x0a, 0xe4, 0xd8, 0x9e, 0xfb, 0x08, 0x7d, 0x86, 0xbe, 0x41, 0x5f, 0xa3, 0x87, 0x9e, 0xfa, 0x16,
+	0xc5, 0xee, 0x92, 0x14, 0x29, 0x5b, 0xb2, 0x5c, 0xf8, 0xc6, 0x99, 0xdd, 0x99, 0xf9, 0xcd, 0xec,
+	0x6f, 0x66, 0x97, 0x70, 0xef, 0x14, 0x69, 0x6f, 0x1c, 0xf8, 0xdc, 0xdf, 0x39, 0x0f, 0x9d, 0x09,
+	0x06, 0x1d, 0x29, 0x10, 0x63, 0xe8, 0x87, 0x01, 0x3b, 0x0f, 0xed, 0x00, 0x3b, 0xf1, 0x42, 0xdf,
+	0x75, 0x90, 0x72, 0xf3, 0x77, 0x0d, 0xee, 0x76, 0x1d, 0x7a, 0xea, 0xe2, 0x57, 0x07, 0x8e, 0x8b,
+	0x47, 0x18, 0x59, 0x78, 0x1e, 0x22, 0xe3, 0xe4, 0x21, 0xc0, 0xd9, 0xd0, 0x71, 0xb1, 0x47, 0x6d,
+	0x0f, 0x0d, 0x6d, 0x53, 0x6b, 0xeb, 0x96, 0x2e, 0x35, 0x6f, 0x6d, 0x0f, 0xc9, 0xff, 0xa0, 0xce,
+	0xfc, 0x80, 0xe3, 0xa0, 0x37, 0xc2, 0x88, 0x19, 0x85, 0xcd, 0x62, 0xbb, 0x61, 0x81, 0x52, 0x1d,
+	0x61, 0xc4, 0xc8, 0x36, 0xdc, 0x19, 0x63, 0x20, 0x56, 0x7b, 0x13, 0xdb, 0x0d, 0xb1, 0xe7, 0x3a,
+	0x9e, 0xc3, 0x8d, 0xe2, 0xa6, 0xd6, 0x2e, 0x5b, 0x6b, 0x63, 0x0c, 0x8e, 0x30, 0xfa, 0x4e, 0x2c,
+	0x7c, 0x2d, 0xf4, 0x22, 0x5c, 0xdf, 0x0f, 0x29, 0xef, 0xf9, 0xd4, 0x8d, 0x8c, 0xd2, 0xa6, 0xd6,
+	0xae, 0x59, 0xba, 0xd4, 0x7c, 0x43, 0xdd, 0xc8, 0xfc, 0x53, 0x83, 0x7b, 0xb3, 0x38, 0xd9, 0xd8,
+	0xa7, 0x0c, 0xc9, 0x31, 0x54, 0x64, 0x00, 0x66, 0x68, 0x9b, 0xc5, 0x76, 0x7d, 0xf7, 0xf3, 0xce,
+	0xbc, 0x6c, 0x3b, 0x97, 0x7b, 0xe8, 0x48, 0x18, 0xec, 0x15, 0xe5, 0x41, 0x64, 0xc5, 0xbe, 0xc8,
+	0x06, 0xe8, 0x02, 0xba, 0x44, 0x60, 0x14, 0x24, 0xe8, 0xda, 0x08, 0xa3, 0x97, 0x42, 0x6e, 0xed,
+	0x41, 0x3d, 0x63, 0x43, 0xd6, 0xa0, 0x38, 0xc2, 0x48, 0xd6, 0xa8, 0x6c, 0x89, 0x4f, 0xb2, 0x0e,
+	0x65, 0xe9, 0x47, 0x5a, 0x36, 0x2c, 0x25, 0x7c, 0x56, 0xf8, 0x54, 0x33, 0x1f, 0x81, 0x1e, 0x67,
+	0xcd, 0x38, 0xb9, 0x97, 0x83, 0xde, 0x48, 0x82, 0x9b, 0xff, 0x68, 0x70, 0xf7, 0x4d, 0xe8, 0x72,
+	0xe7, 0x42, 0xb2, 0xdd, 0x99, 0x64, 0xf7, 0xe7, 0x27, 0x7b, 0xa9, 0x83, 0xeb, 0xe7, 0xfa, 0xe3,
+	0x55, 0xb9, 0xee, 0x65, 0x73, 0xad, 0xef, 0x3e, 0x9a, 0x8f, 0x28, 0x4d, 0x3c, 0x5b, 0x90, 0x8f,
+	0x41, 0x4f, 0x98, 0xc0, 0xb2, 0xde, 0x1b, 0xca, 0xfb, 0xb4, 0x44, 0x85, 0x5c, 0x89, 0x7e, 0xd5,
+	0xa0, 0xf9, 0x2e, 0xc0, 0xa1, 0xf3, 0xd3, 0x4d, 0x11, 0xf6, 0x01, 0xd4, 0x5c, 0x9b, 0x71, 0xb1,
+	0x2c, 0x59, 0xda, 0xb0, 0xaa, 0x42, 0x3e, 0xc2, 0x48, 0xd8, 0x66, 0x39, 0x5c, 0x92, 0xc9, 0xc3,
+	0x24, 0x65, 0xaf, 0x79, 0x06, 0xb7, 0x12, 0x30, 0xf1, 0x41, 0xed, 0xcf, 0x1c, 0xd4, 0x82, 0xb2,
+	0xa4, 0xe9, 0xa7, 0x07, 0x92, 0x85, 0x52, 0xc8, 0x41, 0x31, 0x9f, 0xc0, 0x6a, 0x77, 0xec, 0x3a,
+	0xe2, 0xbb, 0x8b, 0xa7, 0x1e, 0x52, 0x2e, 0xc8, 0x36, 0xb6, 0x03, 0x9e, 0x90, 0x48, 0x09, 0xe6,
+	0x7b, 0x0d, 0x1e, 0x4c, 0x29, 0x90, 0xd8, 0x2c, 0x59, 0xac, 0x03, 0xd0, 0x99, 0xb0, 0x88, 0x11,
+	0x88, 0x04, 0x9e, 0x2e, 0x68, 0xab, 0x3c, 0x20, 0xab, 0xc6, 0x62, 0x85, 0x69, 0xc1, 0x9d, 0x23,
+	0x8c, 0x8e, 0xfd, 0x38, 0xbf, 0x9b, 0x28, 0x8e, 0xf9, 0x09, 0x34, 0x12, 0xe5, 0x21, 0x47, 0xef,
+	0x12, 0xce, 0x5c, 0xda, 0x7d, 0xe6, 0x5f, 0x1a, 0xac, 0x1e, 0x72, 0x0c, 0x6c, 0xee, 0x07, 0x4b,
+	0x96, 0x41, 0x35, 0x06, 0x53, 0x33, 0xa9, 0x20, 0x67, 0x92, 0x68, 0x0c, 0x26, 0x46, 0xd2, 0x22,
+	0xbe, 0x6c, 0x80, 0xce, 0x46, 0xce, 0x58, 0x31, 0x4d, 0xb1, 0xa5, 0x26, 0x14, 0x92, 0x67, 0x8f,
+	0xe1, 0x56, 0x10, 0x17, 0x22, 0xe6, 0x53, 0x59, 0xee, 0x68, 0x26, 0x5a, 0x35, 0x10, 0xef, 0x43,
+	0x15, 0xa9, 0x24, 0xab, 0x51, 0x91, 0xde, 0x2b, 0x48, 0x05, 0x51, 0x89, 0x01, 0xd5, 0x00, 0x27,
+	0x18, 0x30, 0x34, 0xaa, 0x12, 0x52, 0x22, 0x9a, 0xbf, 0x68, 0xb0, 0x36, 0xcd, 0x30, 0xae, 0xf5,
+	0x17, 0x33, 0xb5, 0xde, 0xba, 0xba, 0xd6, 0xa2, 0xac, 0x4b, 0x70, 0x31, 0x9f, 0x66, 0x31, 0x9f,
+	0xa6, 0xf9, 0xbe, 0x04, 0xba, 0xa4, 0xde, 0x21, 0x1d, 0xfa, 0x84, 0x40, 0x29, 0x53, 0x62, 0xf9,
+	0x2d, 0x74, 0x63, 0x9b, 0x9f, 0x49, 0xaf, 0xba, 0x25, 0xbf, 0xc9, 0xff, 0xa1, 0x41, 0x43, 0xaf,
+	0x87, 0x2e, 0x0a, 0x26, 0x29, 0xaf, 0x45, 0xab, 0x4e, 0x43, 0xef, 0x55, 0xac, 0x12, 0x51, 0x87,
+	0x4e, 0x10, 0x23, 0x2a, 0x49, 0x44, 0x35, 0xa9, 0x10, 0x90, 0xb2, 0x68, 0xcb, 0x17, 0x9a, 0x38,
+	0xb0, 0xe9, 0xc0, 0xf7, 0x14, 0xde, 0x8a, 0x1a, 0x00, 0x4a, 0x25, 0x0f, 0xe6, 0x21, 0x80, 0x88,
+	0x7d, 0xe2, 0xfa, 0xfd, 0x11, 0x93, 0xb5, 0x2d, 0x5b, 0x3a, 0x0d, 0xbd, 0x17, 0x52, 0x41, 0x9e,
+	0xc2, 0x5a, 0xdf, 0xf7, 0xc6, 0x01, 0x32, 0x86, 0x83, 0xde, 0x49, 0xc4, 0x91, 0x19, 0x35, 0x09,
+	0x6f, 0x75, 0xaa, 0x7f, 0x21, 0xd4, 0x64, 0x1b, 0x48, 0x48, 0x2f, 0x6c, 0xd6, 0xe5, 0xe6, 0xdb,
+	0x21, 0x9d, 0xdd, 0xbe, 0x0e, 0xe5, 0xbe, 0x3f, 0xc0, 0xbe, 0x01, 0xb2, 0x12, 0x4a, 0x10, 0x78,
+	0x5d, 0xdf, 0x1e, 0xf4, 0x3c, 0xe4, 0x67, 0xfe, 0xc0, 0xa8, 0xcb, 0x35, 0x10, 0xaa, 0x37, 0x52,
+	0x23, 0xcc, 0x4e, 0x5c, 0xdf, 0xf7, 0x8c, 0x86, 0x32, 0x93, 0x02, 0x79, 0x2b, 0xca, 0xe3, 0x62,
+	0xcf, 0xa1, 0x43, 0xdf, 0x68, 0xca, 0x23, 0xff, 0x68, 0xfe, 0x91, 0xa7, 0x27, 0xd4, 0x49, 0x3e,
+	0xd4, 0xd5, 0x50, 0x1b, 0xc6, 0x62, 0x6b, 0x1f, 0x9a, 0xb9, 0xa5, 0x6c, 0xbf, 0xe9, 0x97, 0xf4,
+	0x9b, 0x9e, 0x1d, 0xee, 0xbf, 0x69, 0x50, 0xef, 0xf6, 0x6d, 0xba, 0x7c, 0xbf, 0x31, 0x6e, 0x07,
+	0x59, 0xb2, 0xd5, 0xa4, 0x42, 0x9c, 0x5f, 0xa6, 0x21, 0x8a, 0xb9, 0x86, 0xc8, 0x75, 0x69, 0x69,
+	0xa6, 0x4b, 0xd7, 0xa1, 0x3c, 0x6d, 0xb2, 0xa2, 0xa5, 0x04, 0xf3, 0x0f, 0x0d, 0x6e, 0xab, 0x81,
+	0x7d, 0x0d, 0x74, 0x2d, 0xa8, 0x8d, 0xa5, 0x4d, 0x7a, 0x19, 0xa5, 0x72, 0x1e, 0x79, 0x71, 0x3e,
+	0xf2, 0xd2, 0x7c, 0xe4, 0xe5, 0x79, 0xc8, 0x2b, 0x59, 0xe4, 0xc7, 0x50, 0x17, 0x47, 0xb1, 0x24,
+	0xe4, 0x2d, 0x58, 0x15, 0x94, 0xce, 0xf2, 0xbe, 0x20, 0xbd, 0x35, 0x69, 0xe8, 0x59, 0x29, 0xf5,
+	0xcd, 0x43, 0x68, 0x28, 0xaf, 0xf1, 0xd0, 0xd8, 0x83, 0xb2, 0xe0, 0xcf, 0x12, 0xf3, 0x39, 0x25,
+	0x90, 0xa5, 0x2c, 0x76, 0xff, 0xae, 0x42, 0xf3, 0x5b, 0xb9, 0xa5, 0x8b, 0xc1, 0xc4, 0xe9, 0x23,
+	0x99, 0xc0, 0xea, 0x6b, 0xe4, 0x6a, 0x8a, 0xab, 0x17, 0x18, 0xd9, 0x59, 0xfe, 0x8d, 0x26, 0xf3,
+	0x6c, 0x3d, 0xbb, 0xee, 0xa3, 0xce, 0x5c, 0x21, 0x1c, 0x6e, 0xa5, 0x71, 0xe5, 0x4d, 0x78, 0xfd,
+	0xb0, 0x3b, 0xd7, 0x7c, 0x5e, 0x99, 0x2b, 0x64, 0x04, 0xeb, 0x69, 0xd4, 0x03, 0x3f, 0x78, 0x97,
+	0x30, 0xe4, 0xc9, 0x7c, 0x57, 0xb9, 0x77, 0x4c, 0xab, 0x7d, 0xf5, 0xc6, 0x34, 0xd8, 0xcf, 0x70,
+	0x3f, 0x9f, 0x62, 0x72, 0x15, 0x33, 0xf2, 0x7c, 0x19, 0xe8, 0x33, 0xcf, 0x82, 0xd6, 0xf6, 0xc2,
+	0xcb, 0x61, 0xf6, 0x1e, 0x37, 0x57, 0xc8, 0x10, 0xea, 0xaf, 0x91, 0x27, 0x97, 0x0e, 0x59, 0xf0,
+	0x48, 0x98, 0xb9, 0x7a, 0x5b, 0x1f, 0x2c, 0xb3, 0x35, 0x8d, 0xf3, 0x03, 0x54, 0x45, 0x1c, 0x71,
+	0x95, 0x3c, 0x5e, 0x60, 0x38, 0xed, 0x8c, 0xd6, 0xd6, 0x55, 0xdb, 0x52, 0xdf, 0x14, 0x36, 0xc4,
+	0x14, 0x78, 0xe9, 0xbb, 0x2e, 0xf6, 0xb9, 0xe3, 0xd3, 0x2f, 0xe9, 0xa0, 0x6b, 0x7b, 0x63, 0x79,
+	0xb2, 0xec, 0xe6, 0xe3, 0x7d, 0x0f, 0x25, 0x11, 0x6f, 0x91, 0xe3, 0xcc, 0x54, 0x6a, 0x2d, 0x79,
+	0x61, 0x9b, 0x2b, 0xcf, 0x34, 0x72, 0x0a, 0x0d, 0x61, 0x9a, 0x92, 0xee, 0xc3, 0xab, 0xb8, 0xf4,
+	0x1f, 0x03, 0x9d, 0x54, 0xe4, 0x8f, 0xe5, 0xf3, 0x7f, 0x07, 0x00, 0x0c, 0x03, 0x60, 0x83, 0x72,
+	0x0e, 0x00, 0x00,
 }
@@ -0,0 +1,207 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	pb "github.com/foursquare/quiver/gen_proto"
+	"github.com/foursquare/quiver/hfile"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func DummyGrpcClient(t hasFatal, handler *ThriftRpcImpl) (pb.QuiverServiceClient, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterQuiverServiceServer(s, &GrpcImpl{handler.RpcShared})
+	go s.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb.NewQuiverServiceClient(conn), func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func TestGrpcRoundTrip(t *testing.T) {
+	Setup(t)
+	client, done := DummyGrpcClient(t, compressed)
+	defer done()
+	ctx := context.Background()
+
+	keys := [][]byte{hfile.MockKeyInt(5), hfile.MockKeyInt(7), hfile.MockKeyInt(maxKey + 1)}
+
+	single, err := client.GetValuesSingle(ctx, &pb.SingleHFileKeyRequest{HfileName: "compressed", SortedKeys: keys})
+	assert.Nil(t, err, err)
+	assert.Equal(t, int32(2), single.KeyCount)
+	assert.Equal(t, map[int32][]byte{0: hfile.MockValueInt(5), 1: hfile.MockValueInt(7)}, single.Values)
+
+	multi, err := client.GetValuesMulti(ctx, &pb.SingleHFileKeyRequest{HfileName: "compressed", SortedKeys: keys})
+	assert.Nil(t, err, err)
+	assert.Equal(t, int32(2), multi.KeyCount)
+	assert.Equal(t, [][]byte{hfile.MockValueInt(7)}, multi.Values[1].Values)
+	assert.Nil(t, multi.Values[2])
+
+	prefixes, err := client.GetValuesForPrefixes(ctx, &pb.PrefixRequest{HfileName: "compressed", SortedKeys: [][]byte{{0, 0, 0}}, ValueLimit: 3})
+	assert.Nil(t, err, err)
+	if assert.Len(t, prefixes.Values, 3) {
+		for i, kv := range prefixes.Values {
+			assert.Equal(t, hfile.MockKeyInt(i), kv.Key)
+			assert.Equal(t, [][]byte{hfile.MockValueInt(i)}, kv.Values)
+		}
+	}
+
+	split, err := client.GetValuesMultiSplitKeys(ctx, &pb.MultiHFileSplitKeyRequest{
+		HfileName: "compressed",
+		SplitKey:  []*pb.SplitKeySegment{{Parts: [][]byte{{0, 0}}}, {Parts: [][]byte{{0}}}, {Parts: [][]byte{{2}, {1}}}},
+	})
+	assert.Nil(t, err, err)
+	if assert.Len(t, split.Values, 2) {
+		assert.Equal(t, hfile.MockKeyInt(1), split.Values[0].Key)
+		assert.Equal(t, hfile.MockKeyInt(2), split.Values[1].Key)
+	}
+
+	it, err := client.GetIterator(ctx, &pb.IteratorRequest{HfileName: "compressed", LastKey: hfile.MockKeyInt(10), ResponseLimit: 5, EndKey: hfile.MockKeyInt(13)})
+	assert.Nil(t, err, err)
+	if assert.Len(t, it.Values, 4, "the end key is inclusive") {
+		assert.Equal(t, hfile.MockKeyInt(10), it.Values[0].Key)
+		assert.Equal(t, hfile.MockValueInt(10), it.Values[0].Value)
+	}
+	assert.Equal(t, hfile.MockKeyInt(13), it.LastKey)
+	assert.Equal(t, int32(1), it.SkipKeys)
+
+	it, err = client.GetIterator(ctx, &pb.IteratorRequest{HfileName: "compressed", ResponseLimit: 2, KeysOnly: true})
+	assert.Nil(t, err, err)
+	if assert.Len(t, it.Values, 2) {
+		assert.Equal(t, hfile.MockKeyInt(0), it.Values[0].Key)
+		assert.Empty(t, it.Values[0].Value)
+	}
+
+	info, err := client.ScanCollectionAndSampleKeys(ctx, &pb.InfoRequest{HfileName: "compressed", NumRandomKeys: 10})
+	assert.Nil(t, err, err)
+	if assert.Len(t, info.Infos, 1) {
+		assert.Equal(t, "compressed", info.Infos[0].Name)
+		assert.Equal(t, int64(maxKey), info.Infos[0].NumElements)
+		assert.Equal(t, hfile.MockKeyInt(maxKey-1), info.Infos[0].LastKey)
+		assert.Equal(t, "snappy", info.Infos[0].Codec)
+		assert.NotEmpty(t, info.Infos[0].RandomKeys)
+	}
+	info, err = client.GetInfo(ctx, &pb.InfoRequest{HfileName: "compressed", NumRandomKeys: 10})
+	assert.Nil(t, err, err)
+	assert.Empty(t, info.Infos[0].RandomKeys)
+}
+
+func TestGrpcErrors(t *testing.T) {
+	Setup(t)
+	client, done := DummyGrpcClient(t, compressed)
+	defer done()
+	ctx := context.Background()
+
+	_, err := client.GetValuesSingle(ctx, &pb.SingleHFileKeyRequest{HfileName: "nope", SortedKeys: [][]byte{{0}}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetIterator(ctx, &pb.IteratorRequest{HfileName: "compressed"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	Settings.maxSplitKeyProduct = 1
+	defer func() { Settings.maxSplitKeyProduct = 0 }()
+	_, err = client.GetValuesMultiSplitKeys(ctx, &pb.MultiHFileSplitKeyRequest{
+		HfileName: "compressed",
+		SplitKey:  []*pb.SplitKeySegment{{Parts: [][]byte{{0}, {1}}}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func readGrpcStream(recv func() (*pb.KeyValueItem, error)) ([]*pb.KeyValueItem, error) {
+	var items []*pb.KeyValueItem
+	for {
+		item, err := recv()
+		if err == io.EOF {
+			return items, nil
+		} else if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+}
+
+func TestGrpcScan(t *testing.T) {
+	Setup(t)
+	client, done := DummyGrpcClient(t, compressed)
+	defer done()
+	ctx := context.Background()
+
+	stream, err := client.Scan(ctx, &pb.ScanRequest{HfileName: "compressed", StartKey: hfile.MockKeyInt(100), EndKey: hfile.MockKeyInt(1100)})
+	assert.Nil(t, err, err)
+	items, err := readGrpcStream(stream.Recv)
+	assert.Nil(t, err, err)
+	if assert.Len(t, items, 1000, "the end key is exclusive") {
+		for i, item := range items {
+			assert.Equal(t, hfile.MockKeyInt(100+i), item.Key)
+			assert.Equal(t, hfile.MockValueInt(100+i), item.Value)
+		}
+	}
+
+	stream, err = client.Scan(ctx, &pb.ScanRequest{HfileName: "compressed", Limit: 3, KeysOnly: true})
+	assert.Nil(t, err, err)
+	items, err = readGrpcStream(stream.Recv)
+	assert.Nil(t, err, err)
+	if assert.Len(t, items, 3) {
+		assert.Equal(t, hfile.MockKeyInt(2), items[2].Key)
+		assert.Empty(t, items[2].Value)
+	}
+
+	// Overlapping prefixes are only scanned once, in order, and the limit applies across all of them.
+	prefixes, err := client.ScanPrefixes(ctx, &pb.PrefixScanRequest{
+		HfileName: "compressed",
+		Prefixes:  [][]byte{{0, 0, 2}, {0, 0, 1}, {0, 0, 1, 7}},
+		StartKey:  hfile.MockKeyInt(256 + 250),
+		Limit:     10,
+	})
+	assert.Nil(t, err, err)
+	items, err = readGrpcStream(prefixes.Recv)
+	assert.Nil(t, err, err)
+	if assert.Len(t, items, 10) {
+		for i, item := range items {
+			assert.Equal(t, hfile.MockKeyInt(256+250+i), item.Key)
+		}
+	}
+
+	stream, err = client.Scan(ctx, &pb.ScanRequest{HfileName: "nope"})
+	assert.Nil(t, err, err)
+	_, err = readGrpcStream(stream.Recv)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGrpcScanCancel(t *testing.T) {
+	Setup(t)
+	client, done := DummyGrpcClient(t, compressed)
+	defer done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Scan(ctx, &pb.ScanRequest{HfileName: "compressed"})
+	assert.Nil(t, err, err)
+	for i := 0; i < 10; i++ {
+		item, err := stream.Recv()
+		assert.Nil(t, err, err)
+		assert.Equal(t, hfile.MockKeyInt(i), item.Key)
+	}
+	cancel()
+
+	// The rest of the collection is never sent: at most what was in flight arrives before the cancellation.
+	items, err := readGrpcStream(stream.Recv)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.True(t, len(items) < maxKey/10, "received %d items after cancelling", len(items))
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/AndreasBriese/bbloom"
+)
+
+/*
+Calculating a bloom filter at startup means iterating over every key in the collection, which
+can take minutes for large collections, so writers can instead build one as keys are written
+and store it in the file, as a meta block, for the reader to load at open time.
+
+The meta block holds the filter as exported by bbloom's JSONMarshal (its bitset and number of
+hash locations per key).
+*/
+const BloomMetaBlock = "quiver.bloom"
+
+// The import/export format used by bbloom's JSONMarshal and JSONUnmarshal.
+type storedBloom struct {
+	FilterSet []byte
+	SetLocs   uint64
+}
+
+/*
+EnableBloom makes the writer build a bloom filter of the keys it writes, sized for expectedEntries
+keys at the given false positive rate, and store it in the file on Close.
+*/
+func (w *Writer) EnableBloom(expectedEntries int, falsePosRate float64) {
+	if expectedEntries < 1 {
+		expectedEntries = 1
+	}
+	bloom := bbloom.New(float64(expectedEntries), falsePosRate)
+	w.bloom = &bloom
+}
+
+func (w *Writer) flushBloom() error {
+	if w.bloom == nil {
+		return nil
+	}
+	return w.AppendMetaBlock(BloomMetaBlock, w.bloom.JSONMarshal())
+}
+
+// loadBloom loads the file's precomputed bloom filter, if it has one.
+func (r *Reader) loadBloom() error {
+	raw, found, err := r.MetaBlock(BloomMetaBlock)
+	if err != nil || !found {
+		return err
+	}
+
+	// bbloom.JSONUnmarshal ignores errors, which would leave us with an empty filter that rejects
+	// every key, so check the contents first.
+	var stored storedBloom
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return fmt.Errorf("bad bloom filter: %v", err)
+	}
+	if n := len(stored.FilterSet); n < 8 || n&(n-1) != 0 || stored.SetLocs < 1 {
+		return fmt.Errorf("bad bloom filter: %d bytes, %d locs", n, stored.SetLocs)
+	}
+
+	bloom := bbloom.NewWithBoolset(&stored.FilterSet, stored.SetLocs)
+	r.bloom = &bloom
+	r.bloomPrecomputed = true
+	if r.Debug {
+		log.Printf("[Reader.loadBloom] loaded %db bloom filter for %s", len(stored.FilterSet), r.Name)
+	}
+	return nil
+}
+
+// HasBloom reports whether the reader has a bloom filter, either loaded from the file or calculated.
+func (r *Reader) HasBloom() bool {
+	return r.bloom != nil
+}
+
+/*
+BloomState describes the reader's bloom filter: "none", "precomputed" (loaded from the file),
+"calculated" (by CalculateBloom), or "disabled" if it has one but DisableBloom was called.
+*/
+func (r *Reader) BloomState() string {
+	switch {
+	case r.bloom == nil:
+		return "none"
+	case r.disableBloom:
+		return "disabled"
+	case r.bloomPrecomputed:
+		return "precomputed"
+	default:
+		return "calculated"
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/foursquare/quiver/hfile"
+)
+
+// The summary, with keys encoded, as printed by -json.
+type summary struct {
+	Old       string `json:"old"`
+	New       string `json:"new"`
+	Same      bool   `json:"same"`
+	Unchanged uint64 `json:"unchanged"`
+	Added     uint64 `json:"added"`
+	Removed   uint64 `json:"removed"`
+	Changed   uint64 `json:"changed"`
+
+	AddedKeys   []string `json:"addedKeys"`
+	RemovedKeys []string `json:"removedKeys"`
+	ChangedKeys []string `json:"changedKeys"`
+}
+
+// A difference, with its key and values encoded, as printed by -full -json.
+type difference struct {
+	Kind string   `json:"kind"`
+	Key  string   `json:"key"`
+	Old  []string `json:"old,omitempty"`
+	New  []string `json:"new,omitempty"`
+}
+
+func main() {
+	samples := flag.Int("samples", 10, "how many keys of each kind of difference to list in the summary")
+	full := flag.Bool("full", false, "print every differing key, and its old and new values, before the summary")
+	asJson := flag.Bool("json", false, "print the summary, and any -full output, as JSON Lines")
+	keyEncoding := flag.String("key-encoding", "utf8", "how to print keys: utf8, hex, base64, int32 or int64 (big-endian)")
+	valEncoding := flag.String("value-encoding", "utf8", "how to print values: utf8, hex, base64, int32 or int64 (big-endian)")
+	exitCode := flag.Bool("exit-code", false, "exit with status 2 if the files differ (errors exit with 1)")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nUsage: %s [options] path/to/old.hfile path/to/new.hfile\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Compares two hfiles key by key, reporting which keys were added, removed or changed.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(flag.Args()) != 2 {
+		flag.Usage()
+		os.Exit(-1)
+	}
+
+	keyEnc, err := hfile.ParseByteEncoding(*keyEncoding)
+	if err != nil {
+		log.Fatal(err)
+	}
+	valEnc, err := hfile.ParseByteEncoding(*valEncoding)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	oldPath, newPath := flag.Arg(0), flag.Arg(1)
+	old, err := hfile.NewReader(oldPath, oldPath, hfile.OnDisk, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	new, err := hfile.NewReader(newPath, newPath, hfile.OnDisk, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	var printDiff func(hfile.KeyDiff) error
+	if *full {
+		printDiff = func(d hfile.KeyDiff) error {
+			diff, err := encodeDiff(d, keyEnc, valEnc)
+			if err != nil {
+				return err
+			}
+			if *asJson {
+				return enc.Encode(diff)
+			}
+			return printTextDiff(out, diff)
+		}
+	}
+
+	s, err := hfile.Diff(old, new, *samples, printDiff)
+	if err != nil {
+		log.Fatalf("Error comparing %s and %s: %v", oldPath, newPath, err)
+	}
+
+	encoded := summary{
+		Old:       oldPath,
+		New:       newPath,
+		Same:      s.Same(),
+		Unchanged: s.Unchanged,
+		Added:     s.Added,
+		Removed:   s.Removed,
+		Changed:   s.Changed,
+	}
+	for _, keys := range []struct {
+		raw [][]byte
+		enc *[]string
+	}{{s.AddedKeys, &encoded.AddedKeys}, {s.RemovedKeys, &encoded.RemovedKeys}, {s.ChangedKeys, &encoded.ChangedKeys}} {
+		if *keys.enc, err = encodeAll(keys.raw, keyEnc, "key", "-key-encoding"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *asJson {
+		err = enc.Encode(encoded)
+	} else {
+		err = printTextSummary(out, encoded)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *exitCode && !s.Same() {
+		os.Exit(2)
+	}
+}
+
+func encodeDiff(d hfile.KeyDiff, keyEnc, valEnc hfile.ByteEncoding) (difference, error) {
+	key, err := encodeAll([][]byte{d.Key}, keyEnc, "key", "-key-encoding")
+	if err != nil {
+		return difference{}, err
+	}
+	diff := difference{Kind: d.Kind.String(), Key: key[0]}
+	if diff.Old, err = encodeAll(d.Old, valEnc, "value of "+key[0], "-value-encoding"); err != nil {
+		return difference{}, err
+	}
+	if diff.New, err = encodeAll(d.New, valEnc, "value of "+key[0], "-value-encoding"); err != nil {
+		return difference{}, err
+	}
+	return diff, nil
+}
+
+func encodeAll(raw [][]byte, e hfile.ByteEncoding, what, flag string) ([]string, error) {
+	encoded := make([]string, len(raw))
+	for i, b := range raw {
+		s, err := e.Encode(b)
+		if err != nil {
+			return nil, fmt.Errorf("can't encode %s %x as %s (see %s): %v", what, b, e, flag, err)
+		}
+		encoded[i] = s
+	}
+	return encoded, nil
+}
+
+// printTextDiff prints a difference diff(1)-style: one line per old value prefixed with "-", and one per new value with "+".
+func printTextDiff(out *bufio.Writer, d difference) error {
+	if _, err := fmt.Fprintf(out, "%s %q\n", d.Kind, d.Key); err != nil {
+		return err
+	}
+	for _, v := range d.Old {
+		if _, err := fmt.Fprintf(out, "  - %q\n", v); err != nil {
+			return err
+		}
+	}
+	for _, v := range d.New {
+		if _, err := fmt.Fprintf(out, "  + %q\n", v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printTextSummary(out *bufio.Writer, s summary) error {
+	fmt.Fprintf(out, "old: %s\nnew: %s\n", s.Old, s.New)
+	fmt.Fprintf(out, "unchanged: %d\nadded: %d\nremoved: %d\nchanged: %d\n", s.Unchanged, s.Added, s.Removed, s.Changed)
+	for _, keys := range []struct {
+		kind string
+		keys []string
+	}{{"added", s.AddedKeys}, {"removed", s.RemovedKeys}, {"changed", s.ChangedKeys}} {
+		if len(keys.keys) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "first %s keys:\n", keys.kind)
+		for _, k := range keys.keys {
+			fmt.Fprintf(out, "  %q\n", k)
+		}
+	}
+	if s.Same {
+		_, err := fmt.Fprintln(out, "the files have the same keys and values")
+		return err
+	}
+	return nil
+}
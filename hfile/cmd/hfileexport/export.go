@@ -0,0 +1,156 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/foursquare/quiver/hfile"
+)
+
+// A recordWriter writes an encoded key, and value unless keys-only, as a record of the chosen format.
+type recordWriter func(key, val string) error
+
+func main() {
+	format := flag.String("format", "jsonl", "output format: jsonl, csv or tsv")
+	keyEncoding := flag.String("key-encoding", "utf8", "how to write keys (and read -start, -end and -prefix): utf8, hex, base64, int32 or int64 (big-endian)")
+	valEncoding := flag.String("value-encoding", "utf8", "how to write values: utf8, hex, base64, int32 or int64 (big-endian)")
+	start := flag.String("start", "", "only export keys at or after this one")
+	end := flag.String("end", "", "only export keys before this one")
+	prefix := flag.String("prefix", "", "only export keys with this prefix")
+	keysOnly := flag.Bool("keys-only", false, "only export keys (once per value)")
+	limit := flag.Int("limit", 0, "stop after exporting this many pairs from each file (or 0 for no limit)")
+	header := flag.Bool("header", false, "for csv and tsv, start with a header line")
+	keyField := flag.String("key-field", "key", "for jsonl, the field to write keys to")
+	valField := flag.String("value-field", "value", "for jsonl, the field to write values to")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nUsage: %s [options] path/to/file.hfile [more/files.hfile...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Writes the selected key-value pairs of each file to stdout, in key order.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(flag.Args()) < 1 {
+		flag.Usage()
+		os.Exit(-1)
+	}
+
+	keyEnc, err := hfile.ParseByteEncoding(*keyEncoding)
+	if err != nil {
+		log.Fatal(err)
+	}
+	valEnc, err := hfile.ParseByteEncoding(*valEncoding)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var kr hfile.KeyRange
+	for _, bound := range []struct {
+		flag  string
+		value string
+		key   *[]byte
+	}{{"start", *start, &kr.Start}, {"end", *end, &kr.End}, {"prefix", *prefix, &kr.Prefix}} {
+		if bound.value == "" {
+			continue
+		}
+		if *bound.key, err = keyEnc.Decode(bound.value); err != nil {
+			log.Fatalf("Bad -%s %q: %v", bound.flag, bound.value, err)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	write, flush, err := newRecordWriter(out, *format, *keysOnly, *keyField, *valField)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *header && *format != "jsonl" {
+		if err := write(*keyField, *valField); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, path := range flag.Args() {
+		r, err := hfile.NewReader(path, path, hfile.OnDisk, false)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = r.ScanRange(kr, *limit, func(k, v []byte) error {
+			key, err := keyEnc.Encode(k)
+			if err != nil {
+				return fmt.Errorf("can't encode key %x as %s (see -key-encoding): %v", k, keyEnc, err)
+			}
+			val := ""
+			if !*keysOnly {
+				if val, err = valEnc.Encode(v); err != nil {
+					return fmt.Errorf("can't encode value %x of key %q as %s (see -value-encoding): %v", v, key, valEnc, err)
+				}
+			}
+			return write(key, val)
+		})
+		if err != nil {
+			log.Fatalf("Error exporting %s: %v", path, err)
+		}
+	}
+
+	if err := flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newRecordWriter(out *bufio.Writer, format string, keysOnly bool, keyField, valField string) (recordWriter, func() error, error) {
+	switch format {
+	case "jsonl":
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		return func(key, val string) error {
+			record := map[string]string{keyField: key}
+			if !keysOnly {
+				record[valField] = val
+			}
+			return enc.Encode(record)
+		}, out.Flush, nil
+
+	case "csv":
+		w := csv.NewWriter(out)
+		write := func(key, val string) error {
+			if keysOnly {
+				return w.Write([]string{key})
+			}
+			return w.Write([]string{key, val})
+		}
+		flush := func() error {
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return err
+			}
+			return out.Flush()
+		}
+		return write, flush, nil
+
+	case "tsv":
+		return func(key, val string) error {
+			if strings.ContainsAny(key, "\t\n") || strings.ContainsAny(val, "\t\n") {
+				return fmt.Errorf("%q can't be written as tsv: it contains a tab or newline (try -format csv, or a different encoding)", key)
+			}
+			if keysOnly {
+				_, err := fmt.Fprintf(out, "%s\n", key)
+				return err
+			}
+			_, err := fmt.Fprintf(out, "%s\t%s\n", key, val)
+			return err
+		}, out.Flush, nil
+
+	default:
+		return nil, nil, fmt.Errorf("Unknown output format %q", format)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/foursquare/quiver/hfile"
+)
+
+// Both hfile.Writer and hfile.SortingWriter.
+type pairWriter interface {
+	Write(k, v []byte) error
+	Close() error
+}
+
+type recordReader struct {
+	format               string
+	keyColumn, valColumn int
+	header               bool
+	keyField, valField   string
+	keyEnc, valEnc       hfile.ByteEncoding
+}
+
+func main() {
+	format := flag.String("format", "", "input format: csv, tsv or jsonl (default: from the first input's extension, or csv)")
+	keyEncoding := flag.String("key-encoding", "utf8", "how keys are written in the input: utf8, hex, base64, int32 or int64 (big-endian)")
+	valEncoding := flag.String("value-encoding", "utf8", "how values are written in the input: utf8, hex, base64, int32 or int64 (big-endian)")
+	keyColumn := flag.Int("key-column", 0, "for csv and tsv, the (zero-based) column of keys")
+	valColumn := flag.Int("value-column", 1, "for csv and tsv, the (zero-based) column of values")
+	header := flag.Bool("header", false, "for csv and tsv, skip the first line of each input")
+	keyField := flag.String("key-field", "key", "for jsonl, the field holding keys")
+	valField := flag.String("value-field", "value", "for jsonl, the field holding values")
+	codecName := flag.String("codec", "snappy", "compression codec: none, snappy, lz4, gzip or zstd")
+	blockSize := flag.Int("blocksize", 64*1024, "block size in bytes")
+	sorted := flag.Bool("sorted", false, "the input is already sorted by (encoded) key, so skip sorting it")
+	sortMem := flag.Int("sort-mem", 256, "MB of pairs to sort in memory before spilling sorted runs to disk")
+	tmpDir := flag.String("tmpdir", "", "directory for sorted runs (default: the system's temp dir)")
+	verbose := flag.Bool("verbose", false, "verbose output")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nUsage: %s [options] path/to/output.hfile [inputs...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Reads key-value pairs from the inputs (or stdin, if none or '-') and writes them to a new hfile.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(flag.Args()) < 1 {
+		flag.Usage()
+		os.Exit(-1)
+	}
+	inputs := flag.Args()[1:]
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	rr := recordReader{
+		format:    strings.ToLower(*format),
+		keyColumn: *keyColumn,
+		valColumn: *valColumn,
+		header:    *header,
+		keyField:  *keyField,
+		valField:  *valField,
+	}
+	if rr.format == "" {
+		rr.format = formatFromExtension(inputs[0])
+	}
+	if rr.format != "csv" && rr.format != "tsv" && rr.format != "jsonl" {
+		log.Fatalf("Unknown input format %q", rr.format)
+	}
+	if rr.keyColumn < 0 || rr.valColumn < 0 {
+		log.Fatal("Columns must not be negative")
+	}
+
+	var err error
+	if rr.keyEnc, err = hfile.ParseByteEncoding(*keyEncoding); err != nil {
+		log.Fatal(err)
+	}
+	if rr.valEnc, err = hfile.ParseByteEncoding(*valEncoding); err != nil {
+		log.Fatal(err)
+	}
+
+	codec, err := hfile.ParseCompressionCodec(*codecName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	w, err := hfile.NewLocalWriter(flag.Arg(0), codec, *blockSize, *verbose)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var out pairWriter = w
+	if !*sorted {
+		if out, err = hfile.NewSortingWriter(w, *sortMem*1024*1024, *tmpDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	count := 0
+	for _, input := range inputs {
+		n, err := rr.readInput(input, out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		count += n
+	}
+
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d pairs to %s.", count, flag.Arg(0))
+}
+
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv", ".tab":
+		return "tsv"
+	case ".jsonl", ".json", ".ndjson":
+		return "jsonl"
+	default:
+		return "csv"
+	}
+}
+
+// readInput writes each pair read from the named input (or stdin, for "-") to w, returning how many it wrote.
+func (rr *recordReader) readInput(name string, w pairWriter) (int, error) {
+	in := os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	count := 0
+	err := rr.read(in, func(line int, key, val string) error {
+		k, err := rr.keyEnc.Decode(key)
+		if err != nil {
+			return fmt.Errorf("line %d: bad key %q: %v", line, key, err)
+		}
+		v, err := rr.valEnc.Decode(val)
+		if err != nil {
+			return fmt.Errorf("line %d: bad value %q: %v", line, val, err)
+		}
+		if err := w.Write(k, v); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("%s: %v", name, err)
+	}
+	return count, nil
+}
+
+// read calls emit with the (still encoded) key and value of each record in the input, along with its line number.
+func (rr *recordReader) read(in io.Reader, emit func(line int, key, val string) error) error {
+	switch rr.format {
+	case "csv":
+		r := csv.NewReader(bufio.NewReader(in))
+		r.FieldsPerRecord = -1
+		r.ReuseRecord = true
+		for line := 1; ; line++ {
+			record, err := r.Read()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if line == 1 && rr.header {
+				continue
+			}
+			if err := rr.emitColumns(line, record, emit); err != nil {
+				return err
+			}
+		}
+
+	case "tsv":
+		s := bufio.NewScanner(in)
+		s.Buffer(nil, 64*1024*1024)
+		for line := 1; s.Scan(); line++ {
+			if line == 1 && rr.header {
+				continue
+			}
+			if err := rr.emitColumns(line, strings.Split(s.Text(), "\t"), emit); err != nil {
+				return err
+			}
+		}
+		return s.Err()
+
+	default:
+		s := bufio.NewScanner(in)
+		s.Buffer(nil, 64*1024*1024)
+		for line := 1; s.Scan(); line++ {
+			if len(strings.TrimSpace(s.Text())) == 0 {
+				continue
+			}
+			d := json.NewDecoder(strings.NewReader(s.Text()))
+			d.UseNumber()
+			var record map[string]interface{}
+			if err := d.Decode(&record); err != nil {
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			key, err := jsonField(record, rr.keyField)
+			if err != nil {
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			val, err := jsonField(record, rr.valField)
+			if err != nil {
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			if err := emit(line, key, val); err != nil {
+				return err
+			}
+		}
+		return s.Err()
+	}
+}
+
+func (rr *recordReader) emitColumns(line int, record []string, emit func(line int, key, val string) error) error {
+	if rr.keyColumn >= len(record) || rr.valColumn >= len(record) {
+		return fmt.Errorf("line %d: only %d columns", line, len(record))
+	}
+	return emit(line, record[rr.keyColumn], record[rr.valColumn])
+}
+
+// jsonField returns the named field, which must be a string or a number, as a string.
+func jsonField(record map[string]interface{}, name string) (string, error) {
+	switch v := record[name].(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "", fmt.Errorf("missing field %q", name)
+	default:
+		return "", fmt.Errorf("field %q is not a string or number", name)
+	}
+}
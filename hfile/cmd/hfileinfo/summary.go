@@ -3,22 +3,136 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sort"
+	"text/tabwriter"
 
 	"github.com/foursquare/quiver/hfile"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("supply path/to/file as first arg")
+	asJson := flag.Bool("json", false, "print a JSON description of each file, one per line, rather than text")
+	trailer := flag.Bool("trailer", false, "show the trailer's fields")
+	fileInfo := flag.Bool("fileinfo", false, "show the FileInfo fields and meta blocks")
+	blocks := flag.Bool("blocks", false, "show each data block's offset, sizes, entry count and first and last keys (reads every block)")
+	stats := flag.Bool("stats", false, "show key and value size histograms and duplicate key counts (reads every block)")
+	all := flag.Bool("all", false, "show everything")
+	startKeys := flag.Int("keys", 10, "without other options, how many blocks' start keys to show")
+	debug := flag.Bool("debug", false, "verbose output")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nUsage: %s [options] path/to/file.hfile [more/files.hfile...]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(flag.Args()) < 1 {
+		flag.Usage()
 		os.Exit(-1)
 	}
 
-	if r, err := hfile.NewReader(os.Args[1], os.Args[1], hfile.OnDisk, true); err != nil {
-		log.Fatal(err)
-	} else {
-		r.PrintDebugInfo(os.Stdout, 10)
+	if *all {
+		*trailer, *fileInfo, *blocks, *stats = true, true, true, true
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	for _, path := range flag.Args() {
+		r, err := hfile.NewReader(path, path, hfile.OnDisk, *debug)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if !*asJson && !*trailer && !*fileInfo && !*blocks && !*stats {
+			r.PrintDebugInfo(os.Stdout, *startKeys)
+			continue
+		}
+
+		d, err := r.Describe(*blocks, *stats)
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+
+		if *asJson {
+			if err := out.Encode(d); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+		printDescription(os.Stdout, d, *trailer, *fileInfo)
+	}
+}
+
+func printDescription(w io.Writer, d *hfile.FileDescription, trailer, fileInfo bool) {
+	fmt.Fprintf(w, "%s\n", d.Path)
+	fmt.Fprintf(w, "version: %s\n", d.Version)
+	fmt.Fprintf(w, "codec: %s\n", d.Codec)
+	fmt.Fprintf(w, "entries: %d\n", d.Trailer.EntryCount)
+
+	if trailer {
+		t := d.Trailer
+		fmt.Fprintln(w, "trailer:")
+		tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+		fmt.Fprintf(tw, "\tfile info offset:\t%d\n", t.FileInfoOffset)
+		fmt.Fprintf(tw, "\tdata index offset:\t%d\n", t.DataIndexOffset)
+		fmt.Fprintf(tw, "\tdata index count:\t%d\n", t.DataIndexCount)
+		fmt.Fprintf(tw, "\tmeta index offset:\t%d\n", t.MetaIndexOffset)
+		fmt.Fprintf(tw, "\tmeta index count:\t%d\n", t.MetaIndexCount)
+		fmt.Fprintf(tw, "\ttotal uncompressed bytes:\t%d\n", t.TotalUncompressedDataBytes)
+		fmt.Fprintf(tw, "\tentry count:\t%d\n", t.EntryCount)
+		fmt.Fprintf(tw, "\tcompression codec:\t%d\n", t.CompressionCodec)
+		if t.NumDataIndexLevels > 0 {
+			fmt.Fprintf(tw, "\tuncompressed data index size:\t%d\n", t.UncompressedDataIndexSize)
+			fmt.Fprintf(tw, "\tdata index levels:\t%d\n", t.NumDataIndexLevels)
+			fmt.Fprintf(tw, "\tfirst data block offset:\t%d\n", t.FirstDataBlockOffset)
+			fmt.Fprintf(tw, "\tlast data block offset:\t%d\n", t.LastDataBlockOffset)
+			fmt.Fprintf(tw, "\tcomparator:\t%s\n", t.ComparatorClassName)
+		}
+		tw.Flush()
+	}
+
+	if fileInfo {
+		fmt.Fprintln(w, "file info:")
+		keys := make([]string, 0, len(d.FileInfo))
+		for k := range d.FileInfo {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+		for _, k := range keys {
+			fmt.Fprintf(tw, "\t%s:\t%s\n", k, d.FileInfo[k])
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "meta blocks: %v\n", d.MetaBlocks)
+	}
+
+	if d.Blocks != nil {
+		fmt.Fprintln(w, "blocks:")
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "\t#\toffset\tcompressed\tuncompressed\tentries\tfirst key\tlast key")
+		for i, b := range d.Blocks {
+			fmt.Fprintf(tw, "\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n", i, b.Offset, b.CompressedSize, b.UncompressedSize, b.Entries, b.FirstKey, b.LastKey)
+		}
+		tw.Flush()
+	}
+
+	if s := d.Stats; s != nil {
+		printHistogram(w, "key sizes", s.KeySizes)
+		printHistogram(w, "value sizes", s.ValueSizes)
+		fmt.Fprintf(w, "keys: %d distinct, %d with more than one value, at most %d values per key\n",
+			s.DistinctKeys, s.DuplicatedKeys, s.MaxValuesPerKey)
+	}
+}
+
+func printHistogram(w io.Writer, name string, h hfile.SizeHistogram) {
+	fmt.Fprintf(w, "%s: min %d, max %d, mean %.1f\n", name, h.Min, h.Max, h.Mean)
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.AlignRight)
+	for _, b := range h.Buckets {
+		fmt.Fprintf(tw, "\t%d\t-\t%d:\t%d\t\n", b.Min, b.Max, b.Count)
 	}
+	tw.Flush()
 }
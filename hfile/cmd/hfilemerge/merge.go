@@ -0,0 +1,78 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/foursquare/quiver/hfile"
+)
+
+func main() {
+	mode := flag.String("policy", "all", "for keys in more than one input, keep values from: all (every input), newest (the newest input with the key) or tombstone (as newest, but drop keys marked with -tombstone)")
+	tombstone := flag.String("tombstone", "", "value marking deleted keys, for -policy=tombstone")
+	emptyTombstone := flag.Bool("empty-tombstone", false, "for -policy=tombstone, treat empty values as marking deleted keys, instead of -tombstone")
+	codecName := flag.String("codec", "snappy", "compression codec: none, snappy, lz4, gzip or zstd")
+	blockSize := flag.Int("blocksize", 64*1024, "block size in bytes")
+	bloom := flag.Int("bloom", 0, "store a bloom filter with this wrong-positive % in the output (or 0 to skip)")
+	verbose := flag.Bool("verbose", false, "verbose output")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nUsage: %s [options] path/to/output oldest/input [newer/inputs...]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(flag.Args()) < 2 {
+		flag.Usage()
+		os.Exit(-1)
+	}
+
+	policy := hfile.MergePolicy{}
+	var err error
+	if policy.Mode, err = hfile.ParseMergeMode(*mode); err != nil {
+		log.Fatal(err)
+	}
+	if policy.Mode == hfile.MergeDropTombstones {
+		if *emptyTombstone {
+			if *tombstone != "" {
+				log.Fatal("-empty-tombstone and -tombstone can't both be set")
+			}
+			policy.EmptyTombstone = true
+		} else if *tombstone == "" {
+			log.Fatal("-policy=tombstone requires -tombstone")
+		}
+		policy.Tombstone = []byte(*tombstone)
+	}
+
+	codec, err := hfile.ParseCompressionCodec(*codecName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	inputs := flag.Args()[1:]
+	readers := make([]*hfile.Reader, len(inputs))
+	entries := 0
+	for i, path := range inputs {
+		if readers[i], err = hfile.NewReader(path, path, hfile.OnDisk, *verbose); err != nil {
+			log.Fatalf("Error opening %s: %v", path, err)
+		}
+		entries += int(readers[i].EntryCount)
+	}
+
+	w, err := hfile.NewLocalWriter(flag.Arg(0), codec, *blockSize, *verbose)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *bloom > 0 {
+		w.EnableBloom(entries, float64(*bloom)/100)
+	}
+
+	if err := hfile.Merge(readers, w, policy); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Merged %d files into %s.", len(inputs), flag.Arg(0))
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/foursquare/quiver/hfile"
+)
+
+func main() {
+	function := flag.String("function", "mod_first_byte", "shard function, as in collection/function/partitions/partition")
+	partitions := flag.Int("partitions", 0, "number of partitions to write")
+	output := flag.String("output", "part-%05d.hfile", "path to write each partition to, formatted with its number")
+	codecName := flag.String("codec", "snappy", "compression codec: none, snappy, lz4, gzip or zstd")
+	blockSize := flag.Int("blocksize", 64*1024, "block size in bytes")
+	verbose := flag.Bool("verbose", false, "verbose output")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nUsage: %s -partitions N [options] path/to/input [more/inputs...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Inputs may be a whole collection or all of its existing partitions.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(flag.Args()) < 1 || *partitions < 1 {
+		flag.Usage()
+		os.Exit(-1)
+	}
+
+	shard, err := hfile.GetShardFunction(*function)
+	if err != nil {
+		log.Fatal(err)
+	}
+	codec, err := hfile.ParseCompressionCodec(*codecName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	readers := make([]*hfile.Reader, len(flag.Args()))
+	for i, path := range flag.Args() {
+		if readers[i], err = hfile.NewReader(path, path, hfile.OnDisk, *verbose); err != nil {
+			log.Fatalf("Error opening %s: %v", path, err)
+		}
+	}
+
+	writers := make([]*hfile.Writer, *partitions)
+	for i := range writers {
+		if writers[i], err = hfile.NewLocalWriter(fmt.Sprintf(*output, i), codec, *blockSize, *verbose); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := hfile.Reshard(readers, shard, writers); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d %s partitions to %s.", *partitions, *function, *output)
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/foursquare/quiver/hfile"
+)
+
+func main() {
+	quiet := flag.Bool("quiet", false, "only print reports for files with problems")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nUsage: %s [options] path/to/file.hfile [more/files.hfile...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Prints a JSON report for each file, one per line, and exits non-zero if any file has problems.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(flag.Args()) < 1 {
+		flag.Usage()
+		os.Exit(-1)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	failed := 0
+	for _, path := range flag.Args() {
+		report := hfile.VerifyFile(path)
+		if !report.Ok {
+			failed++
+		} else if *quiet {
+			continue
+		}
+		if err := out.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if failed > 0 {
+		log.Printf("%d of %d files have problems.", failed, len(flag.Args()))
+		os.Exit(1)
+	}
+}
@@ -12,25 +12,74 @@ import (
 )
 
 func main() {
-	compress := flag.Bool("compress", false, "compression")
+	compress := flag.Bool("compress", false, "compression (snappy, unless -codec is set)")
+	codecName := flag.String("codec", "", "compression codec: none, snappy, lz4, gzip or zstd")
 	blockSize := flag.Int("blocksize", 4098, "block size in bytes")
 	verbose := flag.Bool("verbose", false, "verbose output")
 
-	keys := flag.Int("keys", 10000, "number of k-v pairs to generate")
+	keys := flag.Int("keys", 10000, "number of keys to generate")
+	bloom := flag.Int("bloom", 0, "store a bloom filter with this wrong-positive % in the file (or 0 to skip)")
 
-	flag.Parse()
+	seed := flag.Int64("seed", 0, "random seed: the same flags and seed always generate the same pairs")
+	keyStyle := flag.String("key-style", "sequential", "sequential (4-byte ints), random (alphanumerics) or prefixed (a prefix like '0042:' then alphanumerics)")
+	keySize := flag.String("key-size", "16", "for random and prefixed keys, the length of the random part: N, MIN-MAX, normal:MEAN,STDDEV[:MIN-MAX] or lognormal:MEDIAN,STDDEV[:MIN-MAX]")
+	prefixes := flag.Int("prefixes", 100, "for prefixed keys, the number of distinct prefixes")
+	prefixSkew := flag.Float64("prefix-skew", 0, "if more than 1, pick prefixes with this Zipf exponent instead of uniformly")
+	valueSize := flag.String("value-size", "", "value sizes, as for -key-size (default: values like 'value-for-N')")
+	maxValues := flag.Int("max-values", 1, "the most values any one key has")
+	valuesSkew := flag.Float64("values-skew", 2, "Zipf exponent (more than 1) for how many values each key has, if -max-values is more than 1")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "\nUsage: %s [options] path/to/file\n\n", os.Args[0])
 		flag.PrintDefaults()
 	}
+	flag.Parse()
 
 	if len(flag.Args()) < 1 {
 		flag.Usage()
 		os.Exit(-1)
 	}
 
-	if err := hfile.GenerateMockHfile(flag.Arg(0), *keys, *blockSize, *compress, *verbose, true); err != nil {
+	codec := hfile.CompressionNone
+	if *codecName != "" {
+		var err error
+		if codec, err = hfile.ParseCompressionCodec(*codecName); err != nil {
+			log.Fatal(err)
+		}
+	} else if *compress {
+		codec = hfile.CompressionSnappy
+	}
+
+	spec := hfile.MockDataSpec{
+		Keys:        *keys,
+		Seed:        *seed,
+		Prefixes:    *prefixes,
+		PrefixSkew:  *prefixSkew,
+		MaxValues:   *maxValues,
+		ValuesSkew:  *valuesSkew,
+		LegacyValue: *valueSize == "",
+	}
+	var err error
+	if spec.KeyStyle, err = hfile.ParseMockKeyStyle(*keyStyle); err != nil {
+		log.Fatal(err)
+	}
+	if spec.KeySize, err = hfile.ParseSizeDistribution(*keySize); err != nil {
+		log.Fatal(err)
+	}
+	if !spec.LegacyValue {
+		if spec.ValueSize, err = hfile.ParseSizeDistribution(*valueSize); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	w, err := hfile.NewLocalWriter(flag.Arg(0), codec, *blockSize, *verbose)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *bloom > 0 {
+		w.EnableBloom(*keys, float64(*bloom)/100)
+	}
+	if err := hfile.WriteMockData(w, spec, true); err != nil {
 		log.Fatal(err)
 	}
 }
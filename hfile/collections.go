@@ -29,6 +29,19 @@ const (
 	OnDisk
 )
 
+func (l LoadMethod) String() string {
+	switch l {
+	case CopiedToMem:
+		return "CopiedToMem"
+	case MemlockFile:
+		return "MemlockFile"
+	case OnDisk:
+		return "OnDisk"
+	default:
+		return fmt.Sprintf("LoadMethod(%d)", int(l))
+	}
+}
+
 type CollectionConfig struct {
 	// The Name of the collection.
 	Name string
@@ -53,6 +66,9 @@ type CollectionConfig struct {
 	ShardFunction   string
 	Partition       string
 	TotalPartitions string
+
+	// How many bytes of decompressed blocks to cache, if the hfile is compressed (or 0 to not cache).
+	BlockCacheSize int
 }
 
 type CollectionSet struct {
@@ -81,8 +97,13 @@ func LoadCollections(collections []*CollectionConfig, cache string, downloadOnly
 	for _, cfg := range collections {
 		reader, err := NewReaderFromConfig(*cfg)
 		if err != nil {
-			return nil, err
+			// Nothing else can be using the collections opened so far.
+			for _, opened := range cs.Collections {
+				opened.Unload(0)
+			}
+			return nil, fmt.Errorf("error opening %s (%s): %v", cfg.Name, cfg.LocalPath, err)
 		}
+		reader.stats = stats
 
 		cs.Collections[cfg.Name] = reader
 	}
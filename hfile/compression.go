@@ -0,0 +1,269 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/bkaradzic/go-lz4"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+/*
+Blocks are compressed the way Hadoop's codecs compress them, so that files can be exchanged with HBase:
+
+- snappy and LZ4 use BlockCompressorStream framing (see decodeBlockStream), around raw snappy and LZ4 blocks.
+- gzip and zstd are plain gzip and zstd streams.
+*/
+
+var compressionNames = map[uint32]string{
+	CompressionGz:     "gz",
+	CompressionNone:   "none",
+	CompressionSnappy: "snappy",
+	CompressionLz4:    "lz4",
+	CompressionZstd:   "zstd",
+}
+
+// CompressionCodecName returns the name of a compression codec, as HBase names it.
+func CompressionCodecName(codec uint32) string {
+	if name, ok := compressionNames[codec]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", codec)
+}
+
+// ParseCompressionCodec returns the codec with the given name (e.g. "snappy" or "lz4").
+func ParseCompressionCodec(name string) (uint32, error) {
+	name = strings.ToLower(name)
+	if name == "gzip" {
+		name = "gz"
+	}
+	for codec, n := range compressionNames {
+		if n == name {
+			return codec, nil
+		}
+	}
+	return 0, fmt.Errorf("Unsupported compression codec %q", name)
+}
+
+/*
+Hadoop's BlockCompressorStream splits large inputs into chunks that fit its (by default, 256kb) buffers.
+Readers handle any number of chunks, so we always use chunks small enough for either codec's buffer.
+*/
+const blockStreamChunkSize = 64 * 1024
+
+// Neither snappy nor LZ4 can decompress a chunk to more than this many times its compressed size.
+const maxBlockStreamRatio = 256
+
+// compress returns src compressed, and framed, with the given codec.
+func compress(codec uint32, src []byte) ([]byte, error) {
+	switch codec {
+	case CompressionNone:
+		return src, nil
+	case CompressionSnappy:
+		return encodeBlockStream(src, func(chunk []byte) ([]byte, error) {
+			return snappy.Encode(nil, chunk), nil
+		})
+	case CompressionLz4:
+		return encodeBlockStream(src, encodeLz4)
+	case CompressionGz:
+		buf := new(bytes.Buffer)
+		w := gzip.NewWriter(buf)
+		if _, err := w.Write(src); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		return zstdEncoder().EncodeAll(src, nil), nil
+	default:
+		return nil, fmt.Errorf("Unsupported compression codec %d", codec)
+	}
+}
+
+// encodeBlockStream writes the uncompressed length of src, followed by its length-prefixed compressed chunks.
+func encodeBlockStream(src []byte, encode func([]byte) ([]byte, error)) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint32(len(src)))
+
+	for p := 0; p < len(src); p += blockStreamChunkSize {
+		end := p + blockStreamChunkSize
+		if end > len(src) {
+			end = len(src)
+		}
+		compressed, err := encode(src[p:end])
+		if err != nil {
+			return nil, err
+		}
+		binary.Write(buf, binary.BigEndian, uint32(len(compressed)))
+		buf.Write(compressed)
+	}
+	return buf.Bytes(), nil
+}
+
+// go-lz4 prefixes its output with the little-endian uncompressed length, which Hadoop does not expect.
+func encodeLz4(src []byte) ([]byte, error) {
+	compressed, err := lz4.Encode(nil, src)
+	if err != nil {
+		return nil, err
+	}
+	return compressed[4:], nil
+}
+
+var errCorruptLz4 = errors.New("corrupt lz4 block")
+
+/*
+decodeLz4 decodes a raw LZ4 block into dst, which must be large enough to hold it.
+
+go-lz4's Decode needs to know the exact uncompressed size up front, which Hadoop's framing
+only records for the whole stream, not for each compressed chunk, so we decode blocks ourselves.
+*/
+func decodeLz4(dst, src []byte) ([]byte, error) {
+	d, s := 0, 0
+
+	readLen := func(l int) (int, error) {
+		for {
+			if s >= len(src) {
+				return 0, errCorruptLz4
+			}
+			b := src[s]
+			s++
+			l += int(b)
+			if b != 255 {
+				return l, nil
+			}
+		}
+	}
+
+	for s < len(src) {
+		token := src[s]
+		s++
+
+		literals := int(token >> 4)
+		if literals == 15 {
+			var err error
+			if literals, err = readLen(literals); err != nil {
+				return nil, err
+			}
+		}
+		if s+literals > len(src) || d+literals > len(dst) {
+			return nil, errCorruptLz4
+		}
+		copy(dst[d:], src[s:s+literals])
+		s += literals
+		d += literals
+
+		// The last sequence has only literals.
+		if s == len(src) {
+			break
+		}
+
+		if s+2 > len(src) {
+			return nil, errCorruptLz4
+		}
+		offset := int(src[s]) | int(src[s+1])<<8
+		s += 2
+		if offset == 0 || offset > d {
+			return nil, errCorruptLz4
+		}
+
+		matchLen := int(token & 0xf)
+		if matchLen == 15 {
+			var err error
+			if matchLen, err = readLen(matchLen); err != nil {
+				return nil, err
+			}
+		}
+		matchLen += 4
+		if d+matchLen > len(dst) {
+			return nil, errCorruptLz4
+		}
+
+		if offset >= matchLen {
+			copy(dst[d:d+matchLen], dst[d-offset:])
+		} else {
+			// Overlapping matches repeat the bytes being written, so must be copied one by one.
+			for i := 0; i < matchLen; i++ {
+				dst[d+i] = dst[d-offset+i]
+			}
+		}
+		d += matchLen
+	}
+	return dst[:d], nil
+}
+
+func decodeGzip(src, dst []byte, size int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	return readFullBlock(r, dst, size)
+}
+
+var zstdDecoders = sync.Pool{
+	New: func() interface{} {
+		// With a concurrency of 1, decoding happens synchronously in Read, without background goroutines.
+		d, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			panic(err)
+		}
+		return d
+	},
+}
+
+/*
+decodeZstd decodes size bytes from the zstd stream at the start of src.
+
+Since v1 indexes do not record blocks' compressed sizes, src may run on past the end of the
+stream, so we read just what we need rather than decoding all of src.
+*/
+func decodeZstd(src, dst []byte, size int) ([]byte, error) {
+	d := zstdDecoders.Get().(*zstd.Decoder)
+	defer zstdDecoders.Put(d)
+
+	if err := d.Reset(bytes.NewReader(src)); err != nil {
+		return nil, err
+	}
+	return readFullBlock(d, dst, size)
+}
+
+func readFullBlock(r io.Reader, dst []byte, size int) ([]byte, error) {
+	if len(dst) >= size {
+		if _, err := io.ReadFull(r, dst[:size]); err != nil {
+			return nil, fmt.Errorf("error decompressing block: %v", err)
+		}
+		return dst[:size], nil
+	}
+
+	// The size comes from the file, so rather than allocating it up front, grow as the block actually decompresses.
+	buf := bytes.NewBuffer(dst[:0])
+	if _, err := io.CopyN(buf, r, int64(size)); err != nil {
+		return nil, fmt.Errorf("error decompressing block: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var zstdEncoderOnce sync.Once
+var sharedZstdEncoder *zstd.Encoder
+
+// zstdEncoder returns an encoder shared by all writers, which is safe since we only use EncodeAll.
+func zstdEncoder() *zstd.Encoder {
+	zstdEncoderOnce.Do(func() {
+		e, err := zstd.NewWriter(nil)
+		if err != nil {
+			panic(err)
+		}
+		sharedZstdEncoder = e
+	})
+	return sharedZstdEncoder
+}
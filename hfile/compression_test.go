@@ -0,0 +1,55 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// codecFixtureData returns what the testdata/hadoop-*-N.bin fixtures decompress to: N lines like a sorted run of pairs.
+func codecFixtureData(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "key-%08d\tvalue-for-%d\n", i, i*7919%100003)
+	}
+	return buf.Bytes()
+}
+
+// The fixtures are made by testdata/gen-codec-fixtures.py, framed as Hadoop's Lz4Codec and ZStandardCodec frame blocks.
+func TestDecompressHadoopCodecs(t *testing.T) {
+	for _, codec := range []struct {
+		name  string
+		codec uint32
+	}{
+		{"lz4", CompressionLz4},
+		{"zstd", CompressionZstd},
+	} {
+		r := &Reader{Trailer: Trailer{CompressionCodec: codec.codec}}
+
+		for _, n := range []int{2000, 10000} {
+			name := fmt.Sprintf("testdata/hadoop-%s-%d.bin", codec.name, n)
+			src, err := ioutil.ReadFile(name)
+			if !assert.Nil(t, err, err) {
+				continue
+			}
+			expected := codecFixtureData(n)
+
+			dst, err := r.decompress(src, nil, len(expected))
+			assert.Nil(t, err, "%s: %v", name, err)
+			assert.True(t, bytes.Equal(expected, dst), "%s decompressed wrong", name)
+
+			// v1 blocks are read up to the next block, so the stream may be followed by more data.
+			dst, err = r.decompress(append(src, bytes.Repeat([]byte{0xff}, 100)...), make([]byte, len(expected)), len(expected))
+			assert.Nil(t, err, "%s: %v", name, err)
+			assert.True(t, bytes.Equal(expected, dst), "%s, followed by more data, decompressed wrong", name)
+
+			_, err = r.decompress(src[:len(src)/2], nil, len(expected))
+			assert.NotNil(t, err, "%s: truncated stream should be an error", name)
+		}
+	}
+}
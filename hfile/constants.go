@@ -5,6 +5,22 @@ package hfile
 var IndexMagic = []byte("IDXBLK)+")
 var DataMagic = []byte("DATABLK*")
 var TrailerMagic = []byte("TRABLK\"$")
+var MetaMagic = []byte("METABLKc")
 
+// Block magics used by HFile v2.
+var EncodedDataMagic = []byte("DATABLKE")
+var LeafIndexMagic = []byte("IDXLEAF2")
+var IntermediateIndexMagic = []byte("IDXINTE2")
+var RootIndexMagic = []byte("IDXROOT2")
+var FileInfoMagic = []byte("FILEINF2")
+
+// Compression codecs, numbered as in HBase's Compression.Algorithm.
+var CompressionGz = uint32(1)
 var CompressionNone = uint32(2)
 var CompressionSnappy = uint32(3)
+var CompressionLz4 = uint32(4)
+var CompressionZstd = uint32(6)
+
+// Stats recorded for block cache lookups.
+var blockCacheHitStat = "hfile.blockcache.hit"
+var blockCacheMissStat = "hfile.blockcache.miss"
@@ -0,0 +1,138 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// corruptSeeds returns small, valid files of each version and codec, with meta blocks and a bloom filter, to corrupt.
+func corruptSeeds(t testing.TB) [][]byte {
+	var seeds [][]byte
+
+	for _, codec := range []uint32{CompressionNone, CompressionSnappy, CompressionLz4, CompressionGz, CompressionZstd} {
+		fp, err := ioutil.TempFile("", "corrupthfile")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(fp.Name())
+
+		w, err := NewWriter(fp, codec, 128, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.EnableBloom(20, 0.1)
+		w.AppendMetaBlock("sidecar", []byte("some sidecar data"))
+		for i := 0; i < 20; i++ {
+			if err := w.Write(MockKeyInt(i*2), MockValueInt(i*2)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := ioutil.ReadFile(fp.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		seeds = append(seeds, data)
+	}
+
+	for _, minor := range []uint32{0, 1, 3} {
+		keys, vals := make([][]byte, 20), make([][]byte, 20)
+		for i := range keys {
+			keys[i], vals[i] = MockKeyInt(i*2), MockValueInt(i*2)
+		}
+		f := &v2TestFile{minor: minor, codec: CompressionSnappy, fanout: 2}
+		seeds = append(seeds, f.write(keys, vals, 64))
+	}
+	return seeds
+}
+
+/*
+readCorrupt opens data as an hfile and, if that works, reads it every way we can, returning a
+description of any problem that should not have been possible (i.e. anything but an error).
+*/
+func readCorrupt(data []byte) (problem string) {
+	defer func() {
+		if p := recover(); p != nil {
+			problem = fmt.Sprintf("panic: %v", p)
+		}
+	}()
+
+	// Like fetched content, which the reader frees when it's done with it, this must be off the gc's heap.
+	buf := offheapMalloc(int64(len(data)))
+	copy(buf, data)
+	r, err := NewReaderFromConfig(CollectionConfig{Name: "corrupt", cachedContent: &buf})
+	if err != nil {
+		return ""
+	}
+	// Not Unload, which would wait forever for anything a panic left checked out.
+	defer r.free()
+
+	r.FirstKey()
+	r.LastKey()
+	r.SampleKeys(5)
+	for _, name := range r.MetaBlockNames() {
+		r.MetaBlock(name)
+	}
+
+	// Verify recovers from panics itself, reporting them as problems.
+	for _, p := range r.Verify().Problems {
+		if p.Check == "panic" {
+			return p.Message
+		}
+	}
+
+	it := r.GetIterator()
+	var last []byte
+	for ok, _ := it.Next(); ok; ok, _ = it.Next() {
+		last = it.Key()
+	}
+	for i, ok := 0, true; ok && i < 10; i++ {
+		ok, _ = it.Prev()
+	}
+	if last != nil {
+		it.SeekBefore(last)
+		it.Seek(last)
+		it.AllForPrefixes([][]byte{last[:1]}, 10, nil)
+	}
+	it.Release()
+
+	s := r.GetScanner()
+	s.EnforceKeyOrder = false
+	for i := 0; i < 40; i++ {
+		s.GetAll(MockKeyInt(i))
+	}
+	s.GetFirst(MockKeyInt(0))
+	s.Release()
+	return ""
+}
+
+func TestCorruptFiles(t *testing.T) {
+	for i, seed := range corruptSeeds(t) {
+		if problem := readCorrupt(seed); problem != "" {
+			t.Fatalf("seed %d: %s", i, problem)
+		}
+
+		for l := 0; l < len(seed); l++ {
+			if problem := readCorrupt(seed[:l]); problem != "" {
+				t.Fatalf("seed %d truncated to %d bytes: %s", i, l, problem)
+			}
+		}
+
+		for p := 0; p < len(seed); p++ {
+			for _, flip := range []byte{0x01, 0x80, 0xff} {
+				corrupt := append([]byte{}, seed...)
+				corrupt[p] ^= flip
+				if problem := readCorrupt(corrupt); problem != "" {
+					t.Fatalf("seed %d with byte %d ^ %x: %s", i, p, flip, problem)
+				}
+			}
+		}
+	}
+}
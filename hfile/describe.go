@@ -0,0 +1,203 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+)
+
+// A FileDescription describes an hfile's structure and contents, in a form suitable for encoding as JSON.
+type FileDescription struct {
+	Path       string            `json:"path"`
+	Version    string            `json:"version"`
+	Codec      string            `json:"codec"`
+	Trailer    Trailer           `json:"trailer"`
+	FileInfo   map[string]string `json:"fileInfo"`
+	MetaBlocks []string          `json:"metaBlocks"`
+
+	// Only filled in if requested, since they require reading every block.
+	Blocks []BlockDescription `json:"blocks,omitempty"`
+	Stats  *EntryStats        `json:"stats,omitempty"`
+}
+
+// A BlockDescription describes a data block. Keys are hex-encoded.
+type BlockDescription struct {
+	Offset           uint64 `json:"offset"`
+	CompressedSize   uint64 `json:"compressedSize"`   // As stored, including any v2 header and checksums.
+	UncompressedSize uint64 `json:"uncompressedSize"` // Including the v1 magic or v2 header.
+	Entries          int    `json:"entries"`
+	FirstKey         string `json:"firstKey"`
+	LastKey          string `json:"lastKey"`
+}
+
+// EntryStats summarizes the sizes of a file's keys and values, and how many keys have more than one value.
+type EntryStats struct {
+	Entries    uint64        `json:"entries"`
+	KeySizes   SizeHistogram `json:"keySizes"`
+	ValueSizes SizeHistogram `json:"valueSizes"`
+
+	DistinctKeys    uint64 `json:"distinctKeys"`
+	DuplicatedKeys  uint64 `json:"duplicatedKeys"`  // Keys with more than one value.
+	MaxValuesPerKey uint64 `json:"maxValuesPerKey"` // The most values any one key has.
+}
+
+// A SizeHistogram counts sizes in power-of-two buckets.
+type SizeHistogram struct {
+	Min     uint64       `json:"min"`
+	Max     uint64       `json:"max"`
+	Mean    float64      `json:"mean"`
+	Buckets []SizeBucket `json:"buckets"`
+
+	total uint64
+	count uint64
+}
+
+// A SizeBucket counts sizes from Min to Max, inclusive. Empty buckets are omitted from histograms.
+type SizeBucket struct {
+	Min   uint64 `json:"min"`
+	Max   uint64 `json:"max"`
+	Count uint64 `json:"count"`
+}
+
+func (h *SizeHistogram) add(size uint64) {
+	if h.count == 0 || size < h.Min {
+		h.Min = size
+	}
+	if size > h.Max {
+		h.Max = size
+	}
+	h.total += size
+	h.count++
+	h.Mean = float64(h.total) / float64(h.count)
+
+	// Sizes with the same bit length share a bucket: 0, 1, 2-3, 4-7 and so on.
+	b := uint64(bits.Len64(size))
+	min, max := uint64(0), uint64(0)
+	if b > 0 {
+		min, max = 1<<(b-1), 1<<b-1
+	}
+	i := 0
+	for i < len(h.Buckets) && h.Buckets[i].Min < min {
+		i++
+	}
+	if i == len(h.Buckets) || h.Buckets[i].Min != min {
+		h.Buckets = append(h.Buckets, SizeBucket{})
+		copy(h.Buckets[i+1:], h.Buckets[i:])
+		h.Buckets[i] = SizeBucket{min, max, 0}
+	}
+	h.Buckets[i].Count++
+}
+
+/*
+Describe returns the file's trailer, FileInfo and meta block names, and, if requested, a
+description of every data block and statistics about the file's entries, both of which require
+reading (and, if compressed, decompressing) every block.
+*/
+func (r *Reader) Describe(blocks, stats bool) (*FileDescription, error) {
+	d := &FileDescription{
+		Path:       r.SourcePath,
+		Version:    fmt.Sprintf("%d.%d", r.majorVersion, r.minorVersion),
+		Codec:      CompressionCodecName(r.CompressionCodec),
+		Trailer:    r.Trailer,
+		FileInfo:   r.InfoFields,
+		MetaBlocks: r.MetaBlockNames(),
+	}
+	if !blocks && !stats {
+		return d, nil
+	}
+
+	if stats {
+		d.Stats = &EntryStats{KeySizes: SizeHistogram{Buckets: []SizeBucket{}}, ValueSizes: SizeHistogram{Buckets: []SizeBucket{}}}
+	}
+	var prev []byte
+	values := uint64(0)
+
+	for i := range r.index {
+		block, err := r.readDataBlock(i, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// readDataBlock has already checked that the entries are all within the block.
+		var first, last []byte
+		entries := 0
+		for p := 0; p < len(block); entries++ {
+			keyLen := int(binary.BigEndian.Uint32(block[p : p+4]))
+			valLen := int(binary.BigEndian.Uint32(block[p+4 : p+8]))
+			key := block[p+8 : p+8+keyLen]
+			if first == nil {
+				first = key
+			}
+			last = key
+
+			if stats {
+				d.Stats.Entries++
+				d.Stats.KeySizes.add(uint64(keyLen))
+				d.Stats.ValueSizes.add(uint64(valLen))
+
+				if prev != nil && bytes.Equal(prev, key) {
+					values++
+				} else {
+					d.Stats.addKey(values)
+					values = 1
+					prev = append(prev[:0], key...)
+				}
+			}
+			p = r.skipMemstoreTS(block, p+8+keyLen+valLen)
+		}
+
+		if blocks {
+			d.Blocks = append(d.Blocks, BlockDescription{
+				Offset:           r.index[i].offset,
+				CompressedSize:   r.blockOnDiskSize(i),
+				UncompressedSize: uint64(len(block)) + r.blockHeaderSize(),
+				Entries:          entries,
+				FirstKey:         hex.EncodeToString(first),
+				LastKey:          hex.EncodeToString(last),
+			})
+		}
+	}
+	if stats {
+		d.Stats.addKey(values)
+	}
+	return d, nil
+}
+
+// addKey counts a key with the given number of values (or nothing, if there are none).
+func (s *EntryStats) addKey(values uint64) {
+	if values == 0 {
+		return
+	}
+	s.DistinctKeys++
+	if values > 1 {
+		s.DuplicatedKeys++
+	}
+	if values > s.MaxValuesPerKey {
+		s.MaxValuesPerKey = values
+	}
+}
+
+// blockHeaderSize returns the size of what precedes a data block's entries: its magic, or, in v2, its header.
+func (r *Reader) blockHeaderSize() uint64 {
+	if r.majorVersion > 1 {
+		return r.blockHeaderSizeV2()
+	}
+	return uint64(len(DataMagic))
+}
+
+// blockOnDiskSize returns the size of the i-th data block as stored, which v1 indexes do not record.
+func (r *Reader) blockOnDiskSize(i int) uint64 {
+	offset := r.index[i].offset
+	if r.majorVersion > 1 {
+		// Only called for blocks that have been read, so the header is known to be there.
+		return r.blockHeaderSizeV2() + uint64(binary.BigEndian.Uint32(r.data[offset+8:offset+12]))
+	}
+	if i+1 < len(r.index) {
+		return r.index[i+1].offset - offset
+	}
+	return r.dataEnd() - offset
+}
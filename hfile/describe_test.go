@@ -0,0 +1,89 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	var keys, vals [][]byte
+	for i := 0; i < 100; i++ {
+		for j := 0; j <= i%3; j++ {
+			keys = append(keys, MockKeyInt(i))
+			vals = append(vals, []byte("value"[:j+2]))
+		}
+	}
+	f, s := tempHfile(t, true, 64, keys, vals)
+	defer os.Remove(f)
+	r := s.reader
+
+	d, err := r.Describe(false, false)
+	assert.Nil(t, err, err)
+	assert.Equal(t, "1.0", d.Version)
+	assert.Equal(t, "snappy", d.Codec)
+	assert.Equal(t, uint32(len(keys)), d.Trailer.EntryCount)
+	assert.Equal(t, "4", d.FileInfo[FileInfoAvgKeyLen])
+	assert.Nil(t, d.Blocks)
+	assert.Nil(t, d.Stats)
+
+	d, err = r.Describe(true, true)
+	assert.Nil(t, err, err)
+	assert.Equal(t, r.NumBlocks(), len(d.Blocks))
+
+	entries, compressed, uncompressed := 0, uint64(0), uint64(0)
+	for i, b := range d.Blocks {
+		assert.Equal(t, r.index[i].offset, b.Offset)
+		assert.Equal(t, hex.EncodeToString(r.index[i].firstKeyBytes), b.FirstKey)
+		entries += b.Entries
+		compressed += b.CompressedSize
+		uncompressed += b.UncompressedSize
+	}
+	assert.Equal(t, len(keys), entries)
+	assert.Equal(t, r.CompressedDataBytes(), compressed)
+	assert.Equal(t, r.TotalUncompressedDataBytes, uncompressed)
+	assert.Equal(t, hex.EncodeToString(MockKeyInt(99)), d.Blocks[len(d.Blocks)-1].LastKey)
+
+	assert.Equal(t, uint64(len(keys)), d.Stats.Entries)
+	assert.Equal(t, uint64(100), d.Stats.DistinctKeys)
+	assert.Equal(t, uint64(66), d.Stats.DuplicatedKeys)
+	assert.Equal(t, uint64(3), d.Stats.MaxValuesPerKey)
+	assert.Equal(t, []SizeBucket{{4, 7, uint64(len(keys))}}, d.Stats.KeySizes.Buckets)
+	assert.Equal(t, []SizeBucket{{2, 3, 166}, {4, 7, 33}}, d.Stats.ValueSizes.Buckets)
+	assert.Equal(t, uint64(2), d.Stats.ValueSizes.Min)
+	assert.Equal(t, uint64(4), d.Stats.ValueSizes.Max)
+}
+
+func TestDescribeV2(t *testing.T) {
+	f, r := v2TestReader(t, 3, CompressionSnappy, 1000)
+	defer os.Remove(f)
+
+	d, err := r.Describe(true, false)
+	assert.Nil(t, err, err)
+	assert.Equal(t, "2.3", d.Version)
+
+	entries := 0
+	for i, b := range d.Blocks {
+		entries += b.Entries
+		if i+1 < len(d.Blocks) {
+			// Leaf index blocks may sit between data blocks, but never inside one.
+			assert.True(t, b.Offset+b.CompressedSize <= d.Blocks[i+1].Offset)
+		}
+	}
+	assert.Equal(t, 1000, entries)
+}
+
+func TestSizeHistogram(t *testing.T) {
+	h := SizeHistogram{}
+	for _, size := range []uint64{9, 0, 1, 2, 3, 4, 1000, 7, 8} {
+		h.add(size)
+	}
+	assert.Equal(t, uint64(0), h.Min)
+	assert.Equal(t, uint64(1000), h.Max)
+	assert.Equal(t, float64(1034)/9, h.Mean)
+	assert.Equal(t, []SizeBucket{{0, 0, 1}, {1, 1, 1}, {2, 3, 2}, {4, 7, 2}, {8, 15, 2}, {512, 1023, 1}}, h.Buckets)
+}
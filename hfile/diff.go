@@ -0,0 +1,112 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import "bytes"
+
+type DiffKind int
+
+const (
+	// The key is only in the new file.
+	DiffAdded DiffKind = iota
+	// The key is only in the old file.
+	DiffRemoved
+	// The key is in both files, with different values (or the same values, in a different order).
+	DiffChanged
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// A KeyDiff is a key whose values differ between two files. Its values are only valid until the callback returns.
+type KeyDiff struct {
+	Kind DiffKind
+	Key  []byte
+	Old  [][]byte
+	New  [][]byte
+}
+
+// DiffSummary counts the keys that two files do and do not have in common, with the first few keys of each kind of difference.
+type DiffSummary struct {
+	Unchanged uint64
+	Added     uint64
+	Removed   uint64
+	Changed   uint64
+
+	AddedKeys   [][]byte
+	RemovedKeys [][]byte
+	ChangedKeys [][]byte
+}
+
+// Same reports whether the files had the same keys and values.
+func (s *DiffSummary) Same() bool {
+	return s.Added == 0 && s.Removed == 0 && s.Changed == 0
+}
+
+func (s *DiffSummary) add(kind DiffKind, key []byte, samples int) {
+	count, keys := &s.Changed, &s.ChangedKeys
+	switch kind {
+	case DiffAdded:
+		count, keys = &s.Added, &s.AddedKeys
+	case DiffRemoved:
+		count, keys = &s.Removed, &s.RemovedKeys
+	}
+	*count++
+	if len(*keys) < samples {
+		*keys = append(*keys, key)
+	}
+}
+
+/*
+Diff walks the old and new files in key order, calling fn (if not nil) for each key whose values
+differ, and returns a summary, including the first samples keys of each kind of difference. Keys'
+values are compared in the order they were written, so reordering them counts as a change.
+*/
+func Diff(old, new *Reader, samples int, fn func(KeyDiff) error) (*DiffSummary, error) {
+	s := &DiffSummary{}
+
+	err := mergeKeys([]*Reader{old, new}, func(key []byte, values [][][]byte, _ int) error {
+		var kind DiffKind
+		switch {
+		case len(values[0]) == 0:
+			kind = DiffAdded
+		case len(values[1]) == 0:
+			kind = DiffRemoved
+		case sameValues(values[0], values[1]):
+			s.Unchanged++
+			return nil
+		default:
+			kind = DiffChanged
+		}
+
+		s.add(kind, key, samples)
+		if fn != nil {
+			return fn(KeyDiff{kind, key, values[0], values[1]})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func sameValues(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
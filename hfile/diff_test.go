@@ -0,0 +1,50 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	oldPath, old := mergeTestReader(t, [][2]string{{"a", "a1"}, {"b", "b1"}, {"b", "b2"}, {"c", "c1"}, {"d", "d1"}, {"e", "e1"}, {"g", "g1"}})
+	defer os.Remove(oldPath)
+	newPath, new := mergeTestReader(t, [][2]string{{"a", "a1"}, {"b", "b2"}, {"b", "b1"}, {"c", "c2"}, {"d", "d1"}, {"f", "f1"}, {"g", "g1"}, {"g", "g2"}, {"h", "h1"}})
+	defer os.Remove(newPath)
+
+	var found []string
+	s, err := Diff(old, new, 2, func(d KeyDiff) error {
+		found = append(found, fmt.Sprintf("%s %s %q %q", d.Kind, d.Key, d.Old, d.New))
+		return nil
+	})
+	assert.Nil(t, err, err)
+	assert.Equal(t, []string{
+		`changed b ["b1" "b2"] ["b2" "b1"]`,
+		`changed c ["c1"] ["c2"]`,
+		`removed e ["e1"] []`,
+		`added f [] ["f1"]`,
+		`changed g ["g1"] ["g1" "g2"]`,
+		`added h [] ["h1"]`,
+	}, found)
+
+	assert.False(t, s.Same())
+	assert.Equal(t, uint64(2), s.Unchanged)
+	assert.Equal(t, uint64(2), s.Added)
+	assert.Equal(t, uint64(1), s.Removed)
+	assert.Equal(t, uint64(3), s.Changed)
+	assert.Equal(t, [][]byte{[]byte("f"), []byte("h")}, s.AddedKeys)
+	assert.Equal(t, [][]byte{[]byte("e")}, s.RemovedKeys)
+	assert.Equal(t, [][]byte{[]byte("b"), []byte("c")}, s.ChangedKeys)
+
+	s, err = Diff(old, old, 2, nil)
+	assert.Nil(t, err, err)
+	assert.True(t, s.Same())
+	assert.Equal(t, uint64(6), s.Unchanged)
+
+	_, err = Diff(old, new, 0, func(d KeyDiff) error { return fmt.Errorf("stop") })
+	assert.EqualError(t, err, "stop")
+}
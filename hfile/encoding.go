@@ -0,0 +1,114 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+/*
+ByteEncoding converts keys and values between their raw bytes and text, for importing and
+exporting hfiles. NB: keys are compared as raw bytes, so negative integer keys sort after
+positive ones.
+*/
+type ByteEncoding int
+
+const (
+	// The text itself, which must be valid UTF-8 when encoding.
+	EncodingUtf8 ByteEncoding = iota
+	// Hex digits, two per byte.
+	EncodingHex
+	// Standard, padded base64.
+	EncodingBase64
+	// A decimal integer, as 4 big-endian bytes (e.g. as MockKeyInt writes keys).
+	EncodingInt32
+	// A decimal integer, as 8 big-endian bytes.
+	EncodingInt64
+)
+
+var byteEncodingNames = map[ByteEncoding]string{
+	EncodingUtf8:   "utf8",
+	EncodingHex:    "hex",
+	EncodingBase64: "base64",
+	EncodingInt32:  "int32",
+	EncodingInt64:  "int64",
+}
+
+// ParseByteEncoding returns the encoding with the given name: "utf8", "hex", "base64", "int32" or "int64".
+func ParseByteEncoding(name string) (ByteEncoding, error) {
+	for e, n := range byteEncodingNames {
+		if n == name {
+			return e, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown encoding %q", name)
+}
+
+func (e ByteEncoding) String() string {
+	if name, ok := byteEncodingNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(e))
+}
+
+// Decode returns the bytes that s represents.
+func (e ByteEncoding) Decode(s string) ([]byte, error) {
+	switch e {
+	case EncodingUtf8:
+		return []byte(s), nil
+	case EncodingHex:
+		return hex.DecodeString(s)
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(s)
+	case EncodingInt32:
+		i, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, uint32(i))
+		return buf, nil
+	case EncodingInt64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(i))
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("Unknown encoding %d", int(e))
+	}
+}
+
+// Encode returns b as text, or an error if b cannot be represented (e.g. an int32 that isn't 4 bytes).
+func (e ByteEncoding) Encode(b []byte) (string, error) {
+	switch e {
+	case EncodingUtf8:
+		if !utf8.Valid(b) {
+			return "", fmt.Errorf("%q is not valid utf8", b)
+		}
+		return string(b), nil
+	case EncodingHex:
+		return hex.EncodeToString(b), nil
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(b), nil
+	case EncodingInt32:
+		if len(b) != 4 {
+			return "", fmt.Errorf("%d bytes is not an int32", len(b))
+		}
+		return strconv.FormatInt(int64(int32(binary.BigEndian.Uint32(b))), 10), nil
+	case EncodingInt64:
+		if len(b) != 8 {
+			return "", fmt.Errorf("%d bytes is not an int64", len(b))
+		}
+		return strconv.FormatInt(int64(binary.BigEndian.Uint64(b)), 10), nil
+	default:
+		return "", fmt.Errorf("Unknown encoding %d", int(e))
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteEncodings(t *testing.T) {
+	for _, tc := range []struct {
+		encoding string
+		text     string
+		raw      []byte
+	}{
+		{"utf8", "héllo", []byte("héllo")},
+		{"hex", "00ff10", []byte{0, 0xff, 0x10}},
+		{"base64", "AP8Q", []byte{0, 0xff, 0x10}},
+		{"int32", "1", MockKeyInt(1)},
+		{"int32", "-2", []byte{0xff, 0xff, 0xff, 0xfe}},
+		{"int64", "258", []byte{0, 0, 0, 0, 0, 0, 1, 2}},
+	} {
+		e, err := ParseByteEncoding(tc.encoding)
+		assert.Nil(t, err, err)
+		assert.Equal(t, tc.encoding, e.String())
+
+		raw, err := e.Decode(tc.text)
+		assert.Nil(t, err, err)
+		assert.Equal(t, tc.raw, raw, "decoding %q as %s", tc.text, tc.encoding)
+
+		text, err := e.Encode(tc.raw)
+		assert.Nil(t, err, err)
+		assert.Equal(t, tc.text, text, "encoding %x as %s", tc.raw, tc.encoding)
+	}
+
+	_, err := ParseByteEncoding("rot13")
+	assert.NotNil(t, err)
+
+	_, err = EncodingInt32.Decode("4294967296")
+	assert.NotNil(t, err)
+	_, err = EncodingHex.Decode("0")
+	assert.NotNil(t, err)
+	_, err = EncodingInt64.Encode([]byte{1, 2, 3, 4})
+	assert.NotNil(t, err)
+	_, err = EncodingUtf8.Encode([]byte{0xff})
+	assert.NotNil(t, err)
+}
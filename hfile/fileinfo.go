@@ -0,0 +1,149 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Standard FileInfo fields, named as HBase names them. The Writer fills these in itself.
+const (
+	FileInfoLastKey     = "hfile.LASTKEY"
+	FileInfoAvgKeyLen   = "hfile.AVG_KEY_LEN"
+	FileInfoAvgValueLen = "hfile.AVG_VALUE_LEN"
+	FileInfoComparator  = "hfile.COMPARATOR"
+	FileInfoCreateTime  = "hfile.CREATE_TIME_TS"
+
+	// Keys with this prefix are reserved for the standard fields above.
+	fileInfoReservedPrefix = "hfile."
+
+	// The type id HbaseMapWritable uses for byte[] values.
+	fileInfoBytesId = 1
+)
+
+// Keys are compared as raw bytes, which is what this comparator does in HBase.
+const rawBytesComparator = "org.apache.hadoop.hbase.util.Bytes$ByteArrayComparator"
+
+type FileInfo struct {
+	InfoFields map[string]string // Human-readable fields read from the FileInfo block.
+	InfoRaw    map[string][]byte `json:"-"` // The same fields, as stored.
+}
+
+func (f *FileInfo) setInfo(key, val []byte) {
+	f.InfoFields[string(key)] = printableValue(val)
+	f.InfoRaw[string(key)] = val
+}
+
+// InfoBytes returns the value stored in FileInfo for key, if any.
+func (f *FileInfo) InfoBytes(key string) ([]byte, bool) {
+	v, ok := f.InfoRaw[key]
+	return v, ok
+}
+
+// InfoString returns the value stored in FileInfo for key as a string.
+func (f *FileInfo) InfoString(key string) (string, bool) {
+	v, ok := f.InfoRaw[key]
+	return string(v), ok
+}
+
+// InfoInt returns the value stored in FileInfo for key as a big-endian int, if it is 4 or 8 bytes long.
+func (f *FileInfo) InfoInt(key string) (int64, bool) {
+	switch v := f.InfoRaw[key]; len(v) {
+	case 4:
+		return int64(int32(binary.BigEndian.Uint32(v))), true
+	case 8:
+		return int64(binary.BigEndian.Uint64(v)), true
+	}
+	return 0, false
+}
+
+func (f *FileInfo) AvgKeyLen() (int, bool) {
+	l, ok := f.InfoInt(FileInfoAvgKeyLen)
+	return int(l), ok
+}
+
+func (f *FileInfo) AvgValueLen() (int, bool) {
+	l, ok := f.InfoInt(FileInfoAvgValueLen)
+	return int(l), ok
+}
+
+func (f *FileInfo) Comparator() (string, bool) {
+	return f.InfoString(FileInfoComparator)
+}
+
+// CreateTime returns when the file was written, if the writer recorded it.
+func (f *FileInfo) CreateTime() (time.Time, bool) {
+	ms, ok := f.InfoInt(FileInfoCreateTime)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), true
+}
+
+/*
+AppendFileInfo adds a key-value pair to the file's FileInfo block, which is written on Close.
+
+The "hfile." prefix is reserved for the standard fields that the writer computes itself.
+*/
+func (w *Writer) AppendFileInfo(key string, value []byte) error {
+	if strings.HasPrefix(key, fileInfoReservedPrefix) {
+		return errors.New("FileInfo keys with prefix " + fileInfoReservedPrefix + " are reserved")
+	}
+	w.fileInfo[key] = value
+	return nil
+}
+
+func (w *Writer) standardFileInfo() map[string][]byte {
+	info := make(map[string][]byte, len(w.fileInfo)+5)
+	for k, v := range w.fileInfo {
+		info[k] = v
+	}
+
+	avgKeyLen, avgValueLen := uint64(0), uint64(0)
+	if w.trailer.EntryCount > 0 {
+		avgKeyLen = w.totalKeyLen / uint64(w.trailer.EntryCount)
+		avgValueLen = w.totalValueLen / uint64(w.trailer.EntryCount)
+	}
+
+	info[FileInfoAvgKeyLen] = make([]byte, 4)
+	binary.BigEndian.PutUint32(info[FileInfoAvgKeyLen], uint32(avgKeyLen))
+	info[FileInfoAvgValueLen] = make([]byte, 4)
+	binary.BigEndian.PutUint32(info[FileInfoAvgValueLen], uint32(avgValueLen))
+	info[FileInfoComparator] = []byte(rawBytesComparator)
+	info[FileInfoCreateTime] = make([]byte, 8)
+	binary.BigEndian.PutUint64(info[FileInfoCreateTime], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+
+	if w.lastKey != nil {
+		info[FileInfoLastKey] = w.lastKey
+	}
+	return info
+}
+
+/*
+writeFileInfo writes FileInfo the way HBase's HbaseMapWritable does: a count, then, sorted by key,
+each key and value as vint-length-prefixed bytes, separated by a one byte type id.
+*/
+func writeFileInfo(out io.Writer, info map[string][]byte) (int, error) {
+	keys := make([]string, 0, len(info))
+	for k := range info {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint32(len(keys)))
+	for _, k := range keys {
+		writeVint(buf, len(k))
+		buf.WriteString(k)
+		buf.WriteByte(fileInfoBytesId)
+		writeVint(buf, len(info[k]))
+		buf.Write(info[k])
+	}
+	return out.Write(buf.Bytes())
+}
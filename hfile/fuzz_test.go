@@ -0,0 +1,20 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+//go:build go1.18
+// +build go1.18
+
+package hfile
+
+import "testing"
+
+// Run with `go test -run=FuzzReader -fuzz=FuzzReader ./hfile` to look for inputs that crash the reader.
+func FuzzReader(f *testing.F) {
+	for _, seed := range corruptSeeds(f) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if problem := readCorrupt(data); problem != "" {
+			t.Fatal(problem)
+		}
+	})
+}
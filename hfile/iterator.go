@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"log"
 	"math"
+	"sort"
 )
 
 type Iterator struct {
@@ -15,21 +16,29 @@ type Iterator struct {
 
 	block []byte
 	pos   int
+	cur   int // the start of the current entry in block.
+
+	// The start of each entry in the block numbered startsBlock, found when moving backwards.
+	starts      []int
+	startsBlock int
 
 	buf []byte
 
 	key   []byte
 	value []byte
 	OrderedOps
+
+	// From GetIterator, and not yet released.
+	checkedOut bool
 }
 
 func NewIterator(r *Reader) *Iterator {
 	var buf []byte
-	if r.CompressionCodec > CompressionNone {
-		buf = make([]byte, int(float64(r.TotalUncompressedDataBytes/uint64(len(r.index)))*1.5))
+	if size := r.blockBufSize(); size > 0 {
+		buf = make([]byte, size)
 	}
 
-	it := Iterator{r, 0, nil, 0, buf, nil, nil, OrderedOps{nil}}
+	it := Iterator{r, 0, nil, 0, 0, nil, 0, buf, nil, nil, OrderedOps{nil}, false}
 	return &it
 }
 
@@ -37,6 +46,8 @@ func (it *Iterator) Reset() {
 	it.dataBlockIndex = 0
 	it.block = nil
 	it.pos = 0
+	it.cur = 0
+	it.starts = nil
 	it.key = nil
 	it.value = nil
 	it.ResetState()
@@ -135,7 +146,7 @@ func (it *Iterator) Next() (bool, error) {
 		if err != nil {
 			return false, err
 		}
-		it.pos = len(DataMagic) // skip the magic bytes
+		it.pos = 0
 	}
 
 	if len(it.block)-it.pos <= 0 { // nothing left in this block to read, need a new block.
@@ -144,6 +155,8 @@ func (it *Iterator) Next() (bool, error) {
 		return it.Next()
 	}
 
+	it.cur = it.pos
+
 	// 4 bytes each for key and value lengths, so some pointer arithmatic.
 	keyLen := int(binary.BigEndian.Uint32(it.block[it.pos : it.pos+4]))
 	valLen := int(binary.BigEndian.Uint32(it.block[it.pos+4 : it.pos+8]))
@@ -152,7 +165,7 @@ func (it *Iterator) Next() (bool, error) {
 	// it.pos now sitting on the begining of the key
 	it.key = it.block[it.pos : it.pos+keyLen]
 	it.value = it.block[it.pos+keyLen : it.pos+keyLen+valLen]
-	it.pos += keyLen + valLen // move position to next kv pair.
+	it.pos = it.hfile.skipMemstoreTS(it.block, it.pos+keyLen+valLen) // move position to next kv pair.
 	return true, nil
 }
 
@@ -176,6 +189,119 @@ func (it *Iterator) Value() []byte {
 	return ret
 }
 
+/*
+  Load the kv pair before the current one into it.key/it.value, moving the iterator backwards.
+  Return true if a kv pair was loaded, or false if already at the first one, in which case the
+  iterator is left before the first pair (so Next will load it).
+
+  On a new or Reset iterator, or one that Next has run off the end of, Prev loads the last pair,
+  so calling Prev repeatedly iterates over the whole file in reverse.
+*/
+func (it *Iterator) Prev() (bool, error) {
+	it.key = nil
+	it.value = nil
+
+	if len(it.hfile.index) == 0 {
+		return false, nil
+	}
+
+	if it.block == nil { // new, or past the last block: start after the end of the last block.
+		if err := it.loadBlock(len(it.hfile.index) - 1); err != nil {
+			return false, err
+		}
+		it.cur = len(it.block)
+	}
+
+	starts := it.entryStarts()
+	i := sort.SearchInts(starts, it.cur)
+	for i == 0 { // the current pair is the first in its block, so move to the end of the previous one.
+		if it.dataBlockIndex == 0 {
+			it.cur, it.pos = 0, 0
+			return false, nil
+		}
+		if err := it.loadBlock(it.dataBlockIndex - 1); err != nil {
+			return false, err
+		}
+		starts = it.entryStarts()
+		i = len(starts)
+	}
+
+	it.loadEntry(starts[i-1])
+	return true, nil
+}
+
+/*
+  Position the iterator at the last kv pair with a key at-or-before requested, searching the whole
+  index, so unlike Seek it can move backwards. If requested has several values, it is positioned
+  on the last of them, ready for iterating backwards with Prev.
+  Returns false if all keys are after requested, leaving the iterator before the first pair.
+*/
+func (it *Iterator) SeekBefore(requested []byte) (bool, error) {
+	it.key = nil
+	it.value = nil
+	// Seek's ordering check is relative to the previous position, which no longer applies.
+	it.ResetState()
+
+	if len(it.hfile.index) == 0 {
+		return false, nil
+	}
+
+	blk := it.hfile.FindBlock(0, requested)
+	if it.hfile.Debug {
+		log.Printf("[Iterator.SeekBefore] picked block %d\n", blk)
+	}
+	if err := it.loadBlock(blk); err != nil {
+		return false, err
+	}
+
+	starts := it.entryStarts()
+	i := sort.Search(len(starts), func(i int) bool {
+		return After(it.keyAt(starts[i]), requested)
+	})
+	if i == 0 {
+		it.cur = 0
+		return it.Prev()
+	}
+
+	it.loadEntry(starts[i-1])
+	return true, nil
+}
+
+func (it *Iterator) loadBlock(i int) error {
+	block, err := it.hfile.GetBlockBuf(i, it.buf)
+	if err != nil {
+		return err
+	}
+	it.dataBlockIndex = i
+	it.block = block
+	it.pos = 0
+	return nil
+}
+
+// entryStarts returns the start of each pair in the current block, which are only found by reading forwards.
+func (it *Iterator) entryStarts() []int {
+	if it.starts == nil || it.startsBlock != it.dataBlockIndex {
+		it.starts = it.hfile.entryStarts(it.block)
+		it.startsBlock = it.dataBlockIndex
+	}
+	return it.starts
+}
+
+func (it *Iterator) keyAt(p int) []byte {
+	keyLen := int(binary.BigEndian.Uint32(it.block[p : p+4]))
+	return it.block[p+8 : p+8+keyLen]
+}
+
+// loadEntry loads the pair starting at p in the current block into it.key/it.value.
+func (it *Iterator) loadEntry(p int) {
+	keyLen := int(binary.BigEndian.Uint32(it.block[p : p+4]))
+	valLen := int(binary.BigEndian.Uint32(it.block[p+4 : p+8]))
+	it.cur = p
+	it.key = it.block[p+8 : p+8+keyLen]
+	it.value = it.block[p+8+keyLen : p+8+keyLen+valLen]
+	it.pos = it.hfile.skipMemstoreTS(it.block, p+8+keyLen+valLen)
+}
+
 func (it *Iterator) AllForPrefixes(prefixes [][]byte, limit int32, lastKey []byte) (map[string][][]byte, []byte, error) {
 	if limit <= 0 {
 		limit = math.MaxInt32
@@ -240,6 +366,10 @@ func (it *Iterator) AllForPrefixes(prefixes [][]byte, limit int32, lastKey []byt
 
 func (it *Iterator) Release() {
 	it.Reset()
+	if it.checkedOut {
+		it.checkedOut = false
+		it.hfile.checkOut(-1)
+	}
 	select {
 	case it.hfile.iteratorCache <- it:
 	default:
@@ -44,6 +44,100 @@ func TestIterator(t *testing.T) {
 	assert.Equal(t, MockValueInt(75537), i.Value())
 }
 
+func TestReverseIterator(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		f, r := fakeDataReader(t, compress, false)
+		defer os.Remove(f)
+		i := r.GetIterator()
+
+		expected := 100000
+		ok, err := i.Prev()
+		for ok {
+			expected--
+			assert.Equal(t, MockKeyInt(expected), i.Key())
+			assert.Equal(t, MockValueInt(expected), i.Value())
+			ok, err = i.Prev()
+		}
+		assert.Nil(t, err, err)
+		assert.Equal(t, 0, expected)
+
+		// Having moved back past the first key, Next should load it.
+		ok, err = i.Next()
+		assert.Nil(t, err, err)
+		assert.True(t, ok)
+		assert.Equal(t, MockKeyInt(0), i.Key())
+
+		// Prev and Next should undo each other, including across blocks.
+		i.Reset()
+		ok, err = i.Seek(MockKeyInt(40000))
+		assert.True(t, ok)
+		for k := 39999; k > 39000; k-- {
+			ok, err = i.Prev()
+			assert.Nil(t, err, err)
+			assert.True(t, ok)
+			assert.Equal(t, MockKeyInt(k), i.Key())
+		}
+		for k := 39002; k < 40500; k++ {
+			ok, err = i.Next()
+			assert.Nil(t, err, err)
+			assert.True(t, ok)
+			assert.Equal(t, MockKeyInt(k), i.Key())
+		}
+		i.Release()
+	}
+}
+
+func TestSeekBefore(t *testing.T) {
+	f, r := fakeDataReader(t, true, false)
+	defer os.Remove(f)
+	i := r.GetIterator()
+	defer i.Release()
+
+	ok, err := i.SeekBefore(MockKeyInt(65537))
+	assert.Nil(t, err, err)
+	assert.True(t, ok)
+	assert.Equal(t, MockKeyInt(65537), i.Key())
+
+	// Between two keys, it should find the earlier one, even moving backwards.
+	ok, err = i.SeekBefore(append(MockKeyInt(500), 0))
+	assert.Nil(t, err, err)
+	assert.True(t, ok)
+	assert.Equal(t, MockKeyInt(500), i.Key())
+	ok, err = i.Prev()
+	assert.True(t, ok)
+	assert.Equal(t, MockKeyInt(499), i.Key())
+
+	ok, err = i.SeekBefore(MockKeyInt(200000))
+	assert.Nil(t, err, err)
+	assert.True(t, ok)
+	assert.Equal(t, MockKeyInt(99999), i.Key())
+
+	ok, err = i.SeekBefore([]byte{0})
+	assert.Nil(t, err, err)
+	assert.False(t, ok, "no key is before the first")
+	ok, err = i.Next()
+	assert.True(t, ok)
+	assert.Equal(t, MockKeyInt(0), i.Key())
+}
+
+func TestSeekBeforeMulti(t *testing.T) {
+	f, r := fakeDataReader(t, true, true)
+	defer os.Remove(f)
+	i := r.GetIterator()
+	defer i.Release()
+
+	// A key's values are iterated backwards from the last.
+	ok, err := i.SeekBefore(MockKeyInt(501))
+	assert.Nil(t, err, err)
+	for k := 2; k >= 0; k-- {
+		assert.True(t, ok)
+		assert.Equal(t, MockKeyInt(501), i.Key())
+		assert.Equal(t, MockMultiValueInt(501, k), i.Value())
+		ok, err = i.Prev()
+	}
+	assert.Equal(t, MockKeyInt(500), i.Key())
+}
+
 func TestSinglePrefix(t *testing.T) {
 	f, r := fakeDataReader(t, true, false)
 	defer os.Remove(f)
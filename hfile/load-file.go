@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/edsrzf/mmap-go"
@@ -18,15 +19,19 @@ import (
 */
 import "C"
 
+// How many bytes offheapMalloc has allocated that have not yet been freed.
+var offheapBytes int64
+
 /*
 Allocate a []byte outside the control of the garbage collector.
 
-This memory will never be freed -- don't use this unless you are sure that is what you want.
+This memory is never garbage collected -- it must be released with offheapFree, if at all.
 
 Putting gigs and gigs of static, long-lived data on the gc's managed heap has the potential to
 throw off any heuristics which to use the total size of the heap (e.g. maintain some % free space).
 */
 func offheapMalloc(size int64) []byte {
+	atomic.AddInt64(&offheapBytes, size)
 	hdr := reflect.SliceHeader{
 		Data: uintptr(unsafe.Pointer(C.malloc(C.size_t(size)))),
 		Len:  int(size),
@@ -35,16 +40,26 @@ func offheapMalloc(size int64) []byte {
 	return *(*[]byte)(unsafe.Pointer(&hdr))
 }
 
-func loadFile(name, path string, method LoadMethod) ([]byte, error) {
+// offheapFree releases a []byte from offheapMalloc, which must not be used afterwards.
+func offheapFree(buf []byte) {
+	if cap(buf) > 0 {
+		atomic.AddInt64(&offheapBytes, -int64(cap(buf)))
+		C.free(unsafe.Pointer(&buf[:1][0]))
+	}
+}
+
+// loadFile returns the file's contents, loaded according to method, and a func to free them.
+func loadFile(name, path string, method LoadMethod) ([]byte, func() error, error) {
 	f, err := os.OpenFile(path, os.O_RDONLY, 0)
 
 	if err != nil {
-		return nil, fmt.Errorf("[Reader] Error opening file (%s): %v", path, err)
+		return nil, nil, fmt.Errorf("[Reader] Error opening file (%s): %v", path, err)
 	}
 
 	fi, err := f.Stat()
 	if err != nil {
-		return nil, err
+		f.Close()
+		return nil, nil, err
 	}
 	sizeMb := float64(fi.Size()) / (1024.0 * 1024.0)
 
@@ -54,7 +69,8 @@ func loadFile(name, path string, method LoadMethod) ([]byte, error) {
 
 		if err != nil {
 			log.Printf("[Reader.NewReader] Error mapping %s: %s\n", name, err.Error())
-			return nil, err
+			f.Close()
+			return nil, nil, err
 		}
 	}
 
@@ -67,7 +83,9 @@ func loadFile(name, path string, method LoadMethod) ([]byte, error) {
 		log.Printf("[Reader.NewReader] Locking %s (%.02fmb)...\n", name, sizeMb)
 		if err = mapped.Lock(); err != nil {
 			log.Printf("[Reader.NewReader] Error locking %s: %s\n", name, err.Error())
-			return nil, err
+			mapped.Unmap()
+			f.Close()
+			return nil, nil, err
 		}
 		log.Printf("[Reader.NewReader] Locked %s.\n", name)
 
@@ -78,11 +96,20 @@ func loadFile(name, path string, method LoadMethod) ([]byte, error) {
 		data := offheapMalloc(fi.Size())
 		if _, err := io.ReadFull(f, data); err != nil {
 			log.Printf("[Reader.NewReader] Error reading in %s: %s\n", name, err.Error())
-			return nil, err
+			offheapFree(data)
+			return nil, nil, err
 		}
 		log.Printf("[Reader.NewReader] Loaded %s.\n", name)
-		return data, nil
+		return data, func() error {
+			offheapFree(data)
+			return nil
+		}, nil
 
 	}
-	return mapped, nil
+	return mapped, func() error {
+		// Unmapping also unlocks a MemlockFile.
+		err := mapped.Unmap()
+		f.Close()
+		return err
+	}, nil
 }
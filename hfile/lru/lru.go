@@ -15,11 +15,21 @@ type LRU struct {
 	newest *node
 	oldest *node
 
+	// If non-zero, blocks are also evicted to keep their total length under maxBytes.
+	maxBytes int
+	bytes    int
+
 	sync.Mutex
 }
 
+// NewLRU returns an LRU holding at most size blocks.
 func NewLRU(size int) *LRU {
-	return &LRU{size, make(map[int]*node), nil, nil, sync.Mutex{}}
+	return &LRU{size: size, blocks: make(map[int]*node)}
+}
+
+// NewSizedLRU returns an LRU holding blocks with a total length of at most maxBytes.
+func NewSizedLRU(maxBytes int) *LRU {
+	return &LRU{maxBytes: maxBytes, blocks: make(map[int]*node)}
 }
 
 func (l *LRU) Get(i int) ([]byte, bool) {
@@ -36,17 +46,55 @@ func (l *LRU) Add(i int, v []byte) {
 	l.Lock()
 	defer l.Unlock()
 
-	for len(l.blocks) >= l.size {
-		delete(l.blocks, l.oldest.key)
-		l.oldest = l.oldest.newer
-		if l.oldest != nil {
-			l.oldest.older = nil
-		}
+	if l.maxBytes > 0 && len(v) > l.maxBytes {
+		return
+	}
+
+	if existing, ok := l.blocks[i]; ok {
+		l.remove(existing)
+	}
+
+	for len(l.blocks) > 0 && (l.size > 0 && len(l.blocks) >= l.size || l.maxBytes > 0 && l.bytes+len(v) > l.maxBytes) {
+		l.remove(l.oldest)
 	}
 
 	n := &node{i, v, nil, nil}
 	l.moveToFront(n)
 	l.blocks[i] = n
+	l.bytes += len(v)
+}
+
+// Len returns the number of blocks held.
+func (l *LRU) Len() int {
+	l.Lock()
+	defer l.Unlock()
+	return len(l.blocks)
+}
+
+// Bytes returns the total length of the blocks held.
+func (l *LRU) Bytes() int {
+	l.Lock()
+	defer l.Unlock()
+	return l.bytes
+}
+
+func (l *LRU) remove(n *node) {
+	if n == l.oldest {
+		l.oldest = n.newer
+	}
+	if n == l.newest {
+		l.newest = n.older
+	}
+	if n.older != nil {
+		n.older.newer = n.newer
+	}
+	if n.newer != nil {
+		n.newer.older = n.older
+	}
+	n.older, n.newer = nil, nil
+
+	delete(l.blocks, n.key)
+	l.bytes -= len(n.block)
 }
 
 func (l *LRU) moveToFront(n *node) {
@@ -66,6 +114,9 @@ func (l *LRU) moveToFront(n *node) {
 	}
 	n.newer = nil
 	n.older = l.newest
+	if l.newest != nil {
+		l.newest.newer = n
+	}
 	l.newest = n
 	if l.oldest == nil {
 		l.oldest = n
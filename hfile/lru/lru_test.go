@@ -40,3 +40,39 @@ func TestLRU(t *testing.T) {
 	_, ok = lru.Get(4)
 	assert.True(t, ok, "missing item 4")
 }
+
+func TestSizedLRU(t *testing.T) {
+	lru := NewSizedLRU(10)
+
+	lru.Add(1, make([]byte, 4))
+	lru.Add(2, make([]byte, 4))
+	assert.Equal(t, 8, lru.Bytes())
+
+	// Replacing a block should not leave the old one behind.
+	lru.Add(2, make([]byte, 2))
+	assert.Equal(t, 2, lru.Len())
+	assert.Equal(t, 6, lru.Bytes())
+
+	_, ok := lru.Get(1)
+	assert.True(t, ok, "missing item 1")
+
+	// 2 is now the oldest, and must go to make room.
+	lru.Add(3, make([]byte, 5))
+	_, ok = lru.Get(2)
+	assert.False(t, ok, "item 2 should have been evicted")
+	_, ok = lru.Get(1)
+	assert.True(t, ok, "missing item 1")
+	assert.Equal(t, 9, lru.Bytes())
+
+	// Blocks bigger than the whole cache are not cached.
+	lru.Add(4, make([]byte, 11))
+	_, ok = lru.Get(4)
+	assert.False(t, ok, "oversized item should not be cached")
+	assert.Equal(t, 2, lru.Len())
+
+	for i := 10; i < 100; i++ {
+		lru.Add(i, make([]byte, 3))
+		assert.True(t, lru.Bytes() <= 10, "too big: %d", lru.Bytes())
+	}
+	assert.Equal(t, 3, lru.Len())
+}
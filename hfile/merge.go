@@ -0,0 +1,135 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type MergeMode int
+
+const (
+	// Keep every value, from every input, for each key: the oldest input's values first.
+	MergeKeepAll MergeMode = iota
+	// Keep only the values from the newest input that has each key.
+	MergeKeepNewest
+	// As MergeKeepNewest, but drop keys whose newest values include the policy's Tombstone value.
+	MergeDropTombstones
+)
+
+type MergePolicy struct {
+	Mode      MergeMode
+	Tombstone []byte
+
+	// Since empty values are common, an empty Tombstone is only accepted if this is also set.
+	EmptyTombstone bool
+}
+
+// ParseMergeMode returns the mode with the given name: "all", "newest" or "tombstone".
+func ParseMergeMode(name string) (MergeMode, error) {
+	switch name {
+	case "all":
+		return MergeKeepAll, nil
+	case "newest":
+		return MergeKeepNewest, nil
+	case "tombstone":
+		return MergeDropTombstones, nil
+	default:
+		return 0, fmt.Errorf("Unknown merge mode %q", name)
+	}
+}
+
+/*
+Merge writes the pairs from readers, which must be ordered oldest to newest, to w in key order,
+resolving keys found in more than one reader by policy, and then closes w.
+*/
+func Merge(readers []*Reader, w *Writer, policy MergePolicy) error {
+	if policy.Mode == MergeDropTombstones && len(policy.Tombstone) == 0 && !policy.EmptyTombstone {
+		return fmt.Errorf("Dropping tombstones requires a tombstone value (or EmptyTombstone, to drop keys with empty values)")
+	}
+
+	err := mergeKeys(readers, func(key []byte, values [][][]byte, newest int) error {
+		return writeMerged(w, key, values, newest, policy)
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+/*
+mergeKeys iterates over readers' keys in order, calling emit once for each key with its values from
+each reader (values[i] from readers[i]), and the index of the last reader that has the key.
+*/
+func mergeKeys(readers []*Reader, emit func(key []byte, values [][][]byte, newest int) error) error {
+	its := make([]*Iterator, len(readers))
+	ok := make([]bool, len(readers))
+	for i, r := range readers {
+		its[i] = r.GetIterator()
+		defer its[i].Release()
+
+		var err error
+		if ok[i], err = its[i].Next(); err != nil {
+			return err
+		}
+	}
+
+	values := make([][][]byte, len(readers))
+	for {
+		// Find the smallest key any input is on.
+		var key []byte
+		for i, it := range its {
+			if ok[i] && (key == nil || bytes.Compare(it.key, key) < 0) {
+				key = it.key
+			}
+		}
+		if key == nil {
+			return nil
+		}
+		key = append([]byte(nil), key...)
+
+		// Then collect its values from each input that has it.
+		newest := -1
+		for i, it := range its {
+			values[i] = values[i][:0]
+			for ok[i] && bytes.Equal(it.key, key) {
+				values[i] = append(values[i], it.Value())
+				newest = i
+
+				var err error
+				if ok[i], err = it.Next(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := emit(key, values, newest); err != nil {
+			return err
+		}
+	}
+}
+
+// writeMerged writes the values for key from each input (values[i] from readers[i]), as policy chooses.
+func writeMerged(w *Writer, key []byte, values [][][]byte, newest int, policy MergePolicy) error {
+	keep := values
+	if policy.Mode != MergeKeepAll {
+		keep = values[newest : newest+1]
+	}
+	if policy.Mode == MergeDropTombstones {
+		for _, v := range values[newest] {
+			if bytes.Equal(v, policy.Tombstone) {
+				return nil
+			}
+		}
+	}
+
+	for _, input := range keep {
+		for _, v := range input {
+			if err := w.Write(key, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mergeTestReader(t *testing.T, pairs [][2]string) (string, *Reader) {
+	keys, vals := make([][]byte, len(pairs)), make([][]byte, len(pairs))
+	for i, p := range pairs {
+		keys[i], vals[i] = []byte(p[0]), []byte(p[1])
+	}
+	f, s := tempHfile(t, true, 16, keys, vals)
+	return f, s.reader
+}
+
+func TestMerge(t *testing.T) {
+	base, r1 := mergeTestReader(t, [][2]string{{"a", "a1"}, {"b", "b1"}, {"b", "b2"}, {"d", "d1"}, {"e", "e1"}})
+	defer os.Remove(base)
+	delta, r2 := mergeTestReader(t, [][2]string{{"b", "b3"}, {"c", "c1"}, {"d", "deleted"}, {"f", "f1"}})
+	defer os.Remove(delta)
+
+	for _, tc := range []struct {
+		policy   MergePolicy
+		expected [][2]string
+	}{
+		{MergePolicy{Mode: MergeKeepAll}, [][2]string{
+			{"a", "a1"}, {"b", "b1"}, {"b", "b2"}, {"b", "b3"}, {"c", "c1"}, {"d", "d1"}, {"d", "deleted"}, {"e", "e1"}, {"f", "f1"},
+		}},
+		{MergePolicy{Mode: MergeKeepNewest}, [][2]string{
+			{"a", "a1"}, {"b", "b3"}, {"c", "c1"}, {"d", "deleted"}, {"e", "e1"}, {"f", "f1"},
+		}},
+		{MergePolicy{Mode: MergeDropTombstones, Tombstone: []byte("deleted")}, [][2]string{
+			{"a", "a1"}, {"b", "b3"}, {"c", "c1"}, {"e", "e1"}, {"f", "f1"},
+		}},
+	} {
+		fp, err := ioutil.TempFile("", "mergedhfile")
+		assert.Nil(t, err, err)
+		defer os.Remove(fp.Name())
+
+		w, err := NewWriter(fp, CompressionSnappy, 16, false)
+		assert.Nil(t, err, err)
+		assert.Nil(t, Merge([]*Reader{r1, r2}, w, tc.policy))
+
+		r, err := NewReader("merged", fp.Name(), CopiedToMem, false)
+		assert.Nil(t, err, err)
+		assert.Equal(t, uint32(len(tc.expected)), r.EntryCount)
+
+		var found [][2]string
+		it := r.GetIterator()
+		ok, err := it.Next()
+		for ok {
+			found = append(found, [2]string{string(it.Key()), string(it.Value())})
+			ok, err = it.Next()
+		}
+		assert.Nil(t, err, err)
+		assert.Equal(t, tc.expected, found, "policy %v", tc.policy.Mode)
+	}
+
+	fp, err := ioutil.TempFile("", "mergedhfile")
+	assert.Nil(t, err, err)
+	defer os.Remove(fp.Name())
+	w, err := NewWriter(fp, CompressionNone, 16, false)
+	assert.Nil(t, err, err)
+	assert.NotNil(t, Merge([]*Reader{r1, r2}, w, MergePolicy{Mode: MergeDropTombstones}), "tombstone required")
+	assert.NotNil(t, Merge([]*Reader{r1, r2}, w, MergePolicy{Mode: MergeDropTombstones, Tombstone: []byte{}}),
+		"empty tombstone should need EmptyTombstone")
+}
+
+func TestMergeEmptyTombstone(t *testing.T) {
+	base, r1 := mergeTestReader(t, [][2]string{{"a", "a1"}, {"b", "b1"}, {"c", "c1"}})
+	defer os.Remove(base)
+	delta, r2 := mergeTestReader(t, [][2]string{{"b", ""}, {"c", "c2"}, {"d", ""}})
+	defer os.Remove(delta)
+
+	fp, err := ioutil.TempFile("", "mergedhfile")
+	assert.Nil(t, err, err)
+	defer os.Remove(fp.Name())
+	w, err := NewWriter(fp, CompressionNone, 16, false)
+	assert.Nil(t, err, err)
+	assert.Nil(t, Merge([]*Reader{r1, r2}, w, MergePolicy{Mode: MergeDropTombstones, EmptyTombstone: true}))
+
+	r, err := NewReader("merged", fp.Name(), CopiedToMem, false)
+	assert.Nil(t, err, err)
+	var found [][2]string
+	it := r.GetIterator()
+	ok, err := it.Next()
+	for ok {
+		found = append(found, [2]string{string(it.Key()), string(it.Value())})
+		ok, err = it.Next()
+	}
+	assert.Nil(t, err, err)
+	assert.Equal(t, [][2]string{{"a", "a1"}, {"c", "c2"}}, found)
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+/*
+Meta blocks are named, opaque blocks stored alongside the data blocks, which producers can use to
+ship sidecar data (filters, schemas, lineage, etc) inside the same file.
+
+They are written (compressed like data blocks) between the last data block and the FileInfo, and
+are found via the meta index, which follows the data index and has the same format, with each
+block's name in place of its first key. As in HBase, the meta index is sorted by name.
+*/
+
+// AppendMetaBlock adds a named meta block, which is written on Close.
+func (w *Writer) AppendMetaBlock(name string, data []byte) error {
+	if len(name) == 0 {
+		return errors.New("meta block name cannot be empty")
+	}
+	if _, exists := w.metaBlocks[name]; exists {
+		return fmt.Errorf("duplicate meta block %q", name)
+	}
+	w.metaBlocks[name] = data
+	return nil
+}
+
+func (w *Writer) metaBlockNames() []string {
+	names := make([]string, 0, len(w.metaBlocks))
+	for name := range w.metaBlocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (w *Writer) flushMetaBlocks() error {
+	for _, name := range w.metaBlockNames() {
+		if w.debug {
+			log.Printf("[Writer.flushMetaBlocks] flushing meta block %q (%db)", name, len(w.metaBlocks[name]))
+		}
+
+		buf := bytes.NewBuffer(make([]byte, 0, len(MetaMagic)+len(w.metaBlocks[name])))
+		buf.Write(MetaMagic)
+		buf.Write(w.metaBlocks[name])
+
+		block := Block{w.curOffset, uint32(buf.Len()), []byte(name)}
+		if err := w.writeBlock(buf); err != nil {
+			return err
+		}
+		w.metaIndex = append(w.metaIndex, block)
+	}
+	return nil
+}
+
+// MetaBlockNames returns the names of the file's meta blocks, in sorted order.
+func (r *Reader) MetaBlockNames() []string {
+	names := make([]string, len(r.metaIndex))
+	for i, b := range r.metaIndex {
+		names[i] = string(b.firstKeyBytes)
+	}
+	return names
+}
+
+// MetaBlock returns the contents of the named meta block, and whether it was found.
+func (r *Reader) MetaBlock(name string) ([]byte, bool, error) {
+	i := sort.Search(len(r.metaIndex), func(i int) bool {
+		return bytes.Compare(r.metaIndex[i].firstKeyBytes, []byte(name)) >= 0
+	})
+	if i == len(r.metaIndex) || string(r.metaIndex[i].firstKeyBytes) != name {
+		return nil, false, nil
+	}
+	block := r.metaIndex[i]
+
+	if r.majorVersion > 1 {
+		magic, body, _, err := r.readBlockV2(block.offset, nil)
+		if err != nil {
+			return nil, true, err
+		}
+		if !bytes.Equal(magic, MetaMagic) {
+			return nil, true, fmt.Errorf("bad meta block magic for %q", name)
+		}
+		return body, true, nil
+	}
+
+	src, err := r.dataFrom(block.offset)
+	if err != nil {
+		return nil, true, err
+	}
+	buf, err := r.decompress(src, nil, int(block.size))
+	if err != nil {
+		return nil, true, err
+	}
+	if !bytes.HasPrefix(buf, MetaMagic) {
+		return nil, true, fmt.Errorf("bad meta block magic for %q", name)
+	}
+	return buf[len(MetaMagic):], true, nil
+}
+
+// loadMetaIndex reads the meta index, which in v1 files runs from MetaIndexOffset to the trailer.
+func (r *Reader) loadMetaIndex(data []byte) error {
+	if r.MetaIndexCount == 0 {
+		return nil
+	}
+
+	var idx []byte
+	if r.majorVersion > 1 {
+		magic, body, _, err := r.readBlockV2(r.MetaIndexOffset, nil)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(magic, RootIndexMagic) {
+			return errors.New("bad meta index magic")
+		}
+		idx = body
+	} else {
+		if r.MetaIndexOffset+uint64(len(IndexMagic)) > uint64(r.Trailer.offset) {
+			return errors.New("meta index extends past trailer")
+		}
+		idx = data[r.MetaIndexOffset:r.Trailer.offset]
+		if !bytes.HasPrefix(idx, IndexMagic) {
+			return errors.New("bad meta index magic")
+		}
+		idx = idx[len(IndexMagic):]
+	}
+
+	blocks, err := readRootIndex(idx, int(r.MetaIndexCount))
+	if err != nil {
+		return err
+	}
+	r.metaIndex = blocks
+	return nil
+}
@@ -0,0 +1,281 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+type SizeDistributionKind int
+
+const (
+	// Always Min.
+	SizeFixed SizeDistributionKind = iota
+	// Equally likely to be anything from Min to Max.
+	SizeUniform
+	// Normally distributed around Mean, with standard deviation StdDev.
+	SizeNormal
+	// Log-normally distributed: Mean is the median, and StdDev the standard deviation of the size's natural log. Long-tailed, like most real values.
+	SizeLogNormal
+)
+
+/*
+A SizeDistribution picks sizes for mock keys and values. Normal and log-normal sizes are rounded,
+then clamped to at least Min and, if Max is positive, at most Max.
+*/
+type SizeDistribution struct {
+	Kind   SizeDistributionKind
+	Min    int
+	Max    int
+	Mean   float64
+	StdDev float64
+}
+
+/*
+ParseSizeDistribution parses a size distribution written as "16" (fixed), "8-32" (uniform),
+"normal:MEAN,STDDEV" or "lognormal:MEDIAN,STDDEV", where the last two may end with ":MIN-MAX", e.g.
+"lognormal:200,1.5:1-65536".
+*/
+func ParseSizeDistribution(s string) (SizeDistribution, error) {
+	parts := strings.Split(s, ":")
+	switch parts[0] {
+	case "normal", "lognormal":
+		if len(parts) < 2 || len(parts) > 3 {
+			break
+		}
+		d := SizeDistribution{Kind: SizeNormal}
+		if parts[0] == "lognormal" {
+			d.Kind = SizeLogNormal
+		}
+		params := strings.Split(parts[1], ",")
+		if len(params) != 2 {
+			break
+		}
+		var err error
+		if d.Mean, err = strconv.ParseFloat(params[0], 64); err != nil || d.Mean < 0 {
+			break
+		}
+		if d.StdDev, err = strconv.ParseFloat(params[1], 64); err != nil || d.StdDev < 0 {
+			break
+		}
+		if len(parts) == 3 {
+			if d.Min, d.Max, err = parseSizeRange(parts[2]); err != nil {
+				break
+			}
+		}
+		return d, nil
+
+	default:
+		if len(parts) != 1 {
+			break
+		}
+		if size, err := strconv.Atoi(s); err == nil && size >= 0 {
+			return SizeDistribution{SizeFixed, size, size, 0, 0}, nil
+		}
+		if min, max, err := parseSizeRange(s); err == nil {
+			return SizeDistribution{SizeUniform, min, max, 0, 0}, nil
+		}
+	}
+	return SizeDistribution{}, fmt.Errorf("Bad size distribution %q: expected N, MIN-MAX, normal:MEAN,STDDEV[:MIN-MAX] or lognormal:MEDIAN,STDDEV[:MIN-MAX]", s)
+}
+
+func parseSizeRange(s string) (int, int, error) {
+	bounds := strings.Split(s, "-")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("not a range")
+	}
+	min, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if min < 0 || max < min {
+		return 0, 0, fmt.Errorf("bad range")
+	}
+	return min, max, nil
+}
+
+func (d SizeDistribution) pick(r *rand.Rand) int {
+	var size float64
+	switch d.Kind {
+	case SizeFixed:
+		return d.Min
+	case SizeUniform:
+		return d.Min + r.Intn(d.Max-d.Min+1)
+	case SizeNormal:
+		size = r.NormFloat64()*d.StdDev + d.Mean
+	case SizeLogNormal:
+		size = math.Exp(r.NormFloat64()*d.StdDev) * d.Mean
+	}
+
+	n := int(math.Min(math.Floor(size+0.5), math.MaxInt32))
+	if n < d.Min {
+		n = d.Min
+	}
+	if d.Max > 0 && n > d.Max {
+		n = d.Max
+	}
+	return n
+}
+
+type MockKeyStyle int
+
+const (
+	// 0, 1, 2... as 4-byte big-endian ints, like MockKeyInt.
+	MockKeysSequential MockKeyStyle = iota
+	// Random alphanumerics, with lengths from KeySize.
+	MockKeysRandom
+	// One of Prefixes fixed-width prefixes, like "0042:", followed by random alphanumerics with lengths from KeySize.
+	MockKeysPrefixed
+)
+
+var mockKeyStyleNames = map[MockKeyStyle]string{
+	MockKeysSequential: "sequential",
+	MockKeysRandom:     "random",
+	MockKeysPrefixed:   "prefixed",
+}
+
+// ParseMockKeyStyle returns the key style with the given name: "sequential", "random" or "prefixed".
+func ParseMockKeyStyle(name string) (MockKeyStyle, error) {
+	for s, n := range mockKeyStyleNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown key style %q", name)
+}
+
+/*
+MockDataSpec describes synthetic data for benchmarks and load tests. The same spec, including its
+Seed, always generates the same pairs.
+
+Random and prefixed keys are drawn independently, so a short KeySize can draw the same key twice,
+in which case it gets the values of both draws.
+*/
+type MockDataSpec struct {
+	Keys     int
+	Seed     int64
+	KeyStyle MockKeyStyle
+	KeySize  SizeDistribution // Ignored for sequential keys. Random keys are at least one byte.
+
+	Prefixes    int     // For prefixed keys, how many distinct prefixes to use.
+	PrefixSkew  float64 // If more than 1, prefixes are picked with this Zipf exponent, so a few are much more common; otherwise uniformly.
+	MaxValues   int     // The most values any one key has (at least 1).
+	ValuesSkew  float64 // Zipf exponent (more than 1) for how many values each key has, when MaxValues is more than 1.
+	ValueSize   SizeDistribution
+	LegacyValue bool // Ignore ValueSize, writing values like MockValueInt and MockMultiValueInt instead.
+}
+
+const mockAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+func randomAlphanumerics(r *rand.Rand, buf []byte) {
+	for i := range buf {
+		buf[i] = mockAlphabet[r.Intn(len(mockAlphabet))]
+	}
+}
+
+/*
+WriteMockData writes the pairs the spec describes to w, then closes it. Sequential keys are
+written in order; random and prefixed keys are sorted with a SortingWriter, spilling to the
+system's temp dir.
+*/
+func WriteMockData(w *Writer, spec MockDataSpec, progress bool) error {
+	r := rand.New(rand.NewSource(spec.Seed))
+
+	if spec.Keys < 0 {
+		return fmt.Errorf("Can't generate %d keys", spec.Keys)
+	}
+	var prefixes *rand.Zipf
+	prefixWidth := 0
+	if spec.KeyStyle == MockKeysPrefixed {
+		if spec.Prefixes < 1 {
+			return fmt.Errorf("Prefixed keys need at least one prefix")
+		}
+		prefixWidth = len(strconv.Itoa(spec.Prefixes - 1))
+		if spec.PrefixSkew > 1 {
+			prefixes = rand.NewZipf(r, spec.PrefixSkew, 1, uint64(spec.Prefixes-1))
+		}
+	}
+	var valueCounts *rand.Zipf
+	if spec.MaxValues > 1 {
+		if valueCounts = rand.NewZipf(r, spec.ValuesSkew, 1, uint64(spec.MaxValues-1)); valueCounts == nil {
+			return fmt.Errorf("Values skew must be more than 1, not %v", spec.ValuesSkew)
+		}
+	}
+
+	var out interface {
+		Write(k, v []byte) error
+		Close() error
+	} = w
+	if spec.KeyStyle != MockKeysSequential {
+		s, err := NewSortingWriter(w, 256*1024*1024, "")
+		if err != nil {
+			return err
+		}
+		out = s
+	}
+
+	for i := 0; i < spec.Keys; i++ {
+		if progress && i%10000 == 0 {
+			fmt.Printf("\r %d %.02f%%", i, (float64(i)*100.0)/float64(spec.Keys))
+		}
+
+		var key []byte
+		switch spec.KeyStyle {
+		case MockKeysSequential:
+			key = MockKeyInt(i)
+		case MockKeysRandom:
+			size := spec.KeySize.pick(r)
+			if size < 1 {
+				size = 1
+			}
+			key = make([]byte, size)
+			randomAlphanumerics(r, key)
+		case MockKeysPrefixed:
+			var p int
+			if prefixes != nil {
+				p = int(prefixes.Uint64())
+			} else {
+				p = r.Intn(spec.Prefixes)
+			}
+			prefix := fmt.Sprintf("%0*d:", prefixWidth, p)
+			key = make([]byte, len(prefix)+spec.KeySize.pick(r))
+			copy(key, prefix)
+			randomAlphanumerics(r, key[len(prefix):])
+		default:
+			return fmt.Errorf("Unknown key style %d", spec.KeyStyle)
+		}
+
+		values := 1
+		if valueCounts != nil {
+			values += int(valueCounts.Uint64())
+		}
+		for k := 0; k < values; k++ {
+			var value []byte
+			switch {
+			case spec.LegacyValue && values > 1:
+				value = MockMultiValueInt(i, k)
+			case spec.LegacyValue:
+				value = MockValueInt(i)
+			default:
+				value = make([]byte, spec.ValueSize.pick(r))
+				randomAlphanumerics(r, value)
+			}
+			if err := out.Write(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if progress {
+		fmt.Println()
+	}
+	return out.Close()
+}
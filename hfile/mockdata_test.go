@@ -0,0 +1,129 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSizeDistribution(t *testing.T) {
+	for s, expected := range map[string]SizeDistribution{
+		"16":                        {SizeFixed, 16, 16, 0, 0},
+		"8-32":                      {SizeUniform, 8, 32, 0, 0},
+		"normal:24,4":               {SizeNormal, 0, 0, 24, 4},
+		"lognormal:200,1.5:1-65536": {SizeLogNormal, 1, 65536, 200, 1.5},
+	} {
+		d, err := ParseSizeDistribution(s)
+		assert.Nil(t, err, err)
+		assert.Equal(t, expected, d, s)
+	}
+
+	for _, s := range []string{"", "-1", "32-8", "normal:24", "normal:24,4:8", "lognormal:a,1", "uniform:1-2", "16:1-2"} {
+		_, err := ParseSizeDistribution(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+// writeMockTestData returns the generated pairs (the files themselves differ in their creation time) and a reader for them.
+func writeMockTestData(t *testing.T, spec MockDataSpec) ([][2]string, *Reader) {
+	fp, err := ioutil.TempFile("", "mockhfile")
+	assert.Nil(t, err, err)
+	defer os.Remove(fp.Name())
+
+	w, err := NewWriter(fp, CompressionNone, 4096, false)
+	assert.Nil(t, err, err)
+	assert.Nil(t, WriteMockData(w, spec, false))
+
+	r, err := NewReader("mock", fp.Name(), CopiedToMem, false)
+	assert.Nil(t, err, err)
+
+	var pairs [][2]string
+	assert.Nil(t, r.ScanRange(KeyRange{}, 0, func(k, v []byte) error {
+		pairs = append(pairs, [2]string{string(k), string(v)})
+		return nil
+	}))
+	return pairs, r
+}
+
+func TestWriteMockDataLegacy(t *testing.T) {
+	fp, err := ioutil.TempFile("", "mockhfile")
+	assert.Nil(t, err, err)
+	defer os.Remove(fp.Name())
+	w, err := NewWriter(fp, CompressionNone, 4096, false)
+	assert.Nil(t, err, err)
+	assert.Nil(t, WriteMockIntPairs(w, 1000, false, false))
+	r, err := NewReader("legacy", fp.Name(), CopiedToMem, false)
+	assert.Nil(t, err, err)
+
+	pairs, mock := writeMockTestData(t, MockDataSpec{Keys: 1000, LegacyValue: true})
+	assert.Equal(t, r.EntryCount, mock.EntryCount)
+	for i, p := range pairs {
+		assert.Equal(t, [2]string{string(MockKeyInt(i)), string(MockValueInt(i))}, p)
+	}
+}
+
+func TestWriteMockData(t *testing.T) {
+	spec := MockDataSpec{
+		Keys:       2000,
+		Seed:       7,
+		KeyStyle:   MockKeysPrefixed,
+		KeySize:    SizeDistribution{SizeUniform, 4, 12, 0, 0},
+		Prefixes:   20,
+		PrefixSkew: 1.5,
+		MaxValues:  5,
+		ValuesSkew: 2,
+		ValueSize:  SizeDistribution{SizeLogNormal, 1, 1000, 50, 1},
+	}
+	data, r := writeMockTestData(t, spec)
+
+	again, _ := writeMockTestData(t, spec)
+	assert.Equal(t, data, again, "same seed, different data")
+	spec.Seed = 8
+	other, _ := writeMockTestData(t, spec)
+	assert.NotEqual(t, data, other, "different seed, same data")
+
+	prefixCounts := map[string]int{}
+	valueCounts := map[int]int{}
+	var prev []byte
+	values := 0
+	keys := 0
+	err := r.ScanRange(KeyRange{}, 0, func(k, v []byte) error {
+		assert.Equal(t, byte(':'), k[2], "%q", k)
+		assert.True(t, len(k) >= 3+4 && len(k) <= 3+12, "%q", k)
+		assert.True(t, len(v) >= 1 && len(v) <= 1000, "%d", len(v))
+
+		if prev != nil && bytes.Equal(prev, k) {
+			values++
+			return nil
+		}
+		if prev != nil {
+			valueCounts[values]++
+		}
+		keys++
+		prefixCounts[string(k[:3])]++
+		prev, values = append(prev[:0], k...), 1
+		return nil
+	})
+	assert.Nil(t, err, err)
+	valueCounts[values]++
+
+	assert.Equal(t, 2000, keys)
+	assert.True(t, len(prefixCounts) <= 20)
+	assert.True(t, prefixCounts["00:"] > prefixCounts["19:"]*5, "prefixes not skewed: %v", prefixCounts)
+	assert.True(t, valueCounts[1] > valueCounts[2] && valueCounts[2] > valueCounts[5], "values not skewed: %v", valueCounts)
+	for n := range valueCounts {
+		assert.True(t, n >= 1 && n <= 5, "%d values", n)
+	}
+
+	fp, err := ioutil.TempFile("", "mockhfile")
+	assert.Nil(t, err, err)
+	defer os.Remove(fp.Name())
+	w, err := NewWriter(fp, CompressionNone, 4096, false)
+	assert.Nil(t, err, err)
+	assert.NotNil(t, WriteMockData(w, MockDataSpec{Keys: 1, MaxValues: 2, ValuesSkew: 1}, false))
+}
@@ -20,9 +20,17 @@ func (s *OrderedOps) Same(key []byte) bool {
 }
 
 func (s *OrderedOps) CheckIfKeyOutOfOrder(key []byte) error {
+	if err := s.checkKeyOrder(key); err != nil {
+		return err
+	}
+	s.lastKey = key
+	return nil
+}
+
+// checkKeyOrder is CheckIfKeyOutOfOrder without recording key as the last one.
+func (s *OrderedOps) checkKeyOrder(key []byte) error {
 	if s.lastKey != nil && bytes.Compare(s.lastKey, key) > 0 {
 		return fmt.Errorf("Keys out of order! %v > %v", s.lastKey, key)
 	}
-	s.lastKey = key
 	return nil
 }
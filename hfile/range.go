@@ -0,0 +1,52 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import "bytes"
+
+// A KeyRange selects keys from Start (inclusive) to End (exclusive) that start with Prefix. Nil fields select any key.
+type KeyRange struct {
+	Start  []byte
+	End    []byte
+	Prefix []byte
+}
+
+// first returns the smallest key that might be in the range.
+func (kr KeyRange) first() []byte {
+	if bytes.Compare(kr.Prefix, kr.Start) > 0 {
+		return kr.Prefix
+	}
+	return kr.Start
+}
+
+// pastEnd reports whether key, and so every key after it, is past the end of the range.
+func (kr KeyRange) pastEnd(key []byte) bool {
+	if kr.End != nil && bytes.Compare(key, kr.End) >= 0 {
+		return true
+	}
+	// Keys after the prefix's first key either have the prefix, or are past all keys that do.
+	return !bytes.HasPrefix(key, kr.Prefix) && bytes.Compare(key, kr.Prefix) > 0
+}
+
+/*
+ScanRange calls fn with each pair whose key is in kr, in order, stopping after limit pairs (if limit
+is positive) or when fn returns an error, which ScanRange then returns.
+
+The key and value passed to fn are only valid until it returns.
+*/
+func (r *Reader) ScanRange(kr KeyRange, limit int, fn func(key, value []byte) error) error {
+	it := r.GetIterator()
+	defer it.Release()
+
+	ok, err := it.Seek(kr.first())
+	for count := 0; ok && (limit <= 0 || count < limit); count++ {
+		if kr.pastEnd(it.key) {
+			return nil
+		}
+		if err := fn(it.key, it.value); err != nil {
+			return err
+		}
+		ok, err = it.Next()
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanRange(t *testing.T) {
+	pairs := [][2]string{
+		{"a", "1"}, {"ab", "2"}, {"abc", "3"}, {"abc", "4"}, {"abd", "5"}, {"ac", "6"}, {"b", "7"}, {"ba", "8"}, {"c", "9"},
+	}
+	keys, vals := make([][]byte, len(pairs)), make([][]byte, len(pairs))
+	for i, p := range pairs {
+		keys[i], vals[i] = []byte(p[0]), []byte(p[1])
+	}
+	f, s := tempHfile(t, true, 8, keys, vals)
+	defer os.Remove(f)
+
+	scan := func(kr KeyRange, limit int) string {
+		found := ""
+		err := s.reader.ScanRange(kr, limit, func(k, v []byte) error {
+			found += string(v)
+			return nil
+		})
+		assert.Nil(t, err, err)
+		return found
+	}
+
+	assert.Equal(t, "123456789", scan(KeyRange{}, 0))
+	assert.Equal(t, "1234", scan(KeyRange{}, 4))
+	assert.Equal(t, "3456789", scan(KeyRange{Start: []byte("abc")}, 0))
+	assert.Equal(t, "56789", scan(KeyRange{Start: []byte("abcd")}, 0))
+	assert.Equal(t, "12345", scan(KeyRange{End: []byte("ac")}, 0))
+	assert.Equal(t, "3456", scan(KeyRange{Start: []byte("abc"), End: []byte("b")}, 0))
+	assert.Equal(t, "2345", scan(KeyRange{Prefix: []byte("ab")}, 0))
+	assert.Equal(t, "1", scan(KeyRange{Prefix: []byte("a")}, 1))
+	assert.Equal(t, "5", scan(KeyRange{Start: []byte("abd"), Prefix: []byte("ab")}, 0))
+	assert.Equal(t, "234", scan(KeyRange{End: []byte("abd"), Prefix: []byte("ab")}, 0))
+	assert.Equal(t, "78", scan(KeyRange{Start: []byte("a"), Prefix: []byte("b")}, 0))
+	assert.Equal(t, "", scan(KeyRange{Prefix: []byte("bb")}, 0))
+	assert.Equal(t, "", scan(KeyRange{Start: []byte("d")}, 0))
+
+	stop := errors.New("stop")
+	count := 0
+	err := s.reader.ScanRange(KeyRange{}, 0, func(k, v []byte) error {
+		if count++; count == 3 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 3, count)
+}
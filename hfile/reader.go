@@ -11,10 +11,14 @@ import (
 	"io"
 	"log"
 	"sort"
+	"sync"
+	"time"
 
 	"unicode/utf8"
 
 	"github.com/AndreasBriese/bbloom"
+	"github.com/foursquare/fsgo/report"
+	"github.com/foursquare/quiver/hfile/lru"
 	"github.com/golang/snappy"
 )
 
@@ -30,28 +34,48 @@ type Reader struct {
 	Trailer
 	index []Block
 
+	metaIndex []Block
+
 	scannerCache  chan *Scanner
 	iteratorCache chan *Iterator
 
-	disableBloom bool
-	bloom        *bbloom.Bloom
-}
+	disableBloom     bool
+	bloom            *bbloom.Bloom
+	bloomPrecomputed bool
+
+	// Decompressed data blocks, if BlockCacheSize is set and the file is compressed.
+	blockCache *lru.LRU
+	stats      *report.Recorder
+
+	// Entries are followed by a vlong memstore timestamp (HFile v2 with KEY_VALUE_VERSION 1).
+	includesMemstoreTS bool
+
+	// How many scanners and iterators from GetScanner and GetIterator have yet to be released.
+	checkedOut     int
+	checkedOutLock sync.Mutex
 
-type FileInfo struct {
-	InfoFields map[string]string // Human-readable fields read from the FileInfo block.
+	// Frees (or unmaps) data when the reader is unloaded, if it was loaded off the gc's heap.
+	free func() error
 }
 
 type Trailer struct {
 	offset int
 
-	FileInfoOffset             uint64
-	DataIndexOffset            uint64
-	DataIndexCount             uint32
-	MetaIndexOffset            uint64
-	MetaIndexCount             uint32
-	TotalUncompressedDataBytes uint64
-	EntryCount                 uint32
-	CompressionCodec           uint32
+	FileInfoOffset             uint64 `json:"fileInfoOffset"`
+	DataIndexOffset            uint64 `json:"dataIndexOffset"`
+	DataIndexCount             uint32 `json:"dataIndexCount"`
+	MetaIndexOffset            uint64 `json:"metaIndexOffset"`
+	MetaIndexCount             uint32 `json:"metaIndexCount"`
+	TotalUncompressedDataBytes uint64 `json:"totalUncompressedDataBytes"`
+	EntryCount                 uint32 `json:"entryCount"`
+	CompressionCodec           uint32 `json:"compressionCodec"`
+
+	// Only set for HFile v2, where DataIndexOffset is the root of a possibly multi-level index.
+	UncompressedDataIndexSize uint64 `json:"uncompressedDataIndexSize,omitempty"`
+	NumDataIndexLevels        uint32 `json:"numDataIndexLevels,omitempty"`
+	FirstDataBlockOffset      uint64 `json:"firstDataBlockOffset,omitempty"`
+	LastDataBlockOffset       uint64 `json:"lastDataBlockOffset,omitempty"`
+	ComparatorClassName       string `json:"comparatorClassName,omitempty"`
 }
 
 type Block struct {
@@ -61,47 +85,70 @@ type Block struct {
 }
 
 func NewReader(name, path string, load LoadMethod, debug bool) (*Reader, error) {
-	return NewReaderFromConfig(CollectionConfig{name, path, path, nil, load, debug, name, "", "", ""})
+	return NewReaderFromConfig(CollectionConfig{name, path, path, nil, load, debug, name, "", "", "", 0})
 }
 
-func NewReaderFromConfig(cfg CollectionConfig) (*Reader, error) {
+func NewReaderFromConfig(cfg CollectionConfig) (_ *Reader, err error) {
 	hfile := new(Reader)
 	hfile.CollectionConfig = cfg
 
 	if cfg.cachedContent != nil {
 		hfile.data = *cfg.cachedContent
-	} else if data, err := loadFile(cfg.Name, cfg.LocalPath, cfg.LoadMethod); err != nil {
+		hfile.free = func() error {
+			offheapFree(hfile.data)
+			return nil
+		}
+	} else if data, free, err := loadFile(cfg.Name, cfg.LocalPath, cfg.LoadMethod); err != nil {
 		return nil, err
 	} else {
 		hfile.data = data
+		hfile.free = free
 	}
+	// Nothing else has the data yet, so if it can't be read, it must be released here.
+	defer func() {
+		if err != nil {
+			hfile.free()
+		}
+	}()
 
+	if len(hfile.data) < 4 {
+		return nil, fmt.Errorf("%s is too short (%d bytes) to be an hfile", cfg.Name, len(hfile.data))
+	}
 	v := binary.BigEndian.Uint32(hfile.data[len(hfile.data)-4:])
 	hfile.majorVersion = v & 0x00ffffff
 	hfile.minorVersion = v >> 24
 
-	err := hfile.readTrailer(hfile.data)
-	if err != nil {
+	if err = hfile.readTrailer(hfile.data); err != nil {
 		return nil, err
 	}
-
-	err = hfile.readFileInfo(hfile.data)
-	if err != nil {
+	if err = hfile.readFileInfo(hfile.data); err != nil {
+		return nil, err
+	}
+	if err = hfile.loadIndex(hfile.data); err != nil {
+		return nil, err
+	}
+	if err = hfile.loadMetaIndex(hfile.data); err != nil {
 		return nil, err
 	}
 
-	err = hfile.loadIndex(hfile.data)
-	if err != nil {
-		return hfile, err
+	// A bad precomputed bloom filter shouldn't stop us serving; one can be calculated instead.
+	if err := hfile.loadBloom(); err != nil {
+		log.Printf("[Reader.NewReader] Ignoring bloom filter in %s: %v", cfg.Name, err)
 	}
+	if cfg.BlockCacheSize > 0 && hfile.CompressionCodec != CompressionNone {
+		hfile.blockCache = lru.NewSizedLRU(cfg.BlockCacheSize)
+	}
+
 	hfile.scannerCache = make(chan *Scanner, 5)
 	hfile.iteratorCache = make(chan *Iterator, 5)
 	return hfile, nil
 }
 
 func (r *Reader) PrintDebugInfo(out io.Writer, includeStartKeys int) {
+	fmt.Fprintf(out, "version: %d.%d\n", r.majorVersion, r.minorVersion)
 	fmt.Fprintln(out, "entries: ", r.EntryCount)
 	fmt.Fprintf(out, "compressed: %v (codec: %d)\n", r.CompressionCodec != CompressionNone, r.CompressionCodec)
+	fmt.Fprintln(out, "meta blocks: ", r.MetaBlockNames())
 	fmt.Fprintln(out, "blocks: ", len(r.index))
 	for i, blk := range r.index {
 		if i > includeStartKeys {
@@ -113,10 +160,18 @@ func (r *Reader) PrintDebugInfo(out io.Writer, includeStartKeys int) {
 }
 
 func (r *Reader) readTrailer(data []byte) error {
+	if r.majorVersion == 2 {
+		return r.readTrailerV2(data)
+	}
+
 	if r.majorVersion != 1 || r.minorVersion != 0 {
 		return fmt.Errorf("wrong version: %d.%d", r.majorVersion, r.minorVersion)
 	}
 
+	if len(data) < 60 {
+		return fmt.Errorf("file too short for trailer (%d bytes)", len(data))
+	}
+
 	r.Trailer.offset = len(data) - 60
 	buf := bytes.NewReader(data[r.Trailer.offset:])
 
@@ -126,18 +181,37 @@ func (r *Reader) readTrailer(data []byte) error {
 		return errors.New("bad trailer magic")
 	}
 
-	binary.Read(buf, binary.BigEndian, &r.FileInfoOffset)
-	binary.Read(buf, binary.BigEndian, &r.DataIndexOffset)
-	binary.Read(buf, binary.BigEndian, &r.DataIndexCount)
-	binary.Read(buf, binary.BigEndian, &r.MetaIndexOffset)
-	binary.Read(buf, binary.BigEndian, &r.MetaIndexCount)
-	binary.Read(buf, binary.BigEndian, &r.TotalUncompressedDataBytes)
-	binary.Read(buf, binary.BigEndian, &r.EntryCount)
-	binary.Read(buf, binary.BigEndian, &r.CompressionCodec)
+	for _, field := range []interface{}{
+		&r.FileInfoOffset,
+		&r.DataIndexOffset,
+		&r.DataIndexCount,
+		&r.MetaIndexOffset,
+		&r.MetaIndexCount,
+		&r.TotalUncompressedDataBytes,
+		&r.EntryCount,
+		&r.CompressionCodec,
+	} {
+		if err := binary.Read(buf, binary.BigEndian, field); err != nil {
+			return fmt.Errorf("error reading trailer: %v", err)
+		}
+	}
+
+	// FileInfo, the data index and the meta index are written in that order, before the trailer.
+	end := uint64(r.Trailer.offset)
+	if r.MetaIndexOffset != 0 {
+		end = r.MetaIndexOffset
+	}
+	if r.FileInfoOffset > r.DataIndexOffset || r.DataIndexOffset > end || end > uint64(r.Trailer.offset) {
+		return fmt.Errorf("bad trailer offsets: file info %d, data index %d, meta index %d, trailer %d",
+			r.FileInfoOffset, r.DataIndexOffset, r.MetaIndexOffset, r.Trailer.offset)
+	}
 	return nil
 }
 
 func (r *Reader) loadIndex(data []byte) error {
+	if r.majorVersion > 1 {
+		return r.loadIndexV2(data)
+	}
 
 	dataIndexEnd := r.MetaIndexOffset
 	if r.MetaIndexOffset == 0 {
@@ -145,10 +219,15 @@ func (r *Reader) loadIndex(data []byte) error {
 	}
 
 	i := r.DataIndexOffset
+	data = data[:dataIndexEnd]
 
+	// Each entry takes at least 13 bytes, so a count larger than that allows is surely corrupt.
+	if uint64(r.DataIndexCount) > (dataIndexEnd-i)/13 {
+		return fmt.Errorf("data index count %d is too large for the index", r.DataIndexCount)
+	}
 	r.index = make([]Block, 0, r.DataIndexCount)
 
-	if bytes.Compare(data[i:i+8], IndexMagic) != 0 {
+	if !bytes.HasPrefix(data[i:], IndexMagic) {
 		return errors.New("bad data index magic")
 	}
 	i += 8
@@ -156,6 +235,10 @@ func (r *Reader) loadIndex(data []byte) error {
 	for i < dataIndexEnd {
 		dataBlock := Block{}
 
+		if dataIndexEnd-i < 12 {
+			return fmt.Errorf("data index truncated after %d entries", len(r.index))
+		}
+
 		dataBlock.offset = binary.BigEndian.Uint64(data[i:])
 		i += uint64(binary.Size(dataBlock.offset))
 
@@ -163,7 +246,7 @@ func (r *Reader) loadIndex(data []byte) error {
 		i += uint64(binary.Size(dataBlock.size))
 
 		firstKeyLen, s := vintAndLen(data[i:])
-		if s < 1 || firstKeyLen < 1 {
+		if s < 1 || firstKeyLen < 1 || uint64(firstKeyLen) > dataIndexEnd-i-uint64(s) {
 			return fmt.Errorf("Failed to read key length, err %d", s)
 		}
 		i += uint64(s)
@@ -185,11 +268,71 @@ func (b *Block) IsAfter(key []byte) bool {
 	return After(b.firstKeyBytes, key)
 }
 
+// FirstKey returns a copy of the first key in the file.
 func (r *Reader) FirstKey() ([]byte, error) {
 	if len(r.index) < 1 {
 		return nil, fmt.Errorf("empty collection has no first key")
 	}
-	return r.index[0].firstKeyBytes, nil
+	r.checkOut(1)
+	defer r.checkOut(-1)
+	return append([]byte(nil), r.index[0].firstKeyBytes...), nil
+}
+
+/*
+LastKey returns a copy of the last key in the file, as recorded in its FileInfo by the writer, or,
+for files without one, by reading the last entry of the final block.
+*/
+func (r *Reader) LastKey() ([]byte, error) {
+	if len(r.index) < 1 {
+		return nil, fmt.Errorf("empty collection has no last key")
+	}
+	r.checkOut(1)
+	defer r.checkOut(-1)
+
+	if k, ok := r.InfoBytes(FileInfoLastKey); ok && len(k) > 0 {
+		return append([]byte(nil), k...), nil
+	}
+
+	block, err := r.GetBlockBuf(len(r.index)-1, nil)
+	if err != nil {
+		return nil, err
+	}
+	keys := r.blockKeys(block)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("last block is empty")
+	}
+	return append([]byte(nil), keys[len(keys)-1]...), nil
+}
+
+// NumBlocks returns the number of data blocks in the file.
+func (r *Reader) NumBlocks() int {
+	return len(r.index)
+}
+
+/*
+CompressedDataBytes returns how many bytes the data blocks take up in the file, as stored (i.e.
+compressed, if the file is), including any index blocks written inline with them in HFile v2.
+*/
+func (r *Reader) CompressedDataBytes() uint64 {
+	if len(r.index) < 1 {
+		return 0
+	}
+	return r.dataEnd() - r.index[0].offset
+}
+
+// dataEnd returns the offset of the end of the data blocks.
+func (r *Reader) dataEnd() uint64 {
+	// Meta blocks, FileInfo and the index all follow the data blocks, in an order that varies by version.
+	end := r.FileInfoOffset
+	if r.DataIndexOffset < end {
+		end = r.DataIndexOffset
+	}
+	for _, b := range r.metaIndex {
+		if b.offset < end {
+			end = b.offset
+		}
+	}
+	return end
 }
 
 func (r *Reader) FindBlock(from int, key []byte) int {
@@ -219,74 +362,220 @@ func (r *Reader) FindBlock(from int, key []byte) int {
 	return from + offset
 }
 
+/*
+GetBlockBuf returns the entries of the i-th data block (i.e. without the block's magic or header),
+decompressing into dst if it is large enough.
+
+If the reader has a block cache, blocks are instead decompressed into new buffers that are then
+shared via the cache, so the returned entries must not be modified.
+*/
 func (r *Reader) GetBlockBuf(i int, dst []byte) ([]byte, error) {
-	block := r.index[i]
+	cache := r.blockCache
+	if cache == nil {
+		return r.readDataBlock(i, dst)
+	}
 
-	switch r.CompressionCodec {
-	case CompressionNone:
-		dst = r.data[block.offset : block.offset+uint64(block.size)]
-	case CompressionSnappy:
-		/*
-		   SnappyCompressor internally uses BlockCompressorStream, which writes "blocks" of compressed data.
+	if buf, ok := cache.Get(i); ok {
+		r.countCacheLookup(blockCacheHitStat)
+		return buf, nil
+	}
+	r.countCacheLookup(blockCacheMissStat)
+
+	buf, err := r.readDataBlock(i, nil)
+	if err != nil {
+		return nil, err
+	}
+	cache.Add(i, buf)
+	return buf, nil
+}
+
+func (r *Reader) countCacheLookup(stat string) {
+	if r.stats != nil {
+		r.stats.Inc(stat)
+	}
+}
+
+/*
+readDataBlock reads the i-th data block, checking that its entries are all within it, so that
+scanners and iterators can then read them without checking every length against the block.
+*/
+func (r *Reader) readDataBlock(i int, dst []byte) ([]byte, error) {
+	var block []byte
+	var err error
+	if r.majorVersion > 1 {
+		block, err = r.getBlockBufV2(i, dst)
+	} else {
+		block, err = r.readDataBlockV1(i, dst)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		   These are NOT the "blocks" in the hfile sense. A "block" of an hfile may, when written by BlockCompressorStream
-		   write out many of what it calls "blocks". To avoid confusion, in this code, we shall call these "subblocks".
+	if err := r.checkEntries(block); err != nil {
+		return nil, fmt.Errorf("bad data block %d: %v", i, err)
+	}
+	return block, nil
+}
 
-		   Unfortunately the confusing usages of "block" do not stop there.
+func (r *Reader) readDataBlockV1(i int, dst []byte) ([]byte, error) {
+	block := r.index[i]
 
-		   BlockCompressorStream described the "blocks" (subblocks) that it writes thus[1]:
-		   "Each block contains the uncompressed length for the block, followed by one or more length-prefixed *blocks* of compressed data." (emphasis mine)
+	src, err := r.dataFrom(block.offset)
+	if err != nil {
+		return nil, err
+	}
+	dst, err = r.decompress(src, dst, int(block.size))
+	if err != nil {
+		return nil, err
+	}
 
-		   Yes, that is a third, distinct, "block".
+	if len(dst) < len(DataMagic) || bytes.Compare(dst[0:8], DataMagic) != 0 {
+		return nil, errors.New("bad data block magic")
+	}
 
-		   In the hope that we might, with luck, navigate this correctly, we'll refer to these as "chunks" of "subblocks".
+	return dst[len(DataMagic):], nil
+}
 
-		   Thus, reading a "block" of an hfile we read its "subblocks" in a loop, inside of which we, in a nested loop, read the subblock's chunks.
+// Scanners' and iterators' buffers grow to fit larger blocks, so there's no need to trust a corrupt trailer's total size.
+const maxBlockBufSize = 16 * 1024 * 1024
 
-		   1: http://grepcode.com/file/repo1.maven.org/maven2/org.apache.hadoop/hadoop-common/0.22.0/org/apache/hadoop/io/compress/BlockCompressorStream.java?av=f
-		*/
+// blockBufSize returns the size of buffer that scanners and iterators should decompress blocks into.
+func (r *Reader) blockBufSize() int {
+	if r.CompressionCodec <= CompressionNone || len(r.index) == 0 {
+		return 0
+	}
+	size := float64(r.TotalUncompressedDataBytes/uint64(len(r.index))) * 1.5
+	if size > maxBlockBufSize {
+		return maxBlockBufSize
+	}
+	return int(size)
+}
 
-		// If our pre-allocated buffer too small, alloc replacement up front, to make sure Decode doesn't.
-		if len(dst) < int(block.size) {
-			dst = make([]byte, block.size)
-		}
+// dataFrom returns the file's contents from offset on.
+func (r *Reader) dataFrom(offset uint64) ([]byte, error) {
+	if offset > uint64(len(r.data)) {
+		return nil, fmt.Errorf("block at %d is past end of file (%d bytes)", offset, len(r.data))
+	}
+	return r.data[offset:], nil
+}
 
-		p := int(block.offset)
-		decompressed := 0
+// checkEntries checks that each entry in a data block, and its memstore timestamp if any, is complete.
+func (r *Reader) checkEntries(block []byte) error {
+	for i := 0; i < len(block); {
+		if len(block)-i < 8 {
+			return fmt.Errorf("%d trailing bytes at %d", len(block)-i, i)
+		}
+		keyLen := uint64(binary.BigEndian.Uint32(block[i : i+4]))
+		valLen := uint64(binary.BigEndian.Uint32(block[i+4 : i+8]))
+		if keyLen+valLen > uint64(len(block)-i-8) {
+			return fmt.Errorf("entry at %d (%d byte key, %d byte value) extends past end of block", i, keyLen, valLen)
+		}
+		end := i + 8 + int(keyLen+valLen)
 
-		for decompressed < int(block.size) {
-			subblockSize := binary.BigEndian.Uint32(r.data[p : p+4])
-			subblockRead := uint32(0)
-			p += 4
-			for subblockRead < subblockSize {
-				chunkSz := int(binary.BigEndian.Uint32(r.data[p : p+4]))
-				p += 4
-				target := dst[decompressed:]
-				if ret, err := snappy.Decode(target, r.data[p:p+chunkSz]); err != nil {
-					return nil, err
-				} else {
-					decompressed += len(ret)
-					subblockRead += uint32(len(ret))
-					_ = ret // TODO(davidt): check if Decode alloc'ed its own []byte
-				}
-				p += chunkSz
+		if r.includesMemstoreTS && end < len(block) {
+			_, n := vintAndLen(block[end:])
+			if n < 1 {
+				return fmt.Errorf("truncated memstore timestamp at %d", end)
 			}
+			end += n
 		}
-		dst = dst[:decompressed]
+		i = end
+	}
+	return nil
+}
 
+// decompress reads size uncompressed bytes from the start of src using the file's codec.
+func (r *Reader) decompress(src, dst []byte, size int) ([]byte, error) {
+	switch r.CompressionCodec {
+	case CompressionNone:
+		if len(src) < size {
+			return nil, fmt.Errorf("block extends past end of file (%d > %d)", size, len(src))
+		}
+		return src[:size], nil
+	case CompressionSnappy:
+		return decodeBlockStream(src, dst, size, snappy.Decode)
+	case CompressionLz4:
+		return decodeBlockStream(src, dst, size, decodeLz4)
+	case CompressionGz:
+		return decodeGzip(src, dst, size)
+	case CompressionZstd:
+		return decodeZstd(src, dst, size)
 	default:
-		return nil, errors.New("Unsupported compression codec " + string(r.CompressionCodec))
+		return nil, fmt.Errorf("Unsupported compression codec %d", r.CompressionCodec)
 	}
+}
 
-	if bytes.Compare(dst[0:8], DataMagic) != 0 {
-		return nil, errors.New("bad data block magic")
+var errTruncatedBlockStream = errors.New("compressed block is truncated")
+
+// decodeBlockStream reads size bytes of Hadoop BlockCompressorStream output, as used by the snappy and LZ4 codecs.
+func decodeBlockStream(src, dst []byte, size int, decode func(dst, src []byte) ([]byte, error)) ([]byte, error) {
+	/*
+	   SnappyCompressor (and Lz4Compressor) internally uses BlockCompressorStream, which writes "blocks" of compressed data.
+
+	   These are NOT the "blocks" in the hfile sense. A "block" of an hfile may, when written by BlockCompressorStream
+	   write out many of what it calls "blocks". To avoid confusion, in this code, we shall call these "subblocks".
+
+	   Unfortunately the confusing usages of "block" do not stop there.
+
+	   BlockCompressorStream described the "blocks" (subblocks) that it writes thus[1]:
+	   "Each block contains the uncompressed length for the block, followed by one or more length-prefixed *blocks* of compressed data." (emphasis mine)
+
+	   Yes, that is a third, distinct, "block".
+
+	   In the hope that we might, with luck, navigate this correctly, we'll refer to these as "chunks" of "subblocks".
+
+	   Thus, reading a "block" of an hfile we read its "subblocks" in a loop, inside of which we, in a nested loop, read the subblock's chunks.
+
+	   1: http://grepcode.com/file/repo1.maven.org/maven2/org.apache.hadoop/hadoop-common/0.22.0/org/apache/hadoop/io/compress/BlockCompressorStream.java?av=f
+	*/
+
+	if size/maxBlockStreamRatio > len(src) {
+		return nil, fmt.Errorf("compressed block is too short to decompress to %d bytes", size)
+	}
+
+	// If our pre-allocated buffer too small, alloc replacement up front, to make sure Decode doesn't.
+	if len(dst) < size {
+		dst = make([]byte, size)
 	}
 
-	return dst, nil
+	p := 0
+	decompressed := 0
+
+	for decompressed < size {
+		if len(src)-p < 4 {
+			return nil, errTruncatedBlockStream
+		}
+		subblockSize := binary.BigEndian.Uint32(src[p : p+4])
+		subblockRead := uint32(0)
+		p += 4
+		for subblockRead < subblockSize {
+			if len(src)-p < 4 {
+				return nil, errTruncatedBlockStream
+			}
+			chunkSz := int(binary.BigEndian.Uint32(src[p : p+4]))
+			p += 4
+			if chunkSz > len(src)-p {
+				return nil, errTruncatedBlockStream
+			}
+			target := dst[decompressed:size]
+			if ret, err := decode(target, src[p:p+chunkSz]); err != nil {
+				return nil, err
+			} else if len(ret) > len(target) {
+				// Decode only allocates its own []byte if the chunk is larger than the rest of the block.
+				return nil, fmt.Errorf("compressed block is larger than its expected %d bytes", size)
+			} else {
+				decompressed += len(ret)
+				subblockRead += uint32(len(ret))
+			}
+			p += chunkSz
+		}
+	}
+	return dst[:decompressed], nil
 }
 
 func (r *Reader) CalculateBloom(falsePosRate float64) error {
-	i := NewIterator(r)
+	i := r.GetIterator()
+	defer i.Release()
 	bloom := bbloom.New(float64(r.Trailer.EntryCount), falsePosRate)
 	ok, err := i.Next()
 	for ok && err == nil {
@@ -294,6 +583,7 @@ func (r *Reader) CalculateBloom(falsePosRate float64) error {
 		ok, err = i.Next()
 	}
 	r.bloom = &bloom
+	r.bloomPrecomputed = false
 	return err
 }
 
@@ -306,25 +596,79 @@ func (r *Reader) EnableBloom() {
 }
 
 func (r *Reader) MightContain(key []byte) bool {
-	return r.bloom == nil || r.disableBloom || r.bloom.Has(key)
+	// CalculateBloom may replace the filter at any time.
+	bloom := r.bloom
+	return bloom == nil || r.disableBloom || bloom.Has(key)
 }
 
 func (r *Reader) GetScanner() *Scanner {
+	var s *Scanner
 	select {
-	case s := <-r.scannerCache:
-		return s
+	case s = <-r.scannerCache:
 	default:
-		return NewScanner(r)
+		s = NewScanner(r)
 	}
+	r.checkOut(1)
+	s.checkedOut = true
+	return s
 }
 
 func (r *Reader) GetIterator() *Iterator {
+	var it *Iterator
 	select {
-	case i := <-r.iteratorCache:
-		return i
+	case it = <-r.iteratorCache:
 	default:
-		return NewIterator(r)
+		it = NewIterator(r)
 	}
+	r.checkOut(1)
+	it.checkedOut = true
+	return it
+}
+
+// checkOut counts scanners and iterators being handed out (or, if n is negative, released).
+func (r *Reader) checkOut(n int) {
+	r.checkedOutLock.Lock()
+	r.checkedOut += n
+	r.checkedOutLock.Unlock()
+}
+
+func (r *Reader) inUse() bool {
+	r.checkedOutLock.Lock()
+	defer r.checkedOutLock.Unlock()
+	return r.checkedOut > 0
+}
+
+// How often Unload checks whether the reader is still in use.
+const unloadPollInterval = 100 * time.Millisecond
+
+/*
+Unload waits until every scanner and iterator from GetScanner and GetIterator has been released
+(and any CalculateBloom, SampleKeys or LastKey call has returned), and none have been checked out
+for the grace period, then frees the reader's data. The grace
+period covers callers still holding keys or values they read, which point into that data. The
+reader must not be used once Unload has been called.
+*/
+func (r *Reader) Unload(grace time.Duration) error {
+	idleSince := time.Now()
+	for {
+		if r.inUse() {
+			idleSince = time.Now()
+		} else if time.Since(idleSince) >= grace {
+			break
+		}
+		time.Sleep(unloadPollInterval)
+	}
+
+	if r.Debug {
+		log.Printf("[Reader.Unload] Unloading %s.\n", r.Name)
+	}
+	// The block cache and bloom filter are on the gc's heap, so are freed along with the reader itself.
+	if r.free == nil {
+		return nil
+	}
+	free := r.free
+	r.free = nil
+	return free()
 }
 
 // Grab a variable-length sequence of bytes from the buffer.
@@ -334,6 +678,9 @@ func varLenBytes(buf *bytes.Reader) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if seqLen < 0 || seqLen > buf.Len() {
+		return nil, fmt.Errorf("Bad sequence length %d, with %d bytes left.", seqLen, buf.Len())
+	}
 	// Read the sequence itself.
 	seq := make([]byte, seqLen)
 	n, err := buf.Read(seq)
@@ -359,19 +706,40 @@ func printableValue(buf []byte) string {
 	}
 }
 
-// TODO(benjy): Write tests for this, once the writer supports writing the FileInfo block.
 func (r *Reader) readFileInfo(data []byte) (err error) {
 	r.FileInfo.InfoFields = make(map[string]string)
+	r.FileInfo.InfoRaw = make(map[string][]byte)
 
-	if r.FileInfoOffset == r.DataIndexOffset {
+	var raw []byte
+	if r.majorVersion > 1 {
+		if raw, err = r.readFileInfoBlockV2(); err != nil {
+			return err
+		}
+	} else if r.FileInfoOffset == r.DataIndexOffset {
 		log.Println("[Reader.readFileInfo] No FileInfo block found. Skipping.")
 		return nil
+	} else {
+		raw = data[r.FileInfoOffset:r.DataIndexOffset]
 	}
 
-	buf := bytes.NewReader(data[r.FileInfoOffset:r.DataIndexOffset])
+	if bytes.HasPrefix(raw, pbMagic) {
+		err = r.readFileInfoPB(raw[len(pbMagic):])
+	} else {
+		err = r.readFileInfoWritable(raw)
+	}
+
+	kvVersion, _ := r.InfoInt("KEY_VALUE_VERSION")
+	r.includesMemstoreTS = r.majorVersion > 1 && kvVersion == 1
+	return err
+}
+
+func (r *Reader) readFileInfoWritable(raw []byte) error {
+	buf := bytes.NewReader(raw)
 
 	var entryCount uint32
-	binary.Read(buf, binary.BigEndian, &entryCount)
+	if err := binary.Read(buf, binary.BigEndian, &entryCount); err != nil {
+		return fmt.Errorf("error reading file info: %v", err)
+	}
 
 	for i := uint32(0); i < entryCount; i++ {
 		key, err := varLenBytes(buf)
@@ -383,7 +751,7 @@ func (r *Reader) readFileInfo(data []byte) (err error) {
 		if err != nil {
 			return err
 		}
-		r.FileInfo.InfoFields[string(key)] = printableValue(val)
+		r.setInfo(key, val)
 	}
 	return nil
 }
@@ -0,0 +1,432 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+/*
+HFile v2, as written by HBase 0.92 and later, differs from v1 in a few ways that matter to us:
+
+- every block (data, index, file info, ...) starts with a header carrying its magic and sizes, and
+  only the part after the header is compressed. From minor version 1 on, the header also describes
+  checksums, which follow the (compressed) data on disk.
+- the data index may have several levels: a root index block in the "load-on-open" section at the
+  end of the file points to intermediate or leaf index blocks, which are written inline between the
+  data blocks. We flatten all levels into r.index when opening, so Scanner and Iterator work as
+  they do for v1.
+- the trailer is larger, and from minor version 2 on, is a protobuf message (as is the FileInfo).
+
+Data block entries are laid out as in v1, except that writers which include memstore timestamps
+append a vlong to each entry.
+*/
+
+const (
+	trailerSizeV2 = 212
+
+	maxMinorVersionV2 = 3
+
+	minorVersionWithChecksum = 1
+	minorVersionPBTrailer    = 2
+
+	blockHeaderSizeNoChecksum   = 24
+	blockHeaderSizeWithChecksum = 33
+
+	// Each non-root index entry is an 8 byte offset and 4 byte size, followed by the key.
+	nonRootIndexEntryOverhead = 12
+
+	// Far more than a file's data could need, given the index's fanout, but it stops loops in corrupt indexes.
+	maxDataIndexLevels = 16
+
+	comparatorNameSize = 128
+)
+
+var pbMagic = []byte("PBUF")
+
+func (r *Reader) readTrailerV2(data []byte) error {
+	if r.minorVersion > maxMinorVersionV2 {
+		return fmt.Errorf("unsupported version: %d.%d", r.majorVersion, r.minorVersion)
+	}
+
+	if len(data) < trailerSizeV2 {
+		return fmt.Errorf("file too short for v2 trailer (%d bytes)", len(data))
+	}
+
+	r.Trailer.offset = len(data) - trailerSizeV2
+	trailer := data[r.Trailer.offset : len(data)-4]
+
+	if !bytes.Equal(trailer[:len(TrailerMagic)], TrailerMagic) {
+		return errors.New("bad trailer magic")
+	}
+	trailer = trailer[len(TrailerMagic):]
+
+	if r.minorVersion >= minorVersionPBTrailer {
+		return r.readTrailerPB(trailer)
+	}
+
+	var entryCount uint64
+	comparator := make([]byte, comparatorNameSize)
+
+	buf := bytes.NewReader(trailer)
+	for _, field := range []interface{}{
+		&r.FileInfoOffset,
+		&r.DataIndexOffset, // aka the load-on-open offset, where the root data index is.
+		&r.DataIndexCount,
+		&r.UncompressedDataIndexSize,
+		&r.MetaIndexCount,
+		&r.TotalUncompressedDataBytes,
+		&entryCount,
+		&r.CompressionCodec,
+		&r.NumDataIndexLevels,
+		&r.FirstDataBlockOffset,
+		&r.LastDataBlockOffset,
+		comparator,
+	} {
+		if err := binary.Read(buf, binary.BigEndian, field); err != nil {
+			return fmt.Errorf("error reading trailer: %v", err)
+		}
+	}
+	r.ComparatorClassName = string(bytes.TrimRight(comparator, "\x00"))
+
+	return r.setEntryCount(entryCount)
+}
+
+func (r *Reader) readTrailerPB(trailer []byte) error {
+	msg, err := pbDelimited(trailer)
+	if err != nil {
+		return fmt.Errorf("error reading trailer: %v", err)
+	}
+
+	var entryCount uint64
+	err = pbMessage(msg, func(field int, v uint64, b []byte) error {
+		switch field {
+		case 1:
+			r.FileInfoOffset = v
+		case 2:
+			r.DataIndexOffset = v
+		case 3:
+			r.UncompressedDataIndexSize = v
+		case 4:
+			r.TotalUncompressedDataBytes = v
+		case 5:
+			r.DataIndexCount = uint32(v)
+		case 6:
+			r.MetaIndexCount = uint32(v)
+		case 7:
+			entryCount = v
+		case 8:
+			r.NumDataIndexLevels = uint32(v)
+		case 9:
+			r.FirstDataBlockOffset = v
+		case 10:
+			r.LastDataBlockOffset = v
+		case 11:
+			r.ComparatorClassName = string(b)
+		case 12:
+			r.CompressionCodec = uint32(v)
+		case 13:
+			return errors.New("encrypted hfiles are not supported")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error reading trailer: %v", err)
+	}
+
+	return r.setEntryCount(entryCount)
+}
+
+func (r *Reader) setEntryCount(count uint64) error {
+	if count > math.MaxUint32 {
+		return fmt.Errorf("too many entries: %d", count)
+	}
+	r.EntryCount = uint32(count)
+	return nil
+}
+
+func (r *Reader) blockHeaderSizeV2() uint64 {
+	if r.minorVersion >= minorVersionWithChecksum {
+		return blockHeaderSizeWithChecksum
+	}
+	return blockHeaderSizeNoChecksum
+}
+
+// readBlockV2 reads the block at offset, returning its magic, its decompressed contents and its
+// total size on disk (including header and checksums).
+func (r *Reader) readBlockV2(offset uint64, dst []byte) ([]byte, []byte, uint64, error) {
+	headerSize := r.blockHeaderSizeV2()
+	if offset > uint64(len(r.data)) || uint64(len(r.data))-offset < headerSize {
+		return nil, nil, 0, fmt.Errorf("block at %d extends past end of file", offset)
+	}
+	header := r.data[offset : offset+headerSize]
+
+	magic := header[0:8]
+	onDiskSize := headerSize + uint64(binary.BigEndian.Uint32(header[8:12]))
+	uncompressedSize := binary.BigEndian.Uint32(header[12:16])
+
+	// Checksums, if any, follow the data, and are not included in onDiskDataSizeWithHeader.
+	dataEnd := onDiskSize
+	if headerSize == blockHeaderSizeWithChecksum {
+		dataEnd = uint64(binary.BigEndian.Uint32(header[29:33]))
+	}
+
+	if dataEnd < headerSize || dataEnd > onDiskSize || offset+onDiskSize > uint64(len(r.data)) {
+		return nil, nil, 0, fmt.Errorf("block at %d has bad size %d", offset, onDiskSize)
+	}
+
+	body, err := r.decompress(r.data[offset+headerSize:offset+dataEnd], dst, int(uncompressedSize))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return magic, body, onDiskSize, nil
+}
+
+func (r *Reader) getBlockBufV2(i int, dst []byte) ([]byte, error) {
+	magic, body, _, err := r.readBlockV2(r.index[i].offset, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, DataMagic) {
+		if bytes.Equal(magic, EncodedDataMagic) {
+			return nil, errors.New("encoded data blocks are not supported")
+		}
+		return nil, errors.New("bad data block magic")
+	}
+	return body, nil
+}
+
+func (r *Reader) readFileInfoBlockV2() ([]byte, error) {
+	magic, body, _, err := r.readBlockV2(r.FileInfoOffset, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, FileInfoMagic) {
+		return nil, errors.New("bad file info magic")
+	}
+	return body, nil
+}
+
+func (r *Reader) readFileInfoPB(raw []byte) error {
+	msg, err := pbDelimited(raw)
+	if err != nil {
+		return err
+	}
+
+	return pbMessage(msg, func(field int, _ uint64, entry []byte) error {
+		if field != 1 {
+			return nil
+		}
+		var key, val []byte
+		err := pbMessage(entry, func(field int, _ uint64, b []byte) error {
+			switch field {
+			case 1:
+				key = b
+			case 2:
+				val = b
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		r.setInfo(key, val)
+		return nil
+	})
+}
+
+/*
+loadIndexV2 reads the root data index and, for multi-level indexes, walks down through the
+intermediate and leaf index blocks to find every data block.
+
+The meta index root block is written immediately after the root data index, so we note where.
+*/
+func (r *Reader) loadIndexV2(data []byte) error {
+	if r.NumDataIndexLevels > maxDataIndexLevels {
+		return fmt.Errorf("too many data index levels: %d", r.NumDataIndexLevels)
+	}
+
+	magic, root, rootSize, err := r.readBlockV2(r.DataIndexOffset, nil)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(magic, RootIndexMagic) {
+		return errors.New("bad data index magic")
+	}
+	r.MetaIndexOffset = r.DataIndexOffset + rootSize
+
+	blocks, err := readRootIndex(root, int(r.DataIndexCount))
+	if err != nil {
+		return err
+	}
+
+	for level := uint32(1); level < r.NumDataIndexLevels; level++ {
+		expected := IntermediateIndexMagic
+		if level == r.NumDataIndexLevels-1 {
+			expected = LeafIndexMagic
+		}
+
+		var next []Block
+		for _, b := range blocks {
+			magic, idx, _, err := r.readBlockV2(b.offset, nil)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(magic, expected) {
+				return fmt.Errorf("bad index block magic at level %d: %q", level, magic)
+			}
+
+			children, err := readNonRootIndex(idx)
+			if err != nil {
+				return err
+			}
+			next = append(next, children...)
+
+			// Every block takes at least a header's worth of the file, so more would mean the index loops.
+			if uint64(len(next)) > uint64(len(r.data))/r.blockHeaderSizeV2() {
+				return fmt.Errorf("too many index entries at level %d", level)
+			}
+		}
+		blocks = next
+	}
+
+	r.index = blocks
+	return nil
+}
+
+// The root index is a sequence of offset, size and vint-length-prefixed key.
+func readRootIndex(buf []byte, count int) ([]Block, error) {
+	if count > len(buf)/(nonRootIndexEntryOverhead+1) {
+		return nil, fmt.Errorf("root index too short for %d entries", count)
+	}
+	blocks := make([]Block, 0, count)
+	i := 0
+
+	for len(blocks) < count {
+		if len(buf)-i < nonRootIndexEntryOverhead+1 {
+			return nil, fmt.Errorf("root index truncated after %d of %d entries", len(blocks), count)
+		}
+
+		b := Block{}
+		b.offset = binary.BigEndian.Uint64(buf[i:])
+		b.size = binary.BigEndian.Uint32(buf[i+8:])
+		i += nonRootIndexEntryOverhead
+
+		keyLen, s := vintAndLen(buf[i:])
+		if s < 1 || keyLen < 0 || keyLen > len(buf)-i-s {
+			return nil, fmt.Errorf("Failed to read key length, err %d", s)
+		}
+		i += s
+
+		b.firstKeyBytes = buf[i : i+keyLen]
+		i += keyLen
+
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+/*
+Non-root index blocks start with the number of entries and then a "secondary index" of the
+entries' positions (plus one extra, for the end of the last one), relative to the first entry.
+Since keys are not length-prefixed, their lengths come from the secondary index.
+*/
+func readNonRootIndex(buf []byte) ([]Block, error) {
+	if len(buf) < 4 {
+		return nil, errors.New("index block too short")
+	}
+	count := int(binary.BigEndian.Uint32(buf))
+
+	entries := 4 + 4*(count+1)
+	if count < 0 || entries > len(buf) {
+		return nil, fmt.Errorf("index block too short for %d entries", count)
+	}
+
+	blocks := make([]Block, count)
+	for i := range blocks {
+		start := entries + int(binary.BigEndian.Uint32(buf[4+4*i:]))
+		end := entries + int(binary.BigEndian.Uint32(buf[4+4*(i+1):]))
+		if start+nonRootIndexEntryOverhead > end || end > len(buf) {
+			return nil, fmt.Errorf("bad index entry %d (%d-%d of %d)", i, start, end, len(buf))
+		}
+
+		blocks[i].offset = binary.BigEndian.Uint64(buf[start:])
+		blocks[i].size = binary.BigEndian.Uint32(buf[start+8:])
+		blocks[i].firstKeyBytes = buf[start+nonRootIndexEntryOverhead : end]
+	}
+	return blocks, nil
+}
+
+// skipMemstoreTS returns the position after the memstore timestamp, if any, that follows an entry ending at i.
+func (r *Reader) skipMemstoreTS(buf []byte, i int) int {
+	if r.includesMemstoreTS && i < len(buf) {
+		_, n := vintAndLen(buf[i:])
+		return i + n
+	}
+	return i
+}
+
+// pbDelimited returns the varint-length-prefixed protobuf message at the start of buf.
+func pbDelimited(buf []byte) ([]byte, error) {
+	l, n := binary.Uvarint(buf)
+	if n <= 0 || l > uint64(len(buf)-n) {
+		return nil, errors.New("bad protobuf message length")
+	}
+	return buf[n : n+int(l)], nil
+}
+
+/*
+pbMessage calls fn for each field of an encoded protobuf message, with the field's value if it
+is a varint, or its contents if length-delimited.
+
+The few messages HBase writes in the files are simple enough that this beats depending on
+generated code.
+*/
+func pbMessage(buf []byte, fn func(field int, v uint64, b []byte) error) error {
+	for len(buf) > 0 {
+		tag, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return errors.New("bad protobuf field tag")
+		}
+		buf = buf[n:]
+
+		var v uint64
+		var b []byte
+
+		switch tag & 7 {
+		case 0: // varint
+			if v, n = binary.Uvarint(buf); n <= 0 {
+				return errors.New("bad protobuf varint")
+			}
+			buf = buf[n:]
+		case 1: // fixed64
+			if len(buf) < 8 {
+				return errors.New("truncated protobuf fixed64")
+			}
+			v, buf = binary.LittleEndian.Uint64(buf), buf[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(buf)
+			if n <= 0 || l > uint64(len(buf)-n) {
+				return errors.New("bad protobuf field length")
+			}
+			b, buf = buf[n:n+int(l)], buf[n+int(l):]
+		case 5: // fixed32
+			if len(buf) < 4 {
+				return errors.New("truncated protobuf fixed32")
+			}
+			v, buf = uint64(binary.LittleEndian.Uint32(buf)), buf[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", tag&7)
+		}
+
+		if err := fn(int(tag>>3), v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
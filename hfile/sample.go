@@ -0,0 +1,99 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+)
+
+/*
+SampleKeys returns up to n keys chosen at random, in sorted order and without duplicates.
+
+Rather than scanning every entry, it picks random blocks from the index, and then random entries
+within each picked block, so only the picked blocks need to be read. Blocks are picked uniformly,
+so the sample is as uniform as the blocks are evenly sized, which writers aim for. Fewer than n
+keys may be returned if several picks land on the same small block, or on several values of one key.
+*/
+func (r *Reader) SampleKeys(n int) ([][]byte, error) {
+	if n <= 0 || len(r.index) == 0 {
+		return nil, nil
+	}
+	if n > int(r.EntryCount) {
+		n = int(r.EntryCount)
+	}
+	r.checkOut(1)
+	defer r.checkOut(-1)
+
+	picks := make(map[int]int)
+	if n >= int(r.EntryCount) {
+		// Every entry is wanted anyway.
+		for i := range r.index {
+			picks[i] = int(r.EntryCount)
+		}
+	} else {
+		for i := 0; i < n; i++ {
+			picks[rand.Intn(len(r.index))]++
+		}
+	}
+
+	blocks := make([]int, 0, len(picks))
+	for i := range picks {
+		blocks = append(blocks, i)
+	}
+	sort.Ints(blocks)
+
+	sample := make([][]byte, 0, n)
+	for _, i := range blocks {
+		block, err := r.GetBlockBuf(i, nil)
+		if err != nil {
+			return nil, err
+		}
+		keys := r.blockKeys(block)
+
+		var chosen []int
+		if picks[i] >= len(keys) {
+			chosen = make([]int, len(keys))
+			for j := range chosen {
+				chosen[j] = j
+			}
+		} else {
+			chosen = rand.Perm(len(keys))[:picks[i]]
+			sort.Ints(chosen)
+		}
+
+		for _, j := range chosen {
+			// A key's values are adjacent, possibly across blocks, so duplicates are too.
+			if len(sample) > 0 && bytes.Equal(sample[len(sample)-1], keys[j]) {
+				continue
+			}
+			sample = append(sample, append([]byte(nil), keys[j]...))
+		}
+	}
+	return sample, nil
+}
+
+// blockKeys returns the keys of the entries in a data block, pointing into the block.
+func (r *Reader) blockKeys(block []byte) [][]byte {
+	starts := r.entryStarts(block)
+	keys := make([][]byte, len(starts))
+	for i, p := range starts {
+		keyLen := int(binary.BigEndian.Uint32(block[p : p+4]))
+		keys[i] = block[p+8 : p+8+keyLen]
+	}
+	return keys
+}
+
+// entryStarts returns the position of each entry in a data block.
+func (r *Reader) entryStarts(block []byte) []int {
+	var starts []int
+	for i := 0; i+8 <= len(block); {
+		starts = append(starts, i)
+		keyLen := int(binary.BigEndian.Uint32(block[i : i+4]))
+		valLen := int(binary.BigEndian.Uint32(block[i+4 : i+8]))
+		i = r.skipMemstoreTS(block, i+8+keyLen+valLen)
+	}
+	return starts
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleKeys(t *testing.T) {
+	count := 5000
+	keys, vals := make([][]byte, count), make([][]byte, count)
+	for i := range keys {
+		keys[i], vals[i] = keyI(i), valI(i)
+	}
+
+	for _, compress := range []bool{false, true} {
+		f, s := tempHfile(t, compress, 512, keys, vals)
+		defer os.Remove(f)
+		r := s.reader
+
+		sample, err := r.SampleKeys(200)
+		assert.Nil(t, err, err)
+		assert.True(t, len(sample) > 100 && len(sample) <= 200, "sampled %d keys", len(sample))
+		for i, k := range sample {
+			assert.True(t, binary.BigEndian.Uint32(k) < uint32(count), "bad key %v", k)
+			if i > 0 {
+				assert.True(t, bytes.Compare(sample[i-1], k) < 0, "sample not sorted or has dupes")
+			}
+		}
+		// With blocks of a few dozen keys, the sample should span most of the file.
+		assert.True(t, binary.BigEndian.Uint32(sample[0]) < uint32(count/10), "first sampled %v", sample[0])
+		assert.True(t, binary.BigEndian.Uint32(sample[len(sample)-1]) > uint32(count*9/10), "last sampled %v", sample[len(sample)-1])
+
+		all, err := r.SampleKeys(count * 2)
+		assert.Nil(t, err, err)
+		assert.Equal(t, keys, all)
+
+		// Huge requests are clamped to the entry count rather than allocated up front.
+		all, err = r.SampleKeys(1 << 40)
+		assert.Nil(t, err, err)
+		assert.Equal(t, keys, all)
+
+		none, err := r.SampleKeys(0)
+		assert.Nil(t, err, err)
+		assert.Empty(t, none)
+	}
+}
+
+func TestSampleKeysWithRepeatedKeys(t *testing.T) {
+	var keys, vals [][]byte
+	for i := 0; i < 500; i++ {
+		for j := 0; j < 7; j++ {
+			keys, vals = append(keys, keyI(i)), append(vals, valI(j))
+		}
+	}
+	f, s := tempHfile(t, false, 512, keys, vals)
+	defer os.Remove(f)
+
+	for _, n := range []int{50, 1000, len(keys)} {
+		sample, err := s.reader.SampleKeys(n)
+		assert.Nil(t, err, err)
+		assert.NotEmpty(t, sample)
+		for i := 1; i < len(sample); i++ {
+			assert.True(t, bytes.Compare(sample[i-1], sample[i]) < 0, "sample not sorted or has dupes")
+		}
+	}
+	all, err := s.reader.SampleKeys(len(keys))
+	assert.Nil(t, err, err)
+	assert.Len(t, all, 500)
+}
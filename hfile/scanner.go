@@ -19,14 +19,17 @@ type Scanner struct {
 	// When off, maybe be faster but may return incorrect results rather than error on out-of-order keys.
 	EnforceKeyOrder bool
 	OrderedOps
+
+	// From GetScanner, and not yet released.
+	checkedOut bool
 }
 
 func NewScanner(r *Reader) *Scanner {
 	var buf []byte
-	if r.CompressionCodec > CompressionNone {
-		buf = make([]byte, int(float64(r.TotalUncompressedDataBytes/uint64(len(r.index)))*1.5))
+	if size := r.blockBufSize(); size > 0 {
+		buf = make([]byte, size)
 	}
-	return &Scanner{r, 0, nil, nil, buf, true, OrderedOps{nil}}
+	return &Scanner{r, 0, nil, nil, buf, true, OrderedOps{nil}, false}
 }
 
 func (s *Scanner) Reset() {
@@ -44,7 +47,7 @@ func (s *Scanner) blockFor(key []byte) ([]byte, error, bool) {
 		}
 	}
 
-	if s.reader.index[s.idx].IsAfter(key) {
+	if len(s.reader.index) == 0 || s.reader.index[s.idx].IsAfter(key) {
 		return nil, nil, false
 	}
 
@@ -55,7 +58,7 @@ func (s *Scanner) blockFor(key []byte) ([]byte, error, bool) {
 		if err != nil {
 			return nil, err, false
 		}
-		i := 8
+		i := 0
 		s.pos = &i
 		s.idx = idx
 		s.block = data
@@ -120,7 +123,7 @@ func (s *Scanner) getValuesFromBuffer(buf []byte, pos *int, key []byte, first bo
 			ret := make([]byte, valLen)
 			copy(ret, buf[i:i+valLen])
 
-			i += valLen // now on next length pair
+			i = s.reader.skipMemstoreTS(buf, i+valLen) // now on next length pair
 
 			if first {
 				*pos = i
@@ -131,7 +134,7 @@ func (s *Scanner) getValuesFromBuffer(buf []byte, pos *int, key []byte, first bo
 			*pos = i
 			return nil, acc, len(acc) > 0
 		default:
-			i += 8 + keyLen + valLen
+			i = s.reader.skipMemstoreTS(buf, i+8+keyLen+valLen)
 		}
 	}
 
@@ -145,6 +148,10 @@ func (s *Scanner) getValuesFromBuffer(buf []byte, pos *int, key []byte, first bo
 
 func (s *Scanner) Release() {
 	s.Reset()
+	if s.checkedOut {
+		s.checkedOut = false
+		s.reader.checkOut(-1)
+	}
 	select {
 	case s.reader.scannerCache <- s:
 	default:
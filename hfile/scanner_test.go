@@ -2,6 +2,7 @@ package hfile
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -124,3 +125,86 @@ func TestMulti(t *testing.T) {
 	assert.True(t, bytes.Equal(first[0], expectedFirst),
 		fmt.Sprintf("First value CHANGED '%v', expected '%v'\n", first[0], expectedFirst))
 }
+
+func TestGetAllSplitKeys(t *testing.T) {
+	f, r := fakeDataReader(t, true, true)
+	defer os.Remove(f)
+	s := r.GetScanner()
+	defer s.Release()
+
+	segments := [][][]byte{{{0}}, {{0}, {1}}, {{5}, {3}, {2}}, {{0x10}, {0x20}}}
+	found := make(map[string][][]byte)
+	err := s.GetAllSplitKeys(segments, func(key []byte, values [][]byte) {
+		found[string(key)] = values
+	})
+	assert.Nil(t, err, err)
+	assert.Len(t, found, 12)
+	for k, v := range found {
+		i := int(binary.BigEndian.Uint32([]byte(k)))
+		if i%2 == 1 {
+			assert.Equal(t, [][]byte{MockMultiValueInt(i, 0), MockMultiValueInt(i, 1), MockMultiValueInt(i, 2)}, v)
+		} else {
+			assert.Equal(t, [][]byte{MockValueInt(i)}, v)
+		}
+	}
+
+	// A part that prefixes another in its segment makes keys out of order, which should still be found.
+	s.Reset()
+	calls := 0
+	err = s.GetAllSplitKeys([][][]byte{{{0, 0}, {0}}, {{1, 2}, {0, 1, 2}}}, func(key []byte, values [][]byte) {
+		assert.Equal(t, MockKeyInt(258), key)
+		assert.Equal(t, [][]byte{MockValueInt(258)}, values)
+		calls++
+	})
+	assert.Nil(t, err, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestBlockCache(t *testing.T) {
+	f, _ := fakeDataReader(t, true, false)
+	defer os.Remove(f)
+
+	blockSize := 4 * 1024
+	r, err := NewReaderFromConfig(CollectionConfig{Name: "sample", SourcePath: f, LocalPath: f, BlockCacheSize: 3 * blockSize * 2})
+	assert.Nil(t, err, "error creating reader:", err)
+	assert.NotNil(t, r.blockCache, "compressed file should have a block cache")
+
+	for pass := 0; pass < 2; pass++ {
+		s := r.GetScanner()
+		for _, i := range []int{1, 2, 5000, 99999} {
+			v, err, found := s.GetFirst(MockKeyInt(i))
+			assert.Nil(t, err, err)
+			assert.True(t, found, "key %d not found", i)
+			assert.Equal(t, MockValueInt(i), v)
+		}
+		s.Release()
+	}
+	assert.True(t, r.blockCache.Len() > 0, "blocks should have been cached")
+	assert.True(t, r.blockCache.Bytes() <= 3*blockSize*2, "cache too big: %d", r.blockCache.Bytes())
+
+	// Iterating over every block must evict rather than grow the cache, and not corrupt cached blocks.
+	it := r.GetIterator()
+	seen := 0
+	ok, err := it.Next()
+	for ok && err == nil {
+		assert.Equal(t, MockKeyInt(seen), it.Key())
+		seen++
+		ok, err = it.Next()
+	}
+	assert.Nil(t, err, err)
+	assert.Equal(t, 100000, seen)
+	assert.True(t, r.blockCache.Bytes() <= 3*blockSize*2, "cache too big: %d", r.blockCache.Bytes())
+
+	s := r.GetScanner()
+	v, err, found := s.GetFirst(MockKeyInt(1))
+	assert.Nil(t, err, err)
+	assert.True(t, found)
+	assert.Equal(t, MockValueInt(1), v)
+
+	// Uncompressed files have nothing to gain from caching.
+	f2, _ := fakeDataReader(t, false, false)
+	defer os.Remove(f2)
+	r, err = NewReaderFromConfig(CollectionConfig{Name: "sample", SourcePath: f2, LocalPath: f2, BlockCacheSize: 1024 * 1024})
+	assert.Nil(t, err, "error creating reader:", err)
+	assert.Nil(t, r.blockCache, "uncompressed file should not have a block cache")
+}
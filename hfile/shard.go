@@ -0,0 +1,68 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A ShardFunction returns which of partitions a key belongs in.
+type ShardFunction func(key []byte, partitions int) int
+
+/*
+ShardFunctions are the sharding functions collections can be partitioned by, by the name they are
+served (and registered for discovery) under, as in `collection/mod_first_byte/40/4`.
+*/
+var ShardFunctions = map[string]ShardFunction{
+	"mod_first_byte": modFirstByte,
+}
+
+func modFirstByte(key []byte, partitions int) int {
+	if len(key) == 0 {
+		return 0
+	}
+	return int(key[0]) % partitions
+}
+
+// GetShardFunction returns the ShardFunction with the given name.
+func GetShardFunction(name string) (ShardFunction, error) {
+	if f, ok := ShardFunctions[name]; ok {
+		return f, nil
+	}
+	names := make([]string, 0, len(ShardFunctions))
+	for n := range ShardFunctions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("Unknown shard function %q (known: %v)", name, names)
+}
+
+/*
+Reshard writes every pair from readers (for example, all the partitions of a collection) to the
+writer for its partition, as chosen by shard, and then closes the writers. There is one partition
+per writer.
+*/
+func Reshard(readers []*Reader, shard ShardFunction, writers []*Writer) error {
+	err := mergeKeys(readers, func(key []byte, values [][][]byte, _ int) error {
+		w := writers[shard(key, len(writers))]
+		for _, input := range values {
+			for _, v := range input {
+				if err := w.Write(key, v); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range writers {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReshard(t *testing.T) {
+	shard, err := GetShardFunction("mod_first_byte")
+	assert.Nil(t, err, err)
+	_, err = GetShardFunction("nope")
+	assert.NotNil(t, err)
+
+	// Keys spread over every first byte, split into two partitions as the input.
+	keys := make([][][]byte, 2)
+	for i := 0; i < 256*4; i++ {
+		k := []byte{byte(i / 4), byte(i % 4)}
+		keys[shard(k, 2)] = append(keys[shard(k, 2)], k)
+	}
+	readers := make([]*Reader, 2)
+	for i := range readers {
+		f, s := tempHfile(t, true, 64, keys[i], keys[i])
+		defer os.Remove(f)
+		readers[i] = s.reader
+	}
+
+	// More partitions than first bytes leaves some empty, which should still be valid files.
+	partitions := 300
+	writers := make([]*Writer, partitions)
+	paths := make([]string, partitions)
+	for i := range writers {
+		fp, err := ioutil.TempFile("", "reshard")
+		assert.Nil(t, err, err)
+		defer os.Remove(fp.Name())
+		paths[i] = fp.Name()
+		writers[i], err = NewWriter(fp, CompressionSnappy, 64, false)
+		assert.Nil(t, err, err)
+	}
+	assert.Nil(t, Reshard(readers, shard, writers))
+
+	total := 0
+	for i, path := range paths {
+		r, err := NewReader("part", path, CopiedToMem, false)
+		assert.Nil(t, err, err)
+		assert.Equal(t, i < 256, r.EntryCount > 0, "partition %d has %d keys", i, r.EntryCount)
+		_, err, found := r.GetScanner().GetFirst([]byte{byte(i), 0})
+		assert.Nil(t, err, err)
+		assert.Equal(t, i < 256, found)
+		it := r.GetIterator()
+		var prev []byte
+		ok, err := it.Next()
+		for ok {
+			assert.Equal(t, i, int(it.Key()[0])%partitions, "key %v in partition %d", it.Key(), i)
+			assert.Equal(t, it.Key(), it.Value())
+			assert.True(t, prev == nil || After(it.Key(), prev), "keys out of order")
+			prev = it.Key()
+			total++
+			ok, err = it.Next()
+		}
+		assert.Nil(t, err, err)
+	}
+	assert.Equal(t, 256*4, total)
+}
@@ -0,0 +1,299 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+)
+
+/*
+SortingWriter accepts key-value pairs in any order, and writes them, sorted by key, to a Writer.
+
+Pairs are buffered until they take up memLimit bytes, at which point they are sorted and spilled
+to a temporary file as a sorted run. On Close, the runs are merged into the Writer. Values for the
+same key are kept together, in the order they were written, as the Writer requires.
+*/
+type SortingWriter struct {
+	w        *Writer
+	memLimit int
+	tmpDir   string
+	debug    bool
+
+	pairs []sortPair
+	size  int
+
+	runs []string
+}
+
+type sortPair struct {
+	key, value []byte
+}
+
+// Roughly what each buffered pair costs beyond its key and value, for enforcing the memory limit.
+const sortPairOverhead = 64
+
+// At most this many runs are merged at once, since each needs an open file and a read buffer.
+const sortMergeFanIn = 64
+
+// NewSortingWriter returns a SortingWriter that writes to w, spilling runs to tmpDir (or the system's temp dir if empty).
+func NewSortingWriter(w *Writer, memLimit int, tmpDir string) (*SortingWriter, error) {
+	if memLimit <= 0 {
+		return nil, fmt.Errorf("sort memory limit must be positive, not %d", memLimit)
+	}
+	return &SortingWriter{w: w, memLimit: memLimit, tmpDir: tmpDir, debug: w.debug}, nil
+}
+
+// Write buffers a copy of the pair, spilling buffered pairs to a sorted run if over the memory limit.
+func (s *SortingWriter) Write(k, v []byte) error {
+	buf := make([]byte, len(k)+len(v))
+	copy(buf, k)
+	copy(buf[len(k):], v)
+	s.pairs = append(s.pairs, sortPair{buf[:len(k)], buf[len(k):]})
+	s.size += len(buf) + sortPairOverhead
+
+	if s.size >= s.memLimit {
+		return s.spill()
+	}
+	return nil
+}
+
+// sortPairs sorts the buffered pairs, keeping the values for a key in the order they were written.
+func (s *SortingWriter) sortPairs() {
+	sort.SliceStable(s.pairs, func(i, j int) bool {
+		return bytes.Compare(s.pairs[i].key, s.pairs[j].key) < 0
+	})
+}
+
+// spill writes the buffered pairs to a new sorted run, in the same layout as entries in a data block.
+func (s *SortingWriter) spill() error {
+	if len(s.pairs) == 0 {
+		return nil
+	}
+	s.sortPairs()
+
+	fp, err := ioutil.TempFile(s.tmpDir, "hfile-sort-")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, fp.Name())
+
+	out := bufio.NewWriter(fp)
+	for _, p := range s.pairs {
+		if err := writeSortPair(out, p); err != nil {
+			fp.Close()
+			return err
+		}
+	}
+	if err := out.Flush(); err != nil {
+		fp.Close()
+		return err
+	}
+	if err := fp.Close(); err != nil {
+		return err
+	}
+
+	if s.debug {
+		log.Printf("[SortingWriter.spill] spilled %d pairs (%db) to run %d", len(s.pairs), s.size, len(s.runs))
+	}
+	s.pairs = nil
+	s.size = 0
+	return nil
+}
+
+func writeSortPair(out io.Writer, p sortPair) error {
+	var lens [8]byte
+	binary.BigEndian.PutUint32(lens[:4], uint32(len(p.key)))
+	binary.BigEndian.PutUint32(lens[4:], uint32(len(p.value)))
+	if _, err := out.Write(lens[:]); err != nil {
+		return err
+	}
+	if _, err := out.Write(p.key); err != nil {
+		return err
+	}
+	_, err := out.Write(p.value)
+	return err
+}
+
+// Close writes every pair, in order, to the underlying Writer and closes it, then removes any runs.
+func (s *SortingWriter) Close() error {
+	defer s.removeRuns()
+
+	if len(s.runs) == 0 {
+		s.sortPairs()
+		for _, p := range s.pairs {
+			if err := s.w.Write(p.key, p.value); err != nil {
+				return err
+			}
+		}
+		s.pairs = nil
+		return s.w.Close()
+	}
+
+	if err := s.spill(); err != nil {
+		return err
+	}
+	if err := s.merge(); err != nil {
+		return err
+	}
+	return s.w.Close()
+}
+
+func (s *SortingWriter) removeRuns() {
+	for _, run := range s.runs {
+		os.Remove(run)
+	}
+	s.runs = nil
+}
+
+// merge merges the sorted runs into the Writer, first merging groups of them into fewer, longer runs
+// until there are few enough to merge at once.
+func (s *SortingWriter) merge() error {
+	for len(s.runs) > sortMergeFanIn {
+		if err := s.mergePass(); err != nil {
+			return err
+		}
+	}
+
+	if s.debug {
+		log.Printf("[SortingWriter.merge] merging %d runs", len(s.runs))
+	}
+	return mergeRuns(s.runs, s.w.Write)
+}
+
+// mergePass replaces each group of sortMergeFanIn consecutive runs with a single run merging them.
+// Groups stay in the same order, so values for a key still come out in the order they were written.
+func (s *SortingWriter) mergePass() error {
+	if s.debug {
+		log.Printf("[SortingWriter.mergePass] merging %d runs in groups of %d", len(s.runs), sortMergeFanIn)
+	}
+
+	merged := make([]string, 0, (len(s.runs)+sortMergeFanIn-1)/sortMergeFanIn)
+	for len(s.runs) > 0 {
+		group := s.runs
+		if len(group) > sortMergeFanIn {
+			group = group[:sortMergeFanIn]
+		}
+
+		run, err := s.mergeToRun(group)
+		if run != "" {
+			merged = append(merged, run)
+		}
+		if err != nil {
+			// Keep tracking every run still on disk, so Close removes them.
+			s.runs = append(merged, s.runs...)
+			return err
+		}
+
+		for _, path := range group {
+			os.Remove(path)
+		}
+		s.runs = s.runs[len(group):]
+	}
+	s.runs = merged
+	return nil
+}
+
+// mergeToRun merges runs into a new run, returning its path if it was created, even on error.
+func (s *SortingWriter) mergeToRun(runs []string) (string, error) {
+	fp, err := ioutil.TempFile(s.tmpDir, "hfile-sort-")
+	if err != nil {
+		return "", err
+	}
+
+	out := bufio.NewWriter(fp)
+	err = mergeRuns(runs, func(k, v []byte) error {
+		return writeSortPair(out, sortPair{k, v})
+	})
+	if err == nil {
+		err = out.Flush()
+	}
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	return fp.Name(), err
+}
+
+// mergeRuns does a k-way merge of the sorted runs, passing each pair, in order, to write.
+func mergeRuns(runs []string, write func(k, v []byte) error) error {
+	h := make(runHeap, 0, len(runs))
+	for i, path := range runs {
+		fp, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+
+		r := &sortRun{in: bufio.NewReader(fp), order: i}
+		if ok, err := r.next(); err != nil {
+			return err
+		} else if ok {
+			h = append(h, r)
+		}
+	}
+	heap.Init(&h)
+
+	for len(h) > 0 {
+		r := h[0]
+		if err := write(r.cur.key, r.cur.value); err != nil {
+			return err
+		}
+		if ok, err := r.next(); err != nil {
+			return err
+		} else if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return nil
+}
+
+type sortRun struct {
+	in    *bufio.Reader
+	order int // runs were written in order, so earlier runs' values for a key come first.
+	cur   sortPair
+}
+
+func (r *sortRun) next() (bool, error) {
+	var lens [8]byte
+	if _, err := io.ReadFull(r.in, lens[:]); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	keyLen, valLen := binary.BigEndian.Uint32(lens[:4]), binary.BigEndian.Uint32(lens[4:])
+
+	buf := make([]byte, keyLen+valLen)
+	if _, err := io.ReadFull(r.in, buf); err != nil {
+		return false, err
+	}
+	r.cur = sortPair{buf[:keyLen], buf[keyLen:]}
+	return true, nil
+}
+
+type runHeap []*sortRun
+
+func (h runHeap) Len() int { return len(h) }
+func (h runHeap) Less(i, j int) bool {
+	if c := bytes.Compare(h[i].cur.key, h[j].cur.key); c != 0 {
+		return c < 0
+	}
+	return h[i].order < h[j].order
+}
+func (h runHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(*sortRun)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortingWriter(t *testing.T) {
+	count := 10000
+
+	for _, memLimit := range []int{1 << 30, 16 * 1024} {
+		tmpDir, err := ioutil.TempDir("", "sortingwriter")
+		assert.Nil(t, err, err)
+		defer os.RemoveAll(tmpDir)
+
+		fp, err := ioutil.TempFile("", "sortedhfile")
+		assert.Nil(t, err, err)
+		defer os.Remove(fp.Name())
+
+		w, err := NewWriter(fp, CompressionSnappy, 4096, false)
+		assert.Nil(t, err, err)
+		s, err := NewSortingWriter(w, memLimit, tmpDir)
+		assert.Nil(t, err, err)
+
+		// Odd keys get three values, written in order but interleaved with other keys.
+		order := rand.New(rand.NewSource(int64(memLimit))).Perm(count)
+		for k := 0; k < 3; k++ {
+			for _, i := range order {
+				if k == 0 || i%2 == 1 {
+					assert.Nil(t, s.Write(MockKeyInt(i), MockMultiValueInt(i, k)))
+				}
+			}
+		}
+		assert.Nil(t, s.Close())
+
+		runs, _ := filepath.Glob(filepath.Join(tmpDir, "*"))
+		assert.Empty(t, runs, "runs should be removed")
+
+		r, err := NewReader("sorted", fp.Name(), CopiedToMem, false)
+		assert.Nil(t, err, err)
+		assert.Equal(t, uint32(count+count), r.EntryCount)
+
+		it := r.GetIterator()
+		for i := 0; i < count; i++ {
+			values := 1
+			if i%2 == 1 {
+				values = 3
+			}
+			for k := 0; k < values; k++ {
+				ok, err := it.Next()
+				assert.Nil(t, err, err)
+				assert.True(t, ok)
+				assert.Equal(t, MockKeyInt(i), it.Key())
+				assert.Equal(t, MockMultiValueInt(i, k), it.Value())
+			}
+		}
+		ok, err := it.Next()
+		assert.Nil(t, err, err)
+		assert.False(t, ok)
+	}
+}
+
+func TestSortingWriterMergesManyRuns(t *testing.T) {
+	// Each pair is spilled to its own run, so merging needs more than one pass.
+	count := sortMergeFanIn*sortMergeFanIn + 10
+
+	tmpDir, err := ioutil.TempDir("", "sortingwriter")
+	assert.Nil(t, err, err)
+	defer os.RemoveAll(tmpDir)
+
+	fp, err := ioutil.TempFile("", "sortedhfile")
+	assert.Nil(t, err, err)
+	defer os.Remove(fp.Name())
+
+	w, err := NewWriter(fp, CompressionNone, 4096, false)
+	assert.Nil(t, err, err)
+	s, err := NewSortingWriter(w, 1, tmpDir)
+	assert.Nil(t, err, err)
+
+	// Every key gets two values, written in order but in different runs.
+	order := rand.New(rand.NewSource(1)).Perm(count)
+	for k := 0; k < 2; k++ {
+		for _, i := range order {
+			assert.Nil(t, s.Write(MockKeyInt(i), MockMultiValueInt(i, k)))
+		}
+	}
+	assert.Len(t, s.runs, 2*count)
+	assert.Nil(t, s.Close())
+
+	runs, _ := filepath.Glob(filepath.Join(tmpDir, "*"))
+	assert.Empty(t, runs, "runs should be removed")
+
+	r, err := NewReader("sorted", fp.Name(), CopiedToMem, false)
+	assert.Nil(t, err, err)
+	assert.Equal(t, uint32(2*count), r.EntryCount)
+
+	it := r.GetIterator()
+	for i := 0; i < count; i++ {
+		for k := 0; k < 2; k++ {
+			ok, err := it.Next()
+			assert.Nil(t, err, err)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, MockKeyInt(i), it.Key())
+			assert.Equal(t, MockMultiValueInt(i, k), it.Value())
+		}
+	}
+	ok, err := it.Next()
+	assert.Nil(t, err, err)
+	assert.False(t, ok)
+}
+
+func TestSortingWriterRejectsNonPositiveMemLimit(t *testing.T) {
+	w, err := NewWriter(&discardCloser{}, CompressionNone, 4096, false)
+	assert.Nil(t, err, err)
+	for _, memLimit := range []int{0, -1} {
+		_, err := NewSortingWriter(w, memLimit, "")
+		assert.NotNil(t, err, "memLimit %d", memLimit)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"sort"
+)
+
+/*
+GetAllSplitKeys looks up every key made by joining one part from each segment (i.e. each item of
+util.RevProduct(segments), joined), calling found with each key that has values. The key passed to
+found is reused for the next lookup, so must be copied if it is kept.
+
+Keys are built one at a time, rather than building the whole product first. Each segment's parts are
+visited in sorted order, which makes the keys ascending, as the scanner needs, unless some part is a
+prefix of another in its segment. Out-of-order keys are still found, by resetting the scanner,
+but that means searching the index from the start again.
+*/
+func (s *Scanner) GetAllSplitKeys(segments [][][]byte, found func(key []byte, values [][]byte)) error {
+	sorted := make([][][]byte, len(segments))
+	for i, parts := range segments {
+		sorted[i] = parts
+		if !sort.SliceIsSorted(parts, func(a, b int) bool { return bytes.Compare(parts[a], parts[b]) < 0 }) {
+			sorted[i] = append([][]byte(nil), parts...)
+			sort.Slice(sorted[i], func(a, b int) bool { return bytes.Compare(sorted[i][a], sorted[i][b]) < 0 })
+		}
+	}
+
+	// Since key's buffer is reused, the scanner can't keep it to check ordering, so we check against a copy.
+	enforce := s.EnforceKeyOrder
+	s.EnforceKeyOrder = false
+	defer func() { s.EnforceKeyOrder = enforce }()
+	var key, prev []byte
+
+	var walk func(depth int) error
+	walk = func(depth int) error {
+		if depth == len(sorted) {
+			if prev != nil && bytes.Compare(key, prev) < 0 {
+				s.Reset()
+			}
+			values, err := s.GetAll(key)
+			if err != nil {
+				return err
+			}
+			if len(values) > 0 {
+				found(key, values)
+			}
+			prev = append(prev[:0], key...)
+			return nil
+		}
+
+		n := len(key)
+		for _, part := range sorted[depth] {
+			key = append(key[:n], part...)
+			if err := walk(depth + 1); err != nil {
+				return err
+			}
+		}
+		key = key[:n]
+		return nil
+	}
+	return walk(0)
+}
@@ -26,7 +26,11 @@ func MockMultiValueInt(i, k int) []byte {
 }
 
 func GenerateMockHfile(path string, keyCount, blockSize int, compress, verbose, progress bool) error {
-	w, err := NewLocalWriter(path, compress, blockSize, verbose)
+	codec := CompressionNone
+	if compress {
+		codec = CompressionSnappy
+	}
+	w, err := NewLocalWriter(path, codec, blockSize, verbose)
 	if err != nil {
 		return err
 	}
@@ -34,7 +38,11 @@ func GenerateMockHfile(path string, keyCount, blockSize int, compress, verbose,
 }
 
 func GenerateMockMultiHfile(path string, keyCount, blockSize int, compress, verbose, progress bool) error {
-	w, err := NewLocalWriter(path, compress, blockSize, verbose)
+	codec := CompressionNone
+	if compress {
+		codec = CompressionSnappy
+	}
+	w, err := NewLocalWriter(path, codec, blockSize, verbose)
 	if err != nil {
 		return err
 	}
@@ -79,5 +87,5 @@ func TestdataCollectionSet(name string, count int, compress bool, load LoadMetho
 	} else if err != nil {
 		return nil, err
 	}
-	return LoadCollections([]*CollectionConfig{{name, path, path, nil, load, false, name, "", "", ""}}, os.TempDir(), false, nil)
+	return LoadCollections([]*CollectionConfig{{name, path, path, nil, load, false, name, "", "", "", 0}}, os.TempDir(), false, nil)
 }
@@ -0,0 +1,97 @@
+#!/usr/bin/env python3
+"""
+Writes blocks compressed the way Hadoop's Lz4Codec and ZStandardCodec compress hfile blocks, for
+compression_test.go.
+
+Both codecs wrap the native liblz4 and libzstd, so the compressed data comes from the reference lz4
+and zstd command line tools (which use the same libraries), and is framed as the codecs' streams
+frame it:
+
+- Lz4Codec writes a BlockCompressorStream: a big-endian uint32 of the uncompressed length, then
+  big-endian uint32 length-prefixed raw LZ4 blocks, each compressing at most 261100 bytes (the
+  default 256k io.compression.codec.lz4.buffersize, less its 256k/255+16 compression overhead).
+- ZStandardCodec writes a CompressorStream, which is just the compressor's output: a single zstd
+  frame, streamed, so without the content size or a checksum.
+
+Each fixture decompresses to codecFixtureData(n) (see compression_test.go) for the n in its name.
+
+Usage: hfile/testdata/gen-codec-fixtures.py [lz4] [zstd]
+"""
+
+import os
+import struct
+import subprocess
+import sys
+
+LZ4 = os.environ.get("LZ4", "lz4")
+ZSTD = os.environ.get("ZSTD", "zstd")
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+
+# BlockCompressorStream's MAX_INPUT_SIZE for Lz4Codec's default buffer size.
+LZ4_BUFFER_SIZE = 256 * 1024
+LZ4_MAX_INPUT = LZ4_BUFFER_SIZE - (LZ4_BUFFER_SIZE // 255 + 16)
+
+
+def fixture_data(n):
+    """n lines like a sorted run of pairs, matching codecFixtureData in compression_test.go."""
+    return b"".join(b"key-%08d\tvalue-for-%d\n" % (i, i * 7919 % 100003) for i in range(n))
+
+
+def lz4_block(raw):
+    """Compresses raw to a single raw LZ4 block, cut out of an lz4 frame."""
+    frame = subprocess.run(
+        [LZ4, "-q", "-9", "-B7", "-BI", "--no-frame-crc", "-c"],
+        input=raw, stdout=subprocess.PIPE, check=True).stdout
+
+    assert frame[:4] == b"\x04\x22\x4d\x18", "not an lz4 frame"
+    flg = frame[4]
+    p = 6  # magic, FLG and BD
+    if flg & 0x08:
+        p += 8  # content size
+    p += 1  # header checksum
+
+    size, = struct.unpack_from("<I", frame, p)
+    p += 4
+    assert not size & 0x80000000, "data didn't compress, so the frame stored it uncompressed"
+    block = frame[p:p + size]
+    p += size
+    if flg & 0x10:
+        p += 4  # block checksum
+    assert frame[p:p + 4] == b"\x00\x00\x00\x00", "raw data didn't fit in one lz4 frame block"
+    return block
+
+
+def hadoop_lz4(raw):
+    out = [struct.pack(">I", len(raw))]
+    for i in range(0, len(raw), LZ4_MAX_INPUT):
+        block = lz4_block(raw[i:i + LZ4_MAX_INPUT])
+        out.append(struct.pack(">I", len(block)))
+        out.append(block)
+    return b"".join(out)
+
+
+def hadoop_zstd(raw):
+    # Reading stdin, the zstd tool streams, so doesn't know the content size to record it.
+    return subprocess.run(
+        [ZSTD, "-3", "--no-check", "-q", "-c"],
+        input=raw, stdout=subprocess.PIPE, check=True).stdout
+
+
+def write(name, data):
+    with open(os.path.join(HERE, name), "wb") as f:
+        f.write(data)
+    print("wrote %s (%d bytes)" % (name, len(data)))
+
+
+def main(codecs):
+    # One block of a typical size, and one over LZ4_MAX_INPUT, which Lz4Codec splits into several chunks.
+    for n in (2000, 10000):
+        if "lz4" in codecs:
+            write("hadoop-lz4-%d.bin" % n, hadoop_lz4(fixture_data(n)))
+        if "zstd" in codecs:
+            write("hadoop-zstd-%d.bin" % n, hadoop_zstd(fixture_data(n)))
+
+
+if __name__ == "__main__":
+    main(sys.argv[1:] or ["lz4", "zstd"])
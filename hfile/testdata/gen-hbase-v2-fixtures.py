@@ -0,0 +1,419 @@
+#!/usr/bin/env python3
+"""
+Writes HFile v2 files laid out block for block as HBase's HFileWriterV2 lays them out, for
+reader_v2_test.go, as a check on the reader independent of its own test writer:
+
+- hbase-v2.0.hfile: minor version 0, as HBase 0.92 writes it: 24 byte block headers, no checksums,
+  Writable FileInfo and trailer, uncompressed, with a two-level data index.
+- hbase-v2.3-gz.hfile: minor version 3, as HBase 0.98 writes it: 33 byte block headers, CRC32C
+  checksums over every 16k of each block, protobuf FileInfo and trailer, gzip-compressed blocks,
+  with a three-level data index.
+
+Both are written as by HFile.Writer's append(key, value) with the raw bytes comparator, which uses
+blocks' actual first keys in the index. Like HBase's own tests, they use small block and index
+chunk sizes, so that a small file still needs several index levels. Leaf index blocks are written
+inline, between the data blocks, and intermediate ones just before the root index; the root
+index of a multi-level index is followed by mid-key metadata; and each block records the offset
+of the previous block of its type.
+
+Each file holds hbaseFixturePairs() (see reader_v2_test.go), plus a meta block named "sidecar",
+appended as a BytesWritable.
+
+Usage: hfile/testdata/gen-hbase-v2-fixtures.py
+"""
+
+import os
+import struct
+import zlib
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+
+DATA = b"DATABLK*"
+LEAF_INDEX = b"IDXLEAF2"
+META = b"METABLKc"
+INTERMEDIATE_INDEX = b"IDXINTE2"
+ROOT_INDEX = b"IDXROOT2"
+FILE_INFO = b"FILEINF2"
+TRAILER = b"TRABLK\"$"
+
+# Compression.Algorithm ordinals.
+GZ = 1
+NONE = 2
+
+CHECKSUM_CRC32C = 2
+BYTES_PER_CHECKSUM = 16 * 1024
+
+TRAILER_SIZE = 212
+PB_MAGIC = b"PBUF"
+COMPARATOR = b"org.apache.hadoop.hbase.util.Bytes$ByteArrayComparator"
+
+
+def fixture_pairs():
+    """The pairs in each file, matching hbaseFixturePairs in reader_v2_test.go."""
+    pairs = []
+    for i in range(0, 3000, 2):
+        values = 3 if i % 10 == 0 else 1
+        for v in range(values):
+            pairs.append((b"row-%06d" % i, b"value-%d-%d-" % (i, v) + b"x" * (i % 17)))
+    return pairs
+
+
+def crc32c(data):
+    crc = 0xffffffff
+    for b in data:
+        crc = CRC32C_TABLE[(crc ^ b) & 0xff] ^ (crc >> 8)
+    return crc ^ 0xffffffff
+
+
+def _crc32c_table():
+    table = []
+    for i in range(256):
+        c = i
+        for _ in range(8):
+            c = (c >> 1) ^ 0x82f63b78 if c & 1 else c >> 1
+        table.append(c)
+    return table
+
+
+CRC32C_TABLE = _crc32c_table()
+
+
+def vint(n):
+    """WritableUtils.writeVInt, for the non-negative lengths we need."""
+    if n <= 127:
+        return bytes([n])
+    b = n.to_bytes((n.bit_length() + 7) // 8, "big")
+    return bytes([0x100 - 112 - len(b)]) + b
+
+
+def vlong(n):
+    return vint(n)
+
+
+def pb_varint(n):
+    out = bytearray()
+    while True:
+        b = n & 0x7f
+        n >>= 7
+        if n:
+            out.append(b | 0x80)
+        else:
+            out.append(b)
+            return bytes(out)
+
+
+def pb_field(field, value):
+    if isinstance(value, bytes):
+        return pb_varint(field << 3 | 2) + pb_varint(len(value)) + value
+    return pb_varint(field << 3) + pb_varint(value)
+
+
+def pb_delimited(msg):
+    return pb_varint(len(msg)) + msg
+
+
+def gzip_block(body):
+    """What HBase's ReusableStreamGzipCodec writes for a block: a gzip member, as java.util.zip writes them."""
+    c = zlib.compressobj(6, zlib.DEFLATED, -zlib.MAX_WBITS)
+    deflated = c.compress(body) + c.flush()
+    header = b"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x00"
+    return header + deflated + struct.pack("<II", zlib.crc32(body), len(body) & 0xffffffff)
+
+
+class BlockWriter(object):
+    """HFileBlock.Writer: writes blocks, with headers, to the file."""
+
+    def __init__(self, out, minor, codec):
+        self.out = out
+        self.minor = minor
+        self.codec = codec
+        self.prev_offset_by_type = {}
+
+    def header_size(self):
+        return 33 if self.minor >= 1 else 24
+
+    def write(self, magic, body):
+        """Writes a block, returning its offset, on-disk size with header and uncompressed size with header."""
+        offset = len(self.out)
+        data = gzip_block(body) if self.codec == GZ else body
+        header_size = self.header_size()
+
+        on_disk_data_size = header_size + len(data)
+        checksums = 0
+        if self.minor >= 1:
+            checksums = 4 * ((on_disk_data_size + BYTES_PER_CHECKSUM - 1) // BYTES_PER_CHECKSUM)
+
+        header = magic + struct.pack(">iiq", len(data) + checksums, len(body),
+                                     self.prev_offset_by_type.get(magic, -1))
+        if self.minor >= 1:
+            header += struct.pack(">bii", CHECKSUM_CRC32C, BYTES_PER_CHECKSUM, on_disk_data_size)
+
+        block = header + data
+        self.out += block
+        if self.minor >= 1:
+            # Each checksum covers the next BYTES_PER_CHECKSUM bytes of the header and (compressed) data.
+            for i in range(0, len(block), BYTES_PER_CHECKSUM):
+                self.out += struct.pack(">I", crc32c(block[i:i + BYTES_PER_CHECKSUM]))
+
+        self.prev_offset_by_type[magic] = offset
+        return offset, len(self.out) - offset, header_size + len(body)
+
+
+class IndexChunk(object):
+    """HFileBlockIndex.BlockIndexChunk."""
+
+    def __init__(self):
+        self.keys, self.offsets, self.sizes, self.sub_entries = [], [], [], []
+        self.non_root_entries_size = 0
+        self.root_size = 0
+
+    def add(self, key, offset, size, sub_entries=-1):
+        self.keys.append(key)
+        self.offsets.append(offset)
+        self.sizes.append(size)
+        self.sub_entries.append(sub_entries)
+        self.non_root_entries_size += 12 + len(key)
+        self.root_size += 12 + len(vint(len(key))) + len(key)
+
+    def __len__(self):
+        return len(self.keys)
+
+    def non_root_size(self):
+        return 4 + 4 * (len(self) + 1) + self.non_root_entries_size
+
+    def non_root(self):
+        out = struct.pack(">i", len(self))
+        pos = 0
+        for key in self.keys:
+            out += struct.pack(">i", pos)
+            pos += 12 + len(key)
+        out += struct.pack(">i", pos)
+        for key, offset, size in zip(self.keys, self.offsets, self.sizes):
+            out += struct.pack(">qi", offset, size) + key
+        return out
+
+    def root(self):
+        return b"".join(struct.pack(">qi", offset, size) + vint(len(key)) + key
+                        for key, offset, size in zip(self.keys, self.offsets, self.sizes))
+
+    def mid_key_metadata(self):
+        total = self.sub_entries[-1]
+        mid = (total - 1) // 2
+        # The first entry whose leaf holds the mid-key's data block.
+        entry = next(i for i, n in enumerate(self.sub_entries) if n > mid)
+        before = self.sub_entries[entry - 1] if entry > 0 else 0
+        return struct.pack(">qii", self.offsets[entry], self.sizes[entry], mid - before)
+
+
+class IndexWriter(object):
+    """HFileBlockIndex.BlockIndexWriter, for the data index."""
+
+    def __init__(self, blocks, max_chunk_size):
+        self.blocks = blocks
+        self.max_chunk_size = max_chunk_size
+        self.root = IndexChunk()
+        self.inline = IndexChunk()
+        self.levels = 1
+        self.total_entries = 0
+        self.uncompressed_size = 0
+
+    def add_entry(self, key, offset, size):
+        self.inline.add(key, offset, size)
+        self.total_entries += 1
+
+    def should_write_block(self, closing):
+        if len(self.inline) == 0:
+            return False
+        if closing:
+            if len(self.root) == 0:
+                # Everything fits in one chunk, so it becomes the root, rather than a single leaf.
+                self.root, self.inline = self.inline, None
+                return False
+            return True
+        return self.inline.non_root_size() >= self.max_chunk_size
+
+    def write_inline_block(self):
+        first_key = self.inline.keys[0]
+        body = self.inline.non_root()
+        offset, size, _ = self.blocks.write(LEAF_INDEX, body)
+        self.uncompressed_size += len(body)
+        self.inline = IndexChunk()
+
+        if len(self.root) == 0:
+            self.levels = 2
+        self.root.add(first_key, offset, size, self.total_entries)
+        return self.blocks.header_size() + len(body)
+
+    def write_index_blocks(self):
+        mid_key = self.root.mid_key_metadata() if self.levels > 1 else b""
+
+        if self.inline is not None:
+            while self.root.root_size > self.max_chunk_size:
+                self.root = self.write_intermediate_level(self.root)
+                self.levels += 1
+
+        body = self.root.root() + mid_key
+        offset, _, _ = self.blocks.write(ROOT_INDEX, body)
+        self.uncompressed_size += len(body)
+        return offset
+
+    def write_intermediate_level(self, level):
+        parent, chunk = IndexChunk(), IndexChunk()
+        for key, offset, size in zip(level.keys, level.offsets, level.sizes):
+            chunk.add(key, offset, size)
+            if chunk.root_size >= self.max_chunk_size:
+                self.write_intermediate_block(parent, chunk)
+                chunk = IndexChunk()
+        if len(chunk) > 0:
+            self.write_intermediate_block(parent, chunk)
+        return parent
+
+    def write_intermediate_block(self, parent, chunk):
+        body = chunk.non_root()
+        offset, size, _ = self.blocks.write(INTERMEDIATE_INDEX, body)
+        self.uncompressed_size += len(body)
+        parent.add(chunk.keys[0], offset, size)
+
+
+class HFileWriterV2(object):
+    def __init__(self, minor, codec, block_size, max_chunk_size):
+        self.out = bytearray()
+        self.minor = minor
+        self.codec = codec
+        self.block_size = block_size
+        self.blocks = BlockWriter(self.out, minor, codec)
+        self.index = IndexWriter(self.blocks, max_chunk_size)
+        self.meta_index = IndexChunk()
+
+        self.block = None
+        self.first_key_in_block = None
+        self.last_key = None
+        self.first_data_block_offset = -1
+        self.last_data_block_offset = -1
+        self.entry_count = 0
+        self.total_key_length = 0
+        self.total_value_length = 0
+        self.total_uncompressed_bytes = 0
+        self.meta = []
+
+    def append(self, key, value):
+        if self.last_key is not None and key < self.last_key:
+            raise ValueError("keys out of order")
+        # Blocks are only split between keys, so all of a key's values are in one block.
+        if key != self.last_key and self.block is not None and len(self.block) >= self.block_size:
+            self.finish_block()
+            self.write_inline_blocks(False)
+            self.block = None
+        if self.block is None:
+            self.block = bytearray()
+            self.first_key_in_block = key
+
+        # Appended pairs have no memstore timestamp, so it's written as 0.
+        self.block += struct.pack(">ii", len(key), len(value)) + key + value + vlong(0)
+        self.last_key = key
+        self.entry_count += 1
+        self.total_key_length += len(key)
+        self.total_value_length += len(value)
+
+    def append_meta_block(self, name, data):
+        self.meta.append((name, data))
+
+    def finish_block(self):
+        if not self.block:
+            return
+        offset, size, uncompressed = self.blocks.write(DATA, bytes(self.block))
+        if self.first_data_block_offset == -1:
+            self.first_data_block_offset = offset
+        self.last_data_block_offset = offset
+        self.index.add_entry(self.first_key_in_block, offset, size)
+        self.total_uncompressed_bytes += uncompressed
+
+    def write_inline_blocks(self, closing):
+        while self.index.should_write_block(closing):
+            self.total_uncompressed_bytes += self.index.write_inline_block()
+
+    def close(self):
+        self.finish_block()
+        self.write_inline_blocks(True)
+
+        for name, data in self.meta:
+            # A BytesWritable: its length, then its bytes.
+            offset, size, uncompressed = self.blocks.write(META, struct.pack(">i", len(data)) + data)
+            self.total_uncompressed_bytes += uncompressed
+            self.meta_index.add(name, offset, size)
+
+        load_on_open = self.index.write_index_blocks()
+
+        _, _, uncompressed = self.blocks.write(ROOT_INDEX, self.meta_index.root())
+        self.total_uncompressed_bytes += uncompressed
+
+        file_info = {
+            b"hfile.LASTKEY": self.last_key,
+            b"hfile.AVG_KEY_LEN": struct.pack(">i", self.total_key_length // self.entry_count),
+            b"hfile.AVG_VALUE_LEN": struct.pack(">i", self.total_value_length // self.entry_count),
+            b"MAX_MEMSTORE_TS_KEY": struct.pack(">q", 0),
+            b"KEY_VALUE_VERSION": struct.pack(">i", 1),
+        }
+        file_info_offset, _, uncompressed = self.blocks.write(FILE_INFO, self.file_info(file_info))
+        self.total_uncompressed_bytes += uncompressed
+
+        self.trailer(file_info_offset, load_on_open)
+        return bytes(self.out)
+
+    def file_info(self, fields):
+        if self.minor >= 2:
+            msg = b"".join(pb_field(1, pb_field(1, k) + pb_field(2, fields[k])) for k in sorted(fields))
+            return PB_MAGIC + pb_delimited(msg)
+
+        # A byte[] map Writable: the count, then each key and value, with the value's class code (1, for byte[]).
+        out = struct.pack(">i", len(fields))
+        for k in sorted(fields):
+            out += vint(len(k)) + k + b"\x01" + vint(len(fields[k])) + fields[k]
+        return out
+
+    def trailer(self, file_info_offset, load_on_open):
+        start = len(self.out)
+        self.out += TRAILER
+        if self.minor >= 2:
+            msg = b"".join([
+                pb_field(1, file_info_offset),
+                pb_field(2, load_on_open),
+                pb_field(3, self.index.uncompressed_size),
+                pb_field(4, self.total_uncompressed_bytes),
+                pb_field(5, len(self.index.root)),
+                pb_field(6, len(self.meta_index)),
+                pb_field(7, self.entry_count),
+                pb_field(8, self.index.levels),
+                pb_field(9, self.first_data_block_offset),
+                pb_field(10, self.last_data_block_offset),
+                pb_field(11, COMPARATOR),
+                pb_field(12, self.codec),
+            ])
+            self.out += pb_delimited(msg)
+            self.out += b"\x00" * (start + TRAILER_SIZE - 4 - len(self.out))
+        else:
+            self.out += struct.pack(
+                ">qqiqiqqiiqq", file_info_offset, load_on_open, len(self.index.root),
+                self.index.uncompressed_size, len(self.meta_index), self.total_uncompressed_bytes,
+                self.entry_count, self.codec, self.index.levels, self.first_data_block_offset,
+                self.last_data_block_offset)
+            self.out += COMPARATOR.ljust(128, b"\x00")
+        self.out += struct.pack(">i", self.minor << 24 | 2)
+        assert len(self.out) - start == TRAILER_SIZE
+
+
+def write(name, minor, codec, max_chunk_size):
+    w = HFileWriterV2(minor, codec, block_size=1024, max_chunk_size=max_chunk_size)
+    for key, value in fixture_pairs():
+        w.append(key, value)
+    w.append_meta_block(b"sidecar", b"some sidecar data")
+    data = w.close()
+
+    with open(os.path.join(HERE, name), "wb") as f:
+        f.write(data)
+    print("wrote %s (%d bytes, %d index levels)" % (name, len(data), w.index.levels))
+
+
+if __name__ == "__main__":
+    write("hbase-v2.0.hfile", 0, NONE, 1024)
+    write("hbase-v2.3-gz.hfile", 3, GZ, 128)
@@ -0,0 +1,142 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnloadWaitsForRelease(t *testing.T) {
+	for _, method := range []LoadMethod{CopiedToMem, OnDisk} {
+		f, err := tempHfileForUnload()
+		assert.Nil(t, err)
+		defer os.Remove(f)
+
+		r, err := NewReader("sample", f, method, false)
+		assert.Nil(t, err)
+
+		s := r.GetScanner()
+		it := r.GetIterator()
+		_, err, found := s.GetFirst(MockKeyInt(1))
+		assert.Nil(t, err)
+		assert.True(t, found, method)
+
+		done := make(chan error)
+		go func() { done <- r.Unload(0) }()
+
+		s.Release()
+		select {
+		case <-done:
+			t.Fatal("unloaded while an iterator was checked out", method)
+		case <-time.After(3 * unloadPollInterval):
+		}
+
+		// Releasing twice shouldn't let the count go negative.
+		s.Release()
+		it.Release()
+		select {
+		case err := <-done:
+			assert.Nil(t, err, method)
+		case <-time.After(time.Second):
+			t.Fatal("still not unloaded after releasing everything", method)
+		}
+		assert.Nil(t, r.free, method)
+	}
+}
+
+func TestUnloadGrace(t *testing.T) {
+	f, err := tempHfileForUnload()
+	assert.Nil(t, err)
+	defer os.Remove(f)
+
+	r, err := NewReader("sample", f, CopiedToMem, false)
+	assert.Nil(t, err)
+
+	start := time.Now()
+	r.GetScanner().Release()
+	assert.Nil(t, r.Unload(2*unloadPollInterval))
+	assert.True(t, time.Since(start) >= 2*unloadPollInterval)
+}
+
+func tempHfileForUnload() (string, error) {
+	fp, err := ioutil.TempFile("", "unloadhfile")
+	if err != nil {
+		return "", err
+	}
+	fp.Close()
+	return fp.Name(), GenerateMockHfile(fp.Name(), 1000, 1024*4, false, false, false)
+}
+
+func TestDirectReadsAreReleased(t *testing.T) {
+	f, err := tempHfileForUnload()
+	assert.Nil(t, err)
+	defer os.Remove(f)
+
+	r, err := NewReader("sample", f, CopiedToMem, false)
+	assert.Nil(t, err)
+
+	// These read the data without a scanner or iterator, checking it out while they run.
+	assert.Nil(t, r.CalculateBloom(0.01))
+	first, err := r.FirstKey()
+	assert.Nil(t, err)
+	_, err = r.LastKey()
+	assert.Nil(t, err)
+	_, err = r.SampleKeys(10)
+	assert.Nil(t, err)
+	assert.False(t, r.inUse())
+
+	assert.Nil(t, r.Unload(0))
+	assert.Equal(t, MockKeyInt(0), first, "keys returned should be copies that outlive the data")
+}
+
+func TestFailedOpenIsFreed(t *testing.T) {
+	f, err := tempHfileForUnload()
+	assert.Nil(t, err)
+	defer os.Remove(f)
+
+	data, err := ioutil.ReadFile(f)
+	assert.Nil(t, err)
+	r, err := NewReader("sample", f, OnDisk, false)
+	assert.Nil(t, err)
+	indexOffset := r.DataIndexOffset
+	assert.Nil(t, r.Unload(0))
+
+	badIndex := append([]byte{}, data...)
+	copy(badIndex[indexOffset:], "XXXXXXXX")
+
+	for name, contents := range map[string][]byte{
+		"too short": data[:2],
+		"truncated": data[:len(data)/2],
+		"bad index": badIndex,
+	} {
+		bad, err := ioutil.TempFile("", "unloadhfile")
+		assert.Nil(t, err)
+		defer os.Remove(bad.Name())
+		bad.Write(contents)
+		bad.Close()
+
+		before := atomic.LoadInt64(&offheapBytes)
+		r, err := NewReader("bad", bad.Name(), CopiedToMem, false)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, r, name)
+		assert.Equal(t, before, atomic.LoadInt64(&offheapBytes), "%s: copy should be freed", name)
+
+		maps, mapsErr := ioutil.ReadFile("/proc/self/maps")
+		if mapsErr != nil {
+			continue
+		}
+		assert.False(t, bytes.Contains(maps, []byte(bad.Name())), "%s: file was already mapped", name)
+		r, err = NewReader("bad", bad.Name(), MemlockFile, false)
+		assert.NotNil(t, err, name)
+		assert.Nil(t, r, name)
+		maps, _ = ioutil.ReadFile("/proc/self/maps")
+		assert.False(t, bytes.Contains(maps, []byte(bad.Name())), "%s: mapping should be freed", name)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// VerifyReport describes what Verify found, in a form suitable for encoding as JSON.
+type VerifyReport struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+	Ok      bool   `json:"ok"`
+
+	Blocks            int    `json:"blocks"`
+	Entries           uint64 `json:"entries"`
+	UncompressedBytes uint64 `json:"uncompressedBytes"`
+
+	Problems []VerifyProblem `json:"problems"`
+}
+
+// A VerifyProblem is something wrong with the file, found by the named check, in a block (or -1 if not in a block).
+type VerifyProblem struct {
+	Check   string `json:"check"`
+	Block   int    `json:"block"`
+	Message string `json:"message"`
+}
+
+func (v *VerifyReport) problem(check string, block int, format string, args ...interface{}) {
+	v.Ok = false
+	v.Problems = append(v.Problems, VerifyProblem{check, block, fmt.Sprintf(format, args...)})
+}
+
+/*
+VerifyFile opens the hfile at path and verifies it, reporting any failure to open it as a problem
+too (including panics, which a sufficiently corrupt file can cause), rather than returning an error.
+The file is unloaded again before it returns.
+*/
+func VerifyFile(path string) (report *VerifyReport) {
+	report = &VerifyReport{Path: path, Ok: true, Problems: []VerifyProblem{}}
+	defer func() {
+		if p := recover(); p != nil {
+			report.problem("open", -1, "panic opening file: %v", p)
+		}
+	}()
+
+	r, err := NewReader(path, path, OnDisk, false)
+	if err != nil {
+		report.problem("open", -1, "%v", err)
+		return report
+	}
+	// Deferred, so the file is unmapped even if verifying panics.
+	defer func() {
+		if err := r.Unload(0); err != nil {
+			report.problem("unload", -1, "%v", err)
+		}
+	}()
+	report = r.Verify()
+	return report
+}
+
+/*
+Verify reads the whole file, checking the trailer magic, that the index's blocks are within the
+file, that every block has the right magic and decompresses, that keys are in order within and
+across blocks (and match the index), that the trailer's entry count matches the blocks' contents
+and that its uncompressed size covers them.
+*/
+func (r *Reader) Verify() (report *VerifyReport) {
+	report = &VerifyReport{
+		Path:     r.SourcePath,
+		Version:  fmt.Sprintf("%d.%d", r.majorVersion, r.minorVersion),
+		Ok:       true,
+		Blocks:   len(r.index),
+		Problems: []VerifyProblem{},
+	}
+	block := -1
+	defer func() {
+		if p := recover(); p != nil {
+			report.problem("panic", block, "%v", p)
+		}
+	}()
+
+	if t := r.Trailer.offset; t < 0 || t+len(TrailerMagic) > len(r.data) || !bytes.Equal(r.data[t:t+len(TrailerMagic)], TrailerMagic) {
+		report.problem("trailer", -1, "bad trailer magic")
+	}
+
+	if !r.verifyIndexBounds(report) {
+		return report
+	}
+
+	var prev []byte
+	for block = range r.index {
+		data, err := r.readDataBlock(block, nil)
+		if err != nil {
+			report.problem("block", block, "%v", err)
+			continue
+		}
+		report.UncompressedBytes += uint64(len(data)) + r.blockHeaderSize()
+
+		// readDataBlock has already checked that the entries are all within the block.
+		for i, key := range r.blockKeys(data) {
+			if i == 0 && !bytes.Equal(key, r.index[block].firstKeyBytes) {
+				report.problem("index", block, "first key %x does not match index key %x", key, r.index[block].firstKeyBytes)
+			}
+			if prev != nil && bytes.Compare(prev, key) > 0 {
+				report.problem("order", block, "key %x (entry %d) is before previous key %x", key, i, prev)
+			}
+			prev = append(prev[:0], key...)
+			report.Entries++
+		}
+	}
+	block = -1
+
+	if report.Entries != uint64(r.EntryCount) {
+		report.problem("entryCount", -1, "trailer has %d entries, but blocks have %d", r.EntryCount, report.Entries)
+	}
+	// HBase's writers also count some index and meta data in the total, so the data blocks are only a lower bound.
+	if total := r.TotalUncompressedDataBytes; total < report.UncompressedBytes {
+		report.problem("uncompressedBytes", -1, "trailer has %d uncompressed bytes, but blocks have %d", total, report.UncompressedBytes)
+	}
+	return report
+}
+
+// verifyIndexBounds checks that the index's blocks are in order, and start (and, if not compressed, end) within the data.
+func (r *Reader) verifyIndexBounds(report *VerifyReport) bool {
+	end := uint64(r.Trailer.offset)
+	if len(r.index) > 0 && r.DataIndexOffset < end {
+		end = r.DataIndexOffset
+	}
+
+	ok := true
+	for i, b := range r.index {
+		switch {
+		case b.offset >= end:
+			report.problem("index", i, "block offset %d is past the end of the data (%d)", b.offset, end)
+		case i > 0 && b.offset <= r.index[i-1].offset:
+			report.problem("index", i, "block offset %d is not after the previous block's (%d)", b.offset, r.index[i-1].offset)
+		case r.majorVersion == 1 && r.CompressionCodec == CompressionNone && b.offset+uint64(b.size) > end:
+			report.problem("index", i, "block at %d (%d bytes) extends past the end of the data (%d)", b.offset, b.size, end)
+		default:
+			continue
+		}
+		ok = false
+	}
+	return ok
+}
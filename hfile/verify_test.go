@@ -0,0 +1,142 @@
+// Copyright (C) 2017 Foursquare Labs Inc.
+
+package hfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func verifyChecks(report *VerifyReport) []string {
+	var checks []string
+	for _, p := range report.Problems {
+		checks = append(checks, p.Check)
+	}
+	return checks
+}
+
+func TestVerify(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		f, r := fakeDataReader(t, compress, true)
+		defer os.Remove(f)
+
+		report := r.Verify()
+		assert.True(t, report.Ok, "%v", report.Problems)
+		assert.Empty(t, report.Problems)
+		assert.Equal(t, len(r.index), report.Blocks)
+		assert.Equal(t, uint64(r.EntryCount), report.Entries)
+		assert.Equal(t, r.TotalUncompressedDataBytes, report.UncompressedBytes)
+
+		report = VerifyFile(f)
+		assert.True(t, report.Ok, "%v", report.Problems)
+		assert.Equal(t, f, report.Path)
+	}
+
+	for _, minor := range []uint32{0, 1, 3} {
+		f, r := v2TestReader(t, minor, CompressionSnappy, 5000)
+		defer os.Remove(f)
+
+		report := r.Verify()
+		assert.True(t, report.Ok, "%v", report.Problems)
+		assert.Equal(t, uint64(5000), report.Entries)
+	}
+}
+
+func TestVerifyProblems(t *testing.T) {
+	f, r := fakeDataReader(t, false, false)
+	defer os.Remove(f)
+	orig := append([]byte{}, r.data...)
+
+	corrupt := func(fn func(data []byte)) *VerifyReport {
+		copy(r.data, orig)
+		fn(r.data)
+		report := r.Verify()
+		assert.False(t, report.Ok)
+		return report
+	}
+
+	report := corrupt(func(data []byte) { copy(data[r.Trailer.offset:], "NOTMAGIC") })
+	assert.Equal(t, []string{"trailer"}, verifyChecks(report))
+
+	report = corrupt(func(data []byte) { copy(data[r.index[3].offset:], "NOTMAGIC") })
+	assert.Equal(t, VerifyProblem{"block", 3, "bad data block magic"}, report.Problems[0])
+	// The unreadable block's entries are missing from the totals.
+	assert.Equal(t, []string{"block", "entryCount"}, verifyChecks(report))
+
+	// Swap the first key of block 2 with a key larger than the next one.
+	report = corrupt(func(data []byte) {
+		copy(data[r.index[2].offset+uint64(len(DataMagic))+8:], []byte{0xff, 0xff, 0xff, 0xff})
+	})
+	assert.Equal(t, []string{"index", "order"}, verifyChecks(report))
+	assert.Equal(t, 2, report.Problems[1].Block)
+
+	// Make the last entry of the first block claim a value longer than the block.
+	report = corrupt(func(data []byte) {
+		block, _ := r.readDataBlock(0, nil)
+		starts := r.entryStarts(block)
+		last := int(r.index[0].offset) + len(DataMagic) + starts[len(starts)-1]
+		binary.BigEndian.PutUint32(data[last+4:], 1<<20)
+	})
+	assert.Equal(t, []string{"block", "entryCount"}, verifyChecks(report))
+
+	copy(r.data, orig)
+	r.EntryCount++
+	report = r.Verify()
+	assert.Equal(t, []string{"entryCount"}, verifyChecks(report))
+	r.EntryCount--
+
+	r.index[4].offset = r.index[3].offset
+	report = r.Verify()
+	assert.Equal(t, []string{"index"}, verifyChecks(report))
+	assert.Equal(t, 4, report.Problems[0].Block)
+}
+
+func TestVerifyFile(t *testing.T) {
+	f, _ := fakeDataReader(t, true, false)
+	defer os.Remove(f)
+
+	data, err := ioutil.ReadFile(f)
+	assert.Nil(t, err, err)
+
+	truncated, err := ioutil.TempFile("", "hfile")
+	assert.Nil(t, err, err)
+	defer os.Remove(truncated.Name())
+	truncated.Write(data[:len(data)/2])
+	truncated.Close()
+
+	report := VerifyFile(truncated.Name())
+	assert.False(t, report.Ok)
+	assert.Equal(t, []string{"open"}, verifyChecks(report))
+
+	encoded, err := json.Marshal(report)
+	assert.Nil(t, err, err)
+	var decoded VerifyReport
+	assert.Nil(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, *report, decoded)
+}
+
+func TestVerifyFileUnmapsFile(t *testing.T) {
+	f, _ := fakeDataReader(t, true, false)
+	defer os.Remove(f)
+
+	mapped := func() bool {
+		maps, err := ioutil.ReadFile("/proc/self/maps")
+		if err != nil {
+			t.Skip("can't read mappings:", err)
+		}
+		return bytes.Contains(maps, []byte(f))
+	}
+	if mapped() {
+		t.Skip("file was already mapped")
+	}
+
+	report := VerifyFile(f)
+	assert.True(t, report.Ok, "%v", report.Problems)
+	assert.False(t, mapped(), "file should be unmapped once verified")
+}
@@ -4,6 +4,7 @@ package hfile
 
 import (
 	"bytes"
+	"io"
 )
 
 /*
@@ -31,8 +32,13 @@ Putting that in an unsigned (sane) order:
 hex      | 0x00          0x7F| 0x80    | 0x88    | 0x90     0xff|
 java     | 0             127 | -128    | -120    | -112     -1  |
 meaning: | pos               | neg*    | pos*    | neg          |
+
+If b is too short to hold the whole vint, the returned length is 0.
 */
 func vintAndLen(b []byte) (int, int) {
+	if len(b) < 1 {
+		return 0, 0
+	}
 	first := b[0]
 	count := 1
 	neg := false
@@ -51,6 +57,9 @@ func vintAndLen(b []byte) (int, int) {
 	} else {
 		count = int(0x90-first) + 1
 	}
+	if len(b) < count {
+		return 0, 0
+	}
 
 	ret := 0
 	for i := 1; i < count; i++ {
@@ -98,3 +107,31 @@ func vint(r *bytes.Reader) (int, error) {
 		return ret, nil
 	}
 }
+
+/*
+The inverse of the above: values from -112 to 127 are written as a single byte, anything else as
+a byte for the sign and length, followed by the (possibly inverted) value's big-endian bytes.
+*/
+func writeVint(w io.Writer, i int) (int, error) {
+	if i >= -112 && i <= 127 {
+		return w.Write([]byte{byte(i)})
+	}
+
+	first := -112
+	if i < 0 {
+		i = i ^ -1
+		first = -120
+	}
+
+	count := 0
+	for tmp := i; tmp != 0; tmp = tmp >> 8 {
+		count++
+	}
+
+	buf := make([]byte, count+1)
+	buf[0] = byte(first - count)
+	for j := 1; j <= count; j++ {
+		buf[j] = byte(i >> uint(8*(count-j)))
+	}
+	return w.Write(buf)
+}
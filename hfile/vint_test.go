@@ -60,5 +60,25 @@ func TestVIntErrors(t *testing.T) {
 		truncatedBuf := buf[0 : len(buf)-1]
 		_, err := vint(bytes.NewReader(truncatedBuf))
 		assert.NotNil(t, err)
+
+		_, l := vintAndLen(truncatedBuf)
+		assert.Equal(t, 0, l)
 	}
 }
+
+func TestWriteVInt(t *testing.T) {
+	for _, i := range []int{0, 1, -1, 127, 128, -112, -113, 141, 255, 256, 0x9999, -171, -43776, 1431655765, -286331154} {
+		buf := new(bytes.Buffer)
+		n, err := writeVint(buf, i)
+		assert.Nil(t, err)
+		assert.Equal(t, buf.Len(), n)
+
+		x, l := vintAndLen(buf.Bytes())
+		assert.Equal(t, i, x, "round trip of %d", i)
+		assert.Equal(t, n, l)
+	}
+
+	buf := new(bytes.Buffer)
+	writeVint(buf, 141)
+	assert.Equal(t, fromHex(t, "8f8d"), buf.Bytes())
+}
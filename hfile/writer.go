@@ -6,12 +6,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
-	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
 
-	"github.com/golang/snappy"
+	"github.com/AndreasBriese/bbloom"
 )
 
 type Writer struct {
@@ -28,41 +28,57 @@ type Writer struct {
 	blocks  []Block
 	trailer Trailer
 
+	metaBlocks map[string][]byte
+	metaIndex  []Block
+
+	bloom *bbloom.Bloom
+
+	fileInfo      map[string][]byte
+	totalKeyLen   uint64
+	totalValueLen uint64
+
 	OrderedOps
 }
 
-func NewLocalWriter(path string, compress bool, blockSize int, debug bool) (*Writer, error) {
+func NewLocalWriter(path string, codec uint32, blockSize int, debug bool) (*Writer, error) {
 	fp, err := os.Create(path)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return NewWriter(fp, compress, blockSize, debug)
+	return NewWriter(fp, codec, blockSize, debug)
 }
 
-func NewWriter(out io.WriteCloser, compress bool, blockSize int, debug bool) (*Writer, error) {
+// NewWriter returns a Writer that compresses blocks with codec (e.g. CompressionSnappy).
+func NewWriter(out io.WriteCloser, codec uint32, blockSize int, debug bool) (*Writer, error) {
+	if _, ok := compressionNames[codec]; !ok {
+		return nil, fmt.Errorf("Unsupported compression codec %d", codec)
+	}
+
 	w := new(Writer)
 	w.fp = out
 	w.debug = debug
 	w.blockSizeLimit = blockSize
 	w.OrderedOps = OrderedOps{nil}
+	w.metaBlocks = make(map[string][]byte)
+	w.fileInfo = make(map[string][]byte)
 
-	if compress {
-		w.trailer.CompressionCodec = CompressionSnappy
-	} else {
-		w.trailer.CompressionCodec = CompressionNone
-	}
+	w.trailer.CompressionCodec = codec
 
 	return w, nil
 }
 
 func (w *Writer) Write(k, v []byte) error {
-	w.maybeStartBlock(k)
-
-	if err := w.CheckIfKeyOutOfOrder(k); err != nil {
+	// Checked first, so that a rejected key can't flush a block or become the next one's first key.
+	if err := w.checkKeyOrder(k); err != nil {
+		return err
+	}
+	// Compares k to the previous key, so must come before k is recorded as the last key.
+	if err := w.maybeStartBlock(k); err != nil {
 		return err
 	}
+	w.lastKey = k
 
 	if err := binary.Write(w.curBlockBuf, binary.BigEndian, uint32(len(k))); err != nil {
 		return err
@@ -77,6 +93,11 @@ func (w *Writer) Write(k, v []byte) error {
 		return err
 	}
 	w.trailer.EntryCount += 1
+	w.totalKeyLen += uint64(len(k))
+	w.totalValueLen += uint64(len(v))
+	if w.bloom != nil {
+		w.bloom.Add(k)
+	}
 	return nil
 }
 
@@ -120,6 +141,14 @@ func (w *Writer) Close() error {
 		}
 	}
 
+	if err := w.flushBloom(); err != nil {
+		return err
+	}
+
+	if err := w.flushMetaBlocks(); err != nil {
+		return err
+	}
+
 	if err := w.flushFileInfo(); err != nil {
 		return err
 	}
@@ -151,41 +180,8 @@ func (w *Writer) flushBlock() error {
 	block := Block{w.curOffset, uint32(w.curBlockBuf.Len()), w.curBlockFirstKey}
 	w.trailer.TotalUncompressedDataBytes += uint64(w.curBlockBuf.Len())
 
-	switch w.trailer.CompressionCodec {
-	case CompressionNone:
-		if i, err := w.curBlockBuf.WriteTo(w.fp); err != nil {
-			return err
-		} else {
-			w.curOffset += uint64(i)
-		}
-
-	case CompressionSnappy:
-		fullSz := uint32(w.curBlockBuf.Len())
-		if err := binary.Write(w.fp, binary.BigEndian, fullSz); err != nil {
-			return err
-		} else {
-			w.curOffset += uint64(binary.Size(fullSz))
-		}
-		compressed := snappy.Encode(nil, w.curBlockBuf.Bytes())
-		if w.debug {
-			log.Printf("[Writer.flushBlock] compressed block %d (%db -> %db)", len(w.blocks), w.curBlockBuf.Len(), len(compressed))
-		}
-
-		compressedSz := uint32(len(compressed))
-		if err := binary.Write(w.fp, binary.BigEndian, compressedSz); err != nil {
-			return err
-		} else {
-			w.curOffset += uint64(binary.Size(compressedSz))
-		}
-
-		if i, err := w.fp.Write(compressed); err != nil {
-			return err
-		} else {
-			w.curOffset += uint64(i)
-		}
-
-	default:
-		return errors.New("Unsupported compression codec " + string(w.trailer.CompressionCodec))
+	if err := w.writeBlock(w.curBlockBuf); err != nil {
+		return err
 	}
 
 	w.blocks = append(w.blocks, block)
@@ -193,20 +189,36 @@ func (w *Writer) flushBlock() error {
 	return nil
 }
 
-func writeUvarint(fp io.Writer, i uint64) (int, error) {
-	buf := make([]byte, 16)
-	l := binary.PutUvarint(buf, uint64(i))
-	return fp.Write(buf[0:l])
+// writeBlock writes buf, compressed with the file's codec, at the current offset.
+func (w *Writer) writeBlock(buf *bytes.Buffer) error {
+	data, err := compress(w.trailer.CompressionCodec, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if w.debug && w.trailer.CompressionCodec != CompressionNone {
+		log.Printf("[Writer.writeBlock] compressed block (%db -> %db)", buf.Len(), len(data))
+	}
+
+	if i, err := w.fp.Write(data); err != nil {
+		return err
+	} else {
+		w.curOffset += uint64(i)
+	}
+	return nil
 }
 
 func (w *Writer) flushIndex() error {
 	w.trailer.DataIndexOffset = w.curOffset
 	w.trailer.DataIndexCount = uint32(len(w.blocks))
+	return w.writeIndex(w.blocks)
+}
 
+// writeIndex writes an index of blocks (data or meta), each as offset, size and vint-prefixed key.
+func (w *Writer) writeIndex(blocks []Block) error {
 	w.fp.Write(IndexMagic)
 	w.curOffset += uint64(len(IndexMagic))
 
-	for _, b := range w.blocks {
+	for _, b := range blocks {
 		if err := binary.Write(w.fp, binary.BigEndian, b.offset); err != nil {
 			return err
 		}
@@ -217,7 +229,7 @@ func (w *Writer) flushIndex() error {
 		}
 		w.curOffset += uint64(binary.Size(b.size))
 
-		if i, err := writeUvarint(w.fp, uint64(len(b.firstKeyBytes))); err != nil {
+		if i, err := writeVint(w.fp, len(b.firstKeyBytes)); err != nil {
 			return err
 		} else {
 			w.curOffset += uint64(i)
@@ -235,14 +247,21 @@ func (w *Writer) flushIndex() error {
 
 func (w *Writer) flushFileInfo() error {
 	w.trailer.FileInfoOffset = w.curOffset
-	//TODO(davidt): support file info
+	if i, err := writeFileInfo(w.fp, w.standardFileInfo()); err != nil {
+		return err
+	} else {
+		w.curOffset += uint64(i)
+	}
 	return nil
 }
 
 func (w *Writer) flushMetaIndex() error {
 	w.trailer.MetaIndexOffset = w.curOffset
-	w.trailer.MetaIndexCount = uint32(0)
-	return nil
+	w.trailer.MetaIndexCount = uint32(len(w.metaIndex))
+	if len(w.metaIndex) == 0 {
+		return nil
+	}
+	return w.writeIndex(w.metaIndex)
 }
 
 func (w *Writer) flushTrailer() error {
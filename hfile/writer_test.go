@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +16,11 @@ func tempHfile(t *testing.T, compress bool, blockSize int, keys [][]byte, values
 	fp, err := ioutil.TempFile("", "demohfile")
 	assert.Nil(t, err, "error creating tempfile:", err)
 
-	w, err := NewWriter(fp, compress, blockSize, false)
+	codec := CompressionNone
+	if compress {
+		codec = CompressionSnappy
+	}
+	w, err := NewWriter(fp, codec, blockSize, false)
 	assert.Nil(t, err, "error creating writer:", err)
 
 	for i, _ := range keys {
@@ -117,3 +122,318 @@ func TestBigRoundTripCompressed(t *testing.T) {
 	assert.True(t, found, "not found")
 	assert.True(t, bytes.Equal(v, valI(501)), "bad value", v, valI(501))
 }
+
+// A WriteCloser that discards what is written to it.
+type discardCloser struct{ bytes.Buffer }
+
+func (discardCloser) Close() error { return nil }
+
+func TestWriteRejectsKeyWithoutFlushing(t *testing.T) {
+	w, err := NewWriter(&discardCloser{}, CompressionNone, 16, false)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Write(keyI(5), valI(5)))
+
+	// The block is now full, but an out-of-order key shouldn't flush it or start the next one.
+	assert.NotNil(t, w.Write(keyI(1), valI(1)))
+	assert.Empty(t, w.blocks)
+	assert.Equal(t, keyI(5), w.curBlockFirstKey)
+
+	assert.Nil(t, w.Write(keyI(7), valI(7)))
+	if assert.Len(t, w.blocks, 1) {
+		assert.Equal(t, keyI(5), w.blocks[0].firstKeyBytes)
+	}
+	assert.Equal(t, keyI(7), w.curBlockFirstKey)
+	assert.Equal(t, uint32(2), w.trailer.EntryCount)
+}
+
+func TestWriteReturnsFlushErrors(t *testing.T) {
+	w, err := NewWriter(&discardCloser{}, CompressionNone, 16, false)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Write(keyI(1), valI(1)))
+
+	// Unsupported codecs are rejected up front, so break the writer's to make compressing fail.
+	w.trailer.CompressionCodec = 99
+	assert.NotNil(t, w.Write(keyI(2), valI(2)), "flushing the full block should fail")
+	assert.Equal(t, uint32(1), w.trailer.EntryCount, "the pair should not have been written")
+}
+
+func TestWriteFileInfo(t *testing.T) {
+	fp, err := ioutil.TempFile("", "demohfile")
+	assert.Nil(t, err, "error creating tempfile:", err)
+	defer os.Remove(fp.Name())
+
+	w, err := NewWriter(fp, CompressionSnappy, 4096, false)
+	assert.Nil(t, err, "error creating writer:", err)
+
+	assert.Nil(t, w.AppendFileInfo("custom", []byte("value")))
+	assert.NotNil(t, w.AppendFileInfo(FileInfoLastKey, []byte("nope")), "reserved keys should be rejected")
+
+	before := time.Now().Add(-time.Second)
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, w.Write(keyI(i), []byte("v")))
+	}
+	assert.Nil(t, w.Close())
+
+	r, err := NewReader("demo", fp.Name(), CopiedToMem, false)
+	assert.Nil(t, err, "error creating reader:", err)
+
+	v, ok := r.InfoBytes("custom")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), v)
+	assert.Equal(t, "value", r.InfoFields["custom"])
+
+	last, ok := r.InfoBytes(FileInfoLastKey)
+	assert.True(t, ok)
+	assert.Equal(t, keyI(99), last)
+
+	l, ok := r.AvgKeyLen()
+	assert.True(t, ok)
+	assert.Equal(t, len(keyI(0)), l)
+
+	l, ok = r.AvgValueLen()
+	assert.True(t, ok)
+	assert.Equal(t, 1, l)
+
+	c, ok := r.Comparator()
+	assert.True(t, ok)
+	assert.Equal(t, rawBytesComparator, c)
+
+	created, ok := r.CreateTime()
+	assert.True(t, ok)
+	assert.True(t, created.After(before), "bad create time %v", created)
+
+	_, ok = r.InfoInt("custom")
+	assert.False(t, ok, "5-byte values are not ints")
+
+	s := NewScanner(r)
+	found, err, ok := s.GetFirst(keyI(42))
+	assert.Nil(t, err, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v"), found)
+}
+
+func TestLastKey(t *testing.T) {
+	keys, vals := make([][]byte, 1000), make([][]byte, 1000)
+	for i := range keys {
+		keys[i], vals[i] = keyI(i), valI(i)
+	}
+	for _, compress := range []bool{false, true} {
+		f, s := tempHfile(t, compress, 512, keys, vals)
+		defer os.Remove(f)
+		r := s.reader
+
+		last, err := r.LastKey()
+		assert.Nil(t, err, err)
+		assert.Equal(t, keyI(999), last)
+
+		// Without a recorded last key, it is found by reading the final block.
+		delete(r.InfoRaw, FileInfoLastKey)
+		last, err = r.LastKey()
+		assert.Nil(t, err, err)
+		assert.Equal(t, keyI(999), last)
+
+		assert.True(t, r.NumBlocks() > 1, "expected multiple blocks")
+		assert.Equal(t, r.FileInfoOffset, r.CompressedDataBytes())
+		if !compress {
+			assert.Equal(t, r.TotalUncompressedDataBytes, r.CompressedDataBytes())
+		}
+	}
+}
+
+func TestLongKeysRoundTrip(t *testing.T) {
+	var keys, vals [][]byte
+	for i := 0; i < 50; i++ {
+		keys = append(keys, append(bytes.Repeat([]byte{'k'}, 200), keyI(i)...))
+		vals = append(vals, valI(i))
+	}
+
+	f, s := tempHfile(t, false, 1024, keys, vals)
+	defer os.Remove(f)
+
+	assert.True(t, len(s.reader.index) > 1, "expected multiple blocks")
+	for i := range keys {
+		v, err, found := s.GetFirst(keys[i])
+		assert.Nil(t, err, err)
+		assert.True(t, found, "key %d not found", i)
+		assert.Equal(t, vals[i], v)
+	}
+}
+
+func TestMetaBlocks(t *testing.T) {
+	for _, codec := range []uint32{CompressionNone, CompressionSnappy} {
+		fp, err := ioutil.TempFile("", "demohfile")
+		assert.Nil(t, err, "error creating tempfile:", err)
+		defer os.Remove(fp.Name())
+
+		w, err := NewWriter(fp, codec, 4096, false)
+		assert.Nil(t, err, "error creating writer:", err)
+
+		assert.Nil(t, w.AppendMetaBlock("schema", []byte("k:int,v:string")))
+		assert.Nil(t, w.AppendMetaBlock("lineage", bytes.Repeat([]byte("abc"), 1000)))
+		assert.Nil(t, w.AppendMetaBlock("empty", nil))
+		assert.NotNil(t, w.AppendMetaBlock("schema", []byte("again")), "duplicate names should be rejected")
+		assert.NotNil(t, w.AppendMetaBlock("", []byte("x")), "empty names should be rejected")
+
+		for i := 0; i < 1000; i++ {
+			assert.Nil(t, w.Write(keyI(i), valI(i)))
+		}
+		assert.Nil(t, w.Close())
+
+		r, err := NewReader("demo", fp.Name(), CopiedToMem, false)
+		assert.Nil(t, err, "error creating reader:", err)
+
+		assert.Equal(t, []string{"empty", "lineage", "schema"}, r.MetaBlockNames())
+
+		v, found, err := r.MetaBlock("schema")
+		assert.Nil(t, err, err)
+		assert.True(t, found)
+		assert.Equal(t, []byte("k:int,v:string"), v)
+
+		v, found, err = r.MetaBlock("lineage")
+		assert.Nil(t, err, err)
+		assert.True(t, found)
+		assert.Equal(t, bytes.Repeat([]byte("abc"), 1000), v)
+
+		v, found, err = r.MetaBlock("empty")
+		assert.Nil(t, err, err)
+		assert.True(t, found)
+		assert.Len(t, v, 0)
+
+		_, found, err = r.MetaBlock("missing")
+		assert.Nil(t, err, err)
+		assert.False(t, found)
+
+		s := NewScanner(r)
+		for _, i := range []int{0, 500, 999} {
+			v, err, found := s.GetFirst(keyI(i))
+			assert.Nil(t, err, err)
+			assert.True(t, found, "key %d not found", i)
+			assert.Equal(t, valI(i), v)
+		}
+
+		last, ok := r.InfoBytes(FileInfoLastKey)
+		assert.True(t, ok)
+		assert.Equal(t, keyI(999), last)
+	}
+}
+
+func TestPrecomputedBloom(t *testing.T) {
+	fp, err := ioutil.TempFile("", "demohfile")
+	assert.Nil(t, err, "error creating tempfile:", err)
+	defer os.Remove(fp.Name())
+
+	w, err := NewWriter(fp, CompressionSnappy, 4096, false)
+	assert.Nil(t, err, "error creating writer:", err)
+	w.EnableBloom(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		assert.Nil(t, w.Write(keyI(i*2), valI(i*2)))
+	}
+	assert.Nil(t, w.Close())
+
+	r, err := NewReader("demo", fp.Name(), CopiedToMem, false)
+	assert.Nil(t, err, "error creating reader:", err)
+	assert.True(t, r.HasBloom(), "bloom should have been loaded")
+
+	falsePos := 0
+	for i := 0; i < 1000; i++ {
+		assert.True(t, r.MightContain(keyI(i*2)), "bloom rejected key %d", i*2)
+		if r.MightContain(keyI(i*2 + 1)) {
+			falsePos++
+		}
+	}
+	assert.True(t, falsePos < 50, "too many false positives: %d", falsePos)
+
+	// Files without a stored filter still work, and need one to be calculated.
+	f, s := tempHfile(t, false, 4096, [][]byte{keyI(1)}, [][]byte{valI(1)})
+	defer os.Remove(f)
+	assert.False(t, s.reader.HasBloom())
+	assert.Nil(t, s.reader.CalculateBloom(0.01))
+	assert.True(t, s.reader.HasBloom())
+	assert.True(t, s.reader.MightContain(keyI(1)))
+}
+
+func TestBadPrecomputedBloom(t *testing.T) {
+	fp, err := ioutil.TempFile("", "demohfile")
+	assert.Nil(t, err, "error creating tempfile:", err)
+	defer os.Remove(fp.Name())
+
+	w, err := NewWriter(fp, CompressionNone, 4096, false)
+	assert.Nil(t, err, "error creating writer:", err)
+	assert.Nil(t, w.AppendMetaBlock(BloomMetaBlock, []byte(`{"FilterSet":"","SetLocs":3}`)))
+	assert.Nil(t, w.Write(keyI(1), valI(1)))
+	assert.Nil(t, w.Close())
+
+	r, err := NewReader("demo", fp.Name(), CopiedToMem, false)
+	assert.Nil(t, err, "a bad bloom filter should not prevent opening the file")
+	assert.False(t, r.HasBloom(), "bad bloom filter should be ignored")
+	assert.True(t, r.MightContain(keyI(1)))
+}
+
+func TestCodecsRoundTrip(t *testing.T) {
+	for _, name := range []string{"none", "snappy", "lz4", "gzip", "zstd"} {
+		codec, err := ParseCompressionCodec(name)
+		assert.Nil(t, err, err)
+
+		fp, err := ioutil.TempFile("", "demohfile")
+		assert.Nil(t, err, "error creating tempfile:", err)
+		defer os.Remove(fp.Name())
+
+		w, err := NewWriter(fp, codec, 4096, false)
+		assert.Nil(t, err, "error creating writer:", err)
+		for i := 0; i < 10000; i++ {
+			assert.Nil(t, w.Write(keyI(i), valI(i)))
+		}
+		// A value bigger than a BlockCompressorStream chunk, which compresses very well.
+		big := bytes.Repeat([]byte("abcdefgh"), 20000)
+		assert.Nil(t, w.Write(keyI(10000), big))
+		assert.Nil(t, w.AppendMetaBlock("meta", []byte("some meta")))
+		assert.Nil(t, w.Close())
+
+		r, err := NewReader("demo", fp.Name(), CopiedToMem, false)
+		assert.Nil(t, err, "error creating reader:", err)
+		assert.Equal(t, codec, r.CompressionCodec)
+
+		s := NewScanner(r)
+		for _, i := range []int{0, 1, 5000, 9999} {
+			v, err, found := s.GetFirst(keyI(i))
+			assert.Nil(t, err, err)
+			assert.True(t, found, "key %d not found (%s)", i, name)
+			assert.Equal(t, valI(i), v)
+		}
+		v, err, found := s.GetFirst(keyI(10000))
+		assert.Nil(t, err, err)
+		assert.True(t, found, "big value not found (%s)", name)
+		assert.Equal(t, big, v)
+
+		meta, _, err := r.MetaBlock("meta")
+		assert.Nil(t, err, err)
+		assert.Equal(t, []byte("some meta"), meta)
+	}
+
+	_, err := ParseCompressionCodec("lzo")
+	assert.NotNil(t, err, "lzo is not supported")
+	_, err = NewWriter(nil, 5, 4096, false)
+	assert.NotNil(t, err, "bzip2 is not supported")
+}
+
+func TestDecodeLz4(t *testing.T) {
+	for _, src := range [][]byte{
+		[]byte("a"),
+		bytes.Repeat([]byte("a"), 1000),
+		bytes.Repeat([]byte("abc"), 1000),
+		append(bytes.Repeat([]byte("xyz"), 100), []byte("and some unrepeated text at the end")...),
+	} {
+		compressed, err := encodeLz4(src)
+		assert.Nil(t, err, err)
+
+		dst, err := decodeLz4(make([]byte, len(src)), compressed)
+		assert.Nil(t, err, err)
+		assert.Equal(t, src, dst)
+
+		_, err = decodeLz4(make([]byte, len(src)-1), compressed)
+		assert.NotNil(t, err, "dst too small should be an error")
+	}
+
+	_, err := decodeLz4(make([]byte, 100), []byte{0x1f, 'a', 0x05, 0x00})
+	assert.NotNil(t, err, "offset past start should be an error")
+}
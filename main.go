@@ -9,8 +9,10 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
 	"strconv"
+	"syscall"
 	"time"
 
 	_ "expvar"
@@ -40,10 +42,16 @@ type SettingDefs struct {
 
 	bloom int
 
+	blockCacheMb int
+
+	maxSplitKeyProduct int
+
 	mlock  bool
 	onDisk bool
 
 	configJsonUrl string
+	configPoll    time.Duration
+	configZkNode  string
 
 	cachePath string
 
@@ -62,7 +70,11 @@ func readSettings() []string {
 
 	flag.BoolVar(&s.debug, "debug", false, "print more output")
 
-	flag.IntVar(&s.bloom, "bloom", 0, "bloom filter wrong-positive % (or 0 to disable): lower numbers use more RAM but filter more queries.")
+	flag.IntVar(&s.bloom, "bloom", 0, "bloom filter wrong-positive % for collections without a precomputed filter (or 0 to not calculate them): lower numbers use more RAM but filter more queries.")
+
+	flag.IntVar(&s.blockCacheMb, "block-cache-mb", 0, "mb of decompressed blocks to cache per compressed collection (or 0 to disable), unless set in the json config.")
+
+	flag.IntVar(&s.maxSplitKeyProduct, "max-split-key-product", 100000, "max keys a getValuesMultiSplitKeys request may expand to (or 0 for no limit).")
 
 	flag.BoolVar(&s.downloadOnly, "download-only", false, "exit after downloading remote files to local cache.")
 
@@ -71,6 +83,8 @@ func readSettings() []string {
 	flag.BoolVar(&s.mlock, "mlock", false, "mlock mapped files in memory rather than copy to heap.")
 
 	flag.StringVar(&s.configJsonUrl, "config-json", "", "URL of collection configuration json")
+	flag.DurationVar(&s.configPoll, "config-poll", 0, "re-fetch -config-json this often, reloading collections if it changed (or 0 to not poll)")
+	flag.StringVar(&s.configZkNode, "config-zk-node", "", "zookeeper node to watch, re-fetching -config-json and reloading collections whenever it changes")
 
 	flag.StringVar(&s.cachePath, "cache", os.TempDir(), "local path to write files fetched (*not* cleaned up automatically)")
 
@@ -96,6 +110,12 @@ Usage: %s [options] col1=path1 col2=path2 ...
 		os.Exit(-1)
 	}
 
+	if (Settings.configPoll > 0 || Settings.configZkNode != "") && Settings.configJsonUrl == "" {
+		log.Println("-config-poll and -config-zk-node require -config-json.")
+		flag.Usage()
+		os.Exit(-1)
+	}
+
 	return flag.Args()
 }
 
@@ -124,7 +144,10 @@ func main() {
 		defer registrations.Close()
 	}
 
-	configs := getCollectionConfig(args)
+	configs, err := getCollectionConfig(args)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	log.Println("Loading collections...")
 
@@ -141,16 +164,18 @@ func main() {
 
 	if Settings.bloom > 0 {
 		beforeBloom := time.Now()
-		for _, c := range cs.Collections {
-			log.Println("Calculating bloom filter for", c.Name)
-			c.CalculateBloom(float64(Settings.bloom) / 100)
-		}
+		calculateBlooms(cs.Collections)
 		stats.TimeSince("startup.bloom", beforeBloom)
 	}
 
 	log.Printf("Serving on http://%s:%d/ \n", hostname, Settings.port)
 
-	http.Handle("/rpc/HFileService", WrapHttpRpcHandler(cs, stats))
+	shared := NewRpcShared(cs)
+	reloader := NewReloader(shared, args, configs, stats)
+
+	http.Handle("/rpc/HFileService", WrapHttpRpcHandler(shared, stats))
+	http.Handle("/v1/", RestHandler(shared))
+	http.Handle("/debug/reload", reloader)
 
 	admin := adminz.New()
 	admin.KillfilePaths(adminz.Killfiles(Settings.port))
@@ -161,32 +186,65 @@ func main() {
 			Impl           string                   `json:"implementation"`
 			QuiverVersion  string                   `json:"quiver_version"`
 			PackageVersion string                   `json:"package_version"`
+			LastReload     *ReloadResult            `json:"last_reload,omitempty"`
 		}{
-			cs.Collections,
+			shared.CollectionSet().Collections,
 			"quiver",
 			version,
 			Settings.packageVersion,
+			reloader.Last(),
 		}
 	})
 
 	admin.OnPause(registrations.Leave)
 	admin.OnResume(func() {
 		if Settings.discoveryPath != "" {
-			registrations.Join(hostname, Settings.discoveryPath, configs, 0)
+			registrations.Join(hostname, Settings.discoveryPath, reloader.Configs(), 0)
 		}
 	})
+	if Settings.discoveryPath != "" {
+		reloader.OnReload = registrations.Update
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if !reloader.ReloadInBackground() {
+				log.Println("Ignoring SIGHUP:", errReloadInProgress)
+			}
+		}
+	}()
+
+	if Settings.configPoll > 0 || Settings.configZkNode != "" {
+		watcher := NewConfigWatcher(Settings.configJsonUrl, reloader)
+		if Settings.configPoll > 0 {
+			go watcher.Poll(Settings.configPoll)
+		}
+		if Settings.configZkNode != "" {
+			zk := registrations.zk
+			if zk == nil {
+				if Settings.zk == "" {
+					log.Fatal("Specified -config-zk-node but not zk?")
+				}
+				zk = connectZookeeper()
+				defer zk.Close()
+			}
+			go watcher.Watch(zk, Settings.configZkNode)
+		}
+	}
 
 	http.HandleFunc("/hfilez", admin.ServicezHandler)
 	http.HandleFunc("/", admin.ServicezHandler)
 
 	http.HandleFunc("/debug/bloom/enable", func(w http.ResponseWriter, r *http.Request) {
-		for _, c := range cs.Collections {
+		for _, c := range shared.CollectionSet().Collections {
 			c.EnableBloom()
 		}
 	})
 
 	http.HandleFunc("/debug/bloom/disable", func(w http.ResponseWriter, r *http.Request) {
-		for _, c := range cs.Collections {
+		for _, c := range shared.CollectionSet().Collections {
 			c.DisableBloom()
 		}
 	})
@@ -199,7 +257,7 @@ func main() {
 		} else {
 			admin.Pause()
 			defer admin.Resume()
-			for _, c := range cs.Collections {
+			for _, c := range shared.CollectionSet().Collections {
 				fmt.Fprintln(w, "Recalculating bloom for", c.Name)
 				c.CalculateBloom(float64(falsePos) / 100)
 			}
@@ -213,7 +271,7 @@ func main() {
 	stats.TimeSince("startup.total", t)
 
 	if Settings.rpcPort > 0 {
-		s, err := NewTRpcServer(fmt.Sprintf(":%d", Settings.rpcPort), WrapProcessor(cs, stats), thrift.NewTBinaryProtocolFactory(true, true))
+		s, err := NewTRpcServer(fmt.Sprintf(":%d", Settings.rpcPort), WrapProcessor(shared, stats), thrift.NewTBinaryProtocolFactory(true, true))
 		if err != nil {
 			log.Fatalln("Could not open RPC port", Settings.rpcPort, err)
 		} else {
@@ -234,7 +292,7 @@ func main() {
 			log.Fatalf("failed to listen on gRPC port %d: %v", Settings.grpcPort, err)
 		}
 		s := grpc.NewServer()
-		pb.RegisterQuiverServiceServer(s, &GrpcImpl{&RpcShared{cs}})
+		pb.RegisterQuiverServiceServer(s, &GrpcImpl{shared})
 		reflection.Register(s)
 		go func() {
 			log.Fatalln(s.Serve(lis))
@@ -244,3 +302,18 @@ func main() {
 
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", Settings.port), nil))
 }
+
+// calculateBlooms calculates bloom filters, if enabled, for the collections without a precomputed one.
+func calculateBlooms(collections map[string]*hfile.Reader) {
+	if Settings.bloom < 1 {
+		return
+	}
+	for _, c := range collections {
+		if c.HasBloom() {
+			log.Println("Using precomputed bloom filter for", c.Name)
+			continue
+		}
+		log.Println("Calculating bloom filter for", c.Name)
+		c.CalculateBloom(float64(Settings.bloom) / 100)
+	}
+}
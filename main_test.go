@@ -14,7 +14,7 @@ import (
 )
 
 func DummyServer(t hasFatal, handler *ThriftRpcImpl) *httptest.Server {
-	return httptest.NewServer(WrapHttpRpcHandler(handler.CollectionSet, nil))
+	return httptest.NewServer(WrapHttpRpcHandler(handler.RpcShared, nil))
 }
 
 func DummyClient(url string, compact bool) *gen.HFileServiceClient {
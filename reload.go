@@ -0,0 +1,318 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/foursquare/fsgo/report"
+	"github.com/foursquare/quiver/hfile"
+)
+
+// How long a replaced or removed collection stays loaded after its last scanner or iterator is released,
+// for responses still being written from it.
+const defaultUnloadGrace = 30 * time.Second
+
+var errReloadInProgress = errors.New("a reload is already in progress")
+
+/*
+A Reloader re-reads the collection config and swaps new and changed collections into an RpcShared
+while it keeps serving: collections are loaded in the background, then swapped in all at once.
+Requests already running finish with the collections they started with, and replaced or removed
+collections are unloaded once nothing is using them. Unchanged collections are kept as they are,
+along with their bloom filters.
+*/
+type Reloader struct {
+	shared *RpcShared
+	args   []string
+	stats  *report.Recorder
+	grace  time.Duration
+
+	// If set, called with the new configs after each reload that changes them (e.g. to update discovery).
+	OnReload func([]*hfile.CollectionConfig)
+
+	// 1 while reloading, so only one reload runs at a time.
+	busy int32
+
+	// Guards configs, loaded and last, which are only changed while reloading.
+	sync.Mutex
+	configs []*hfile.CollectionConfig
+	loaded  map[string]loadedCollection
+	last    *ReloadResult
+}
+
+// A loadedCollection records how a served collection was configured, to tell if a reload changes it.
+type loadedCollection struct {
+	cfg     hfile.CollectionConfig
+	version fileVersion
+}
+
+// The modification time and size of a local file, or zero for remote (or missing) ones.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func statSource(path string) fileVersion {
+	if fi, err := os.Stat(path); err == nil {
+		return fileVersion{fi.ModTime(), fi.Size()}
+	}
+	return fileVersion{}
+}
+
+// changed reports if cfg, whose source is currently at version v, needs to be loaded again.
+func (c loadedCollection) changed(cfg *hfile.CollectionConfig, v fileVersion) bool {
+	return c.cfg.SourcePath != cfg.SourcePath ||
+		c.cfg.LoadMethod != cfg.LoadMethod ||
+		c.cfg.BlockCacheSize != cfg.BlockCacheSize ||
+		c.cfg.Debug != cfg.Debug ||
+		c.cfg.ParentName != cfg.ParentName ||
+		c.cfg.ShardFunction != cfg.ShardFunction ||
+		c.cfg.Partition != cfg.Partition ||
+		c.cfg.TotalPartitions != cfg.TotalPartitions ||
+		!c.version.modTime.Equal(v.modTime) ||
+		c.version.size != v.size
+}
+
+// A ReloadResult lists the collections a reload loaded or unloaded.
+type ReloadResult struct {
+	Time      time.Time `json:"time"`
+	Added     []string  `json:"added"`
+	Changed   []string  `json:"changed"`
+	Removed   []string  `json:"removed"`
+	Unchanged int       `json:"unchanged"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NewReloader returns a Reloader for shared, which is serving configs, that re-reads the config from args
+// (or -config-json).
+func NewReloader(shared *RpcShared, args []string, configs []*hfile.CollectionConfig, stats *report.Recorder) *Reloader {
+	rl := &Reloader{
+		shared:  shared,
+		args:    args,
+		stats:   stats,
+		grace:   defaultUnloadGrace,
+		configs: configs,
+		loaded:  make(map[string]loadedCollection, len(configs)),
+	}
+	for _, cfg := range configs {
+		rl.loaded[cfg.Name] = loadedCollection{*cfg, statSource(cfg.SourcePath)}
+	}
+	return rl
+}
+
+// Configs returns the configs of the collections currently being served.
+func (rl *Reloader) Configs() []*hfile.CollectionConfig {
+	rl.Lock()
+	defer rl.Unlock()
+	return rl.configs
+}
+
+// Last returns the result of the most recent reload, or nil if there hasn't been one.
+func (rl *Reloader) Last() *ReloadResult {
+	rl.Lock()
+	defer rl.Unlock()
+	return rl.last
+}
+
+// Reload re-reads the config and applies it, returning an error if it can't or if a reload is already running.
+func (rl *Reloader) Reload() (*ReloadResult, error) {
+	if !atomic.CompareAndSwapInt32(&rl.busy, 0, 1) {
+		return nil, errReloadInProgress
+	}
+	return rl.reload(rl.readConfig)
+}
+
+// Apply is like Reload, but serves configs rather than re-reading the config.
+func (rl *Reloader) Apply(configs []*hfile.CollectionConfig) (*ReloadResult, error) {
+	if !atomic.CompareAndSwapInt32(&rl.busy, 0, 1) {
+		return nil, errReloadInProgress
+	}
+	return rl.reload(func() ([]*hfile.CollectionConfig, error) { return configs, nil })
+}
+
+// ReloadInBackground starts a reload, unless one is already running, and reports whether it did.
+func (rl *Reloader) ReloadInBackground() bool {
+	if !atomic.CompareAndSwapInt32(&rl.busy, 0, 1) {
+		return false
+	}
+	go rl.reload(rl.readConfig)
+	return true
+}
+
+func (rl *Reloader) readConfig() ([]*hfile.CollectionConfig, error) {
+	return getCollectionConfig(rl.args)
+}
+
+func (rl *Reloader) reload(getConfigs func() ([]*hfile.CollectionConfig, error)) (*ReloadResult, error) {
+	defer atomic.StoreInt32(&rl.busy, 0)
+	t := time.Now()
+
+	log.Println("[Reload] Reloading collection config...")
+	res, err := rl.apply(t, getConfigs)
+	if err != nil {
+		log.Println("[Reload] Failed, still serving the previous collections:", err)
+		res = &ReloadResult{Time: t, Error: err.Error()}
+	} else {
+		log.Printf("[Reload] Added %d, changed %d and removed %d collections (%d unchanged) in %s.\n",
+			len(res.Added), len(res.Changed), len(res.Removed), res.Unchanged, time.Since(t))
+	}
+
+	rl.Lock()
+	rl.last = res
+	rl.Unlock()
+
+	if rl.stats != nil {
+		rl.stats.TimeSince("reload.total", t)
+		if err != nil {
+			rl.stats.Inc("reload.failed")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// apply loads the collections the config adds or changes and, if all of them load, swaps them in.
+func (rl *Reloader) apply(t time.Time, getConfigs func() ([]*hfile.CollectionConfig, error)) (*ReloadResult, error) {
+	configs, err := getConfigs()
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) < 1 {
+		return nil, fmt.Errorf("no collections configured")
+	}
+
+	current := rl.shared.CollectionSet().Collections
+	res := &ReloadResult{Time: t, Added: []string{}, Changed: []string{}, Removed: []string{}}
+
+	rl.Lock()
+	loaded := rl.loaded
+	rl.Unlock()
+
+	versions := make(map[string]fileVersion, len(configs))
+	var load []*hfile.CollectionConfig
+	for _, cfg := range configs {
+		if _, ok := versions[cfg.Name]; ok {
+			return nil, fmt.Errorf("collection %s is configured more than once", cfg.Name)
+		}
+		v := statSource(cfg.SourcePath)
+		versions[cfg.Name] = v
+
+		prev, ok := loaded[cfg.Name]
+		if _, served := current[cfg.Name]; !ok || !served {
+			res.Added = append(res.Added, cfg.Name)
+		} else if prev.changed(cfg, v) {
+			res.Changed = append(res.Changed, cfg.Name)
+		} else {
+			res.Unchanged++
+			continue
+		}
+		load = append(load, cfg)
+	}
+	for name := range current {
+		if _, ok := versions[name]; !ok {
+			res.Removed = append(res.Removed, name)
+		}
+	}
+	sort.Strings(res.Added)
+	sort.Strings(res.Changed)
+	sort.Strings(res.Removed)
+
+	if len(load) == 0 && len(res.Removed) == 0 {
+		return res, nil
+	}
+
+	next := &hfile.CollectionSet{Collections: make(map[string]*hfile.Reader, len(configs))}
+	if len(load) > 0 {
+		log.Printf("[Reload] Loading %d new or changed collections...\n", len(load))
+		fresh, err := hfile.LoadCollections(load, Settings.cachePath, false, rl.stats)
+		if err != nil {
+			return nil, err
+		}
+		calculateBlooms(fresh.Collections)
+		next = fresh
+	}
+	for _, cfg := range configs {
+		if _, ok := next.Collections[cfg.Name]; !ok {
+			next.Collections[cfg.Name] = current[cfg.Name]
+		}
+	}
+
+	prev := rl.shared.swap(next)
+	for name, r := range prev.Collections {
+		if next.Collections[name] != r {
+			go rl.unload(name, r)
+		}
+	}
+
+	rl.Lock()
+	updated := make(map[string]loadedCollection, len(configs))
+	for _, cfg := range configs {
+		if l, ok := loaded[cfg.Name]; ok && next.Collections[cfg.Name] == current[cfg.Name] {
+			updated[cfg.Name] = l
+		} else {
+			updated[cfg.Name] = loadedCollection{*cfg, versions[cfg.Name]}
+		}
+	}
+	rl.loaded = updated
+	rl.configs = configs
+	rl.Unlock()
+
+	if rl.OnReload != nil {
+		rl.OnReload(configs)
+	}
+	return res, nil
+}
+
+func (rl *Reloader) unload(name string, r *hfile.Reader) {
+	if err := r.Unload(rl.grace); err != nil {
+		log.Printf("[Reload] Error unloading %s: %v\n", name, err)
+		return
+	}
+	log.Printf("[Reload] Unloaded the previous %s.\n", name)
+}
+
+/*
+ServeHTTP starts a reload in response to a POST, replying 202 Accepted, or 409 Conflict if a reload
+is already running. With ?wait=true, it instead waits for the reload and replies with its result.
+*/
+func (rl *Reloader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "reloading requires a POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if wait, _ := strconv.ParseBool(r.URL.Query().Get("wait")); !wait {
+		if !rl.ReloadInBackground() {
+			http.Error(w, errReloadInProgress.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "Reloading collections in the background.")
+		return
+	}
+
+	res, err := rl.Reload()
+	if err == errReloadInProgress {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,162 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/foursquare/quiver/hfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func reloadTestFiles(t *testing.T, names ...string) string {
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := hfile.GenerateMockHfile(filepath.Join(dir, name), 1000, 1024*4, false, false, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newTestReloader(t *testing.T, args ...string) *Reloader {
+	configs, err := ConfigsFromCommandline(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := hfile.LoadCollections(configs, "", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl := NewReloader(NewRpcShared(cs), args, configs, nil)
+	rl.grace = 0
+	return rl
+}
+
+func TestReload(t *testing.T) {
+	dir := reloadTestFiles(t, "a", "b", "c")
+	defer os.RemoveAll(dir)
+	a, b, c := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")
+
+	rl := newTestReloader(t, "a="+a, "b="+b)
+	var notified []*hfile.CollectionConfig
+	rl.OnReload = func(configs []*hfile.CollectionConfig) { notified = configs }
+
+	before := rl.shared.CollectionSet().Collections
+	oldA, oldB := before["a"], before["b"]
+
+	// A scanner from the old b keeps working after b is replaced, until it's released.
+	s := oldB.GetScanner()
+
+	res, err := rl.Reload()
+	assert.Nil(t, err)
+	assert.Empty(t, res.Added)
+	assert.Empty(t, res.Changed)
+	assert.Empty(t, res.Removed)
+	assert.Equal(t, 2, res.Unchanged)
+	assert.Nil(t, notified, "nothing changed")
+
+	rl.args = []string{"a=" + a, "b=" + c, "c=" + c}
+	res, err = rl.Reload()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c"}, res.Added)
+	assert.Equal(t, []string{"b"}, res.Changed)
+	assert.Empty(t, res.Removed)
+	assert.Equal(t, 1, res.Unchanged)
+	assert.Len(t, notified, 3)
+	assert.Equal(t, res, rl.Last())
+
+	after := rl.shared.CollectionSet().Collections
+	assert.Len(t, after, 3)
+	assert.True(t, oldA == after["a"], "unchanged collections should be kept")
+	assert.False(t, oldB == after["b"])
+	assert.Equal(t, c, after["b"].SourcePath)
+	assert.Equal(t, c, after["c"].SourcePath)
+
+	v, err, found := s.GetFirst(hfile.MockKeyInt(1))
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, hfile.MockValueInt(1), v)
+	s.Release()
+
+	// Files replaced in place are reloaded too.
+	future := time.Now().Add(time.Hour)
+	assert.Nil(t, os.Chtimes(a, future, future))
+	rl.args = []string{"a=" + a}
+	res, err = rl.Reload()
+	assert.Nil(t, err)
+	assert.Empty(t, res.Added)
+	assert.Equal(t, []string{"a"}, res.Changed)
+	assert.Equal(t, []string{"b", "c"}, res.Removed)
+	assert.Len(t, rl.shared.CollectionSet().Collections, 1)
+	assert.False(t, oldA == rl.shared.CollectionSet().Collections["a"])
+	assert.Len(t, rl.Configs(), 1)
+}
+
+func TestReloadFailureKeepsServing(t *testing.T) {
+	dir := reloadTestFiles(t, "a")
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a")
+
+	rl := newTestReloader(t, "a="+a)
+	before := rl.shared.CollectionSet()
+
+	for _, args := range [][]string{
+		{"a=" + a, "b=" + filepath.Join(dir, "missing")},
+		{"a=" + a, "a=" + a},
+		{"a"},
+		{},
+	} {
+		rl.args = args
+		_, err := rl.Reload()
+		assert.NotNil(t, err, args)
+		assert.True(t, before == rl.shared.CollectionSet(), args)
+		assert.NotEmpty(t, rl.Last().Error, args)
+		assert.Len(t, rl.Configs(), 1)
+	}
+}
+
+func TestReloadHandler(t *testing.T) {
+	dir := reloadTestFiles(t, "a", "b")
+	defer os.RemoveAll(dir)
+
+	rl := newTestReloader(t, "a="+filepath.Join(dir, "a"))
+	srv := httptest.NewServer(rl)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	rl.args = append(rl.args, "b="+filepath.Join(dir, "b"))
+	resp, err = http.Post(srv.URL+"?wait=true", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var res ReloadResult
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, []string{"b"}, res.Added)
+
+	rl.busy = 1
+	resp, err = http.Post(srv.URL, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
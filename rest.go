@@ -0,0 +1,385 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/foursquare/quiver/hfile"
+)
+
+const (
+	restPrefix = "/v1/collections"
+
+	// Scans return at most this many pairs unless a limit is given, and never more than the max.
+	restDefaultScanLimit = 100
+	restMaxScanLimit     = 10000
+
+	// Info requests may sample at most this many random keys per collection.
+	restMaxSamples = 10000
+)
+
+/*
+RestHandler serves lookups, scans and info as JSON, for debugging with curl and for clients without
+Thrift or gRPC:
+
+	GET /v1/collections                          info on every collection
+	GET /v1/collections/{name}                   info on a collection (and any under it), with ?samples=N (at most 10000) random keys
+	GET /v1/collections/{name}/keys/{key}        a key's values
+	GET /v1/collections/{name}/prefixes/{prefix} pairs whose keys start with the prefix
+	GET /v1/collections/{name}/scan              pairs from ?start= (inclusive) to ?end= (exclusive)
+
+Keys, in paths and parameters as well as responses, use ?key_encoding= and values ?value_encoding=
+(utf8, hex, base64, int32 or int64), or ?encoding= for both; the default is utf8. Scans take
+?limit= and ?keys_only=true, and report whether there are more pairs after those returned.
+Collection names may contain slashes, escaped or not.
+*/
+func RestHandler(cs *RpcShared) http.Handler {
+	return &restHandler{cs}
+}
+
+type restHandler struct {
+	*RpcShared
+}
+
+type (
+	restPair struct {
+		Key   string  `json:"key"`
+		Value *string `json:"value,omitempty"`
+	}
+	restValues struct {
+		Key    string   `json:"key"`
+		Values []string `json:"values"`
+	}
+	restScan struct {
+		Pairs []restPair `json:"pairs"`
+		More  bool       `json:"more"`
+	}
+	restInfo struct {
+		Name              string            `json:"name"`
+		Path              string            `json:"path"`
+		NumElements       int64             `json:"num_elements"`
+		FirstKey          string            `json:"first_key"`
+		LastKey           string            `json:"last_key"`
+		RandomKeys        []string          `json:"random_keys,omitempty"`
+		NumBlocks         int32             `json:"num_blocks"`
+		CompressedBytes   int64             `json:"compressed_bytes"`
+		UncompressedBytes int64             `json:"uncompressed_bytes"`
+		Codec             string            `json:"codec"`
+		LoadMethod        string            `json:"load_method"`
+		Bloom             string            `json:"bloom"`
+		FileInfo          map[string]string `json:"file_info"`
+	}
+	restError struct {
+		Error string `json:"error"`
+	}
+)
+
+// A restNotFoundError is a request for an endpoint that doesn't exist, or a key without any values.
+type restNotFoundError string
+
+func (e restNotFoundError) Error() string { return string(e) }
+
+// restRequest holds a request's parsed parameters, and encodes keys and values for its response.
+type restRequest struct {
+	query          url.Values
+	keyEnc, valEnc hfile.ByteEncoding
+}
+
+func (h *restHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		restRespond(w, http.StatusMethodNotAllowed, restError{"only GET is supported"})
+		return
+	}
+	res, err := h.serve(r)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.(type) {
+		case BadRequestError:
+			status = http.StatusBadRequest
+		case UnknownCollectionError, restNotFoundError:
+			status = http.StatusNotFound
+		}
+		if Settings.debug || status == http.StatusInternalServerError {
+			log.Printf("[RestHandler] %s: %v\n", r.URL, err)
+		}
+		restRespond(w, status, restError{err.Error()})
+		return
+	}
+	restRespond(w, http.StatusOK, res)
+}
+
+func restRespond(w http.ResponseWriter, status int, res interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(res)
+}
+
+func (h *restHandler) serve(r *http.Request) (interface{}, error) {
+	req, err := parseRestRequest(r.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	path := r.URL.EscapedPath()
+	if path != restPrefix && !strings.HasPrefix(path, restPrefix+"/") {
+		return nil, restNotFoundError(fmt.Sprintf("no such endpoint: %s", r.URL.Path))
+	}
+	segments, err := unescapeSegments(strings.TrimPrefix(path, restPrefix))
+	if err != nil {
+		return nil, BadRequestError(err.Error())
+	}
+	if len(segments) == 0 {
+		return h.info(req, "")
+	}
+
+	name, action, args := h.splitCollection(segments)
+	switch {
+	case action == "" && len(args) == 0:
+		return h.info(req, name)
+	case action == "keys" && len(args) == 1:
+		return h.get(req, name, args[0])
+	case action == "prefixes" && len(args) == 1:
+		return h.scan(req, ScanRequest{HfileName: name, Prefixes: [][]byte{nil}}, args[0])
+	case action == "scan" && len(args) == 0:
+		return h.scan(req, ScanRequest{HfileName: name}, "")
+	default:
+		return nil, restNotFoundError(fmt.Sprintf("no such endpoint: %s", r.URL.Path))
+	}
+}
+
+// unescapeSegments splits an escaped path on its slashes, so escaped slashes (%2F) stay within a segment.
+func unescapeSegments(path string) ([]string, error) {
+	var segments []string
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		if s == "" {
+			continue
+		}
+		unescaped, err := url.PathUnescape(s)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, unescaped)
+	}
+	return segments, nil
+}
+
+/*
+splitCollection splits the path segments into a collection name, an action and its arguments. Since
+names may contain unescaped slashes, it picks the first served name that is followed by an action
+(or nothing), falling back to splitting at the first action, if any, for names that aren't served
+(e.g. "bigcol", to describe all of its partitions).
+*/
+func (h *restHandler) splitCollection(segments []string) (string, string, []string) {
+	isAction := func(s string) bool { return s == "keys" || s == "prefixes" || s == "scan" }
+	collections := h.CollectionSet().Collections
+
+	for i := 1; i <= len(segments); i++ {
+		name := strings.Join(segments[:i], "/")
+		if _, ok := collections[name]; !ok {
+			continue
+		}
+		if i == len(segments) {
+			return name, "", nil
+		}
+		if isAction(segments[i]) {
+			return name, segments[i], segments[i+1:]
+		}
+	}
+	for i := 1; i < len(segments); i++ {
+		if isAction(segments[i]) {
+			return strings.Join(segments[:i], "/"), segments[i], segments[i+1:]
+		}
+	}
+	return strings.Join(segments, "/"), "", nil
+}
+
+func parseRestRequest(query url.Values) (*restRequest, error) {
+	req := &restRequest{query: query}
+	for _, p := range []struct {
+		param string
+		enc   *hfile.ByteEncoding
+	}{{"encoding", &req.keyEnc}, {"key_encoding", &req.keyEnc}, {"encoding", &req.valEnc}, {"value_encoding", &req.valEnc}} {
+		if name := query.Get(p.param); name != "" {
+			enc, err := hfile.ParseByteEncoding(name)
+			if err != nil {
+				return nil, BadRequestError(fmt.Sprintf("bad %s: %v", p.param, err))
+			}
+			*p.enc = enc
+		}
+	}
+	return req, nil
+}
+
+func (req *restRequest) intParam(name string, def int) (int, error) {
+	s := req.query.Get(name)
+	if s == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return 0, BadRequestError(fmt.Sprintf("bad %s %q: expected a non-negative integer", name, s))
+	}
+	return i, nil
+}
+
+// decodeKey decodes a key from a path or parameter, returning nil for an empty one.
+func (req *restRequest) decodeKey(what, s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	key, err := req.keyEnc.Decode(s)
+	if err != nil {
+		return nil, BadRequestError(fmt.Sprintf("bad %s %q for key_encoding %s: %v", what, s, req.keyEnc, err))
+	}
+	return key, nil
+}
+
+func (req *restRequest) encodeKey(key []byte) (string, error) {
+	s, err := req.keyEnc.Encode(key)
+	if err != nil {
+		return "", BadRequestError(fmt.Sprintf("can't encode key %x as %s (try key_encoding=hex): %v", key, req.keyEnc, err))
+	}
+	return s, nil
+}
+
+func (req *restRequest) encodeValue(key, value []byte) (string, error) {
+	s, err := req.valEnc.Encode(value)
+	if err != nil {
+		return "", BadRequestError(fmt.Sprintf("can't encode value of key %x as %s (try value_encoding=hex): %v", key, req.valEnc, err))
+	}
+	return s, nil
+}
+
+func (h *restHandler) info(req *restRequest, name string) (interface{}, error) {
+	samples, err := req.intParam("samples", 0)
+	if err != nil {
+		return nil, err
+	}
+	if samples > restMaxSamples {
+		return nil, BadRequestError(fmt.Sprintf("samples must be at most %d", restMaxSamples))
+	}
+	infos, err := h.GetInfo(InfoRequest{HfileName: name, NumRandomKeys: samples})
+	if err != nil {
+		return nil, err
+	}
+	if name != "" && len(infos) == 0 {
+		return nil, UnknownCollectionError(name)
+	}
+
+	res := make([]restInfo, len(infos))
+	for j, i := range infos {
+		res[j] = restInfo{
+			Name:              i.Name,
+			Path:              i.Path,
+			NumElements:       i.NumElements,
+			NumBlocks:         i.NumBlocks,
+			CompressedBytes:   i.CompressedBytes,
+			UncompressedBytes: i.UncompressedBytes,
+			Codec:             i.Codec,
+			LoadMethod:        i.LoadMethod,
+			Bloom:             i.Bloom,
+			FileInfo:          i.FileInfo,
+		}
+		if res[j].FirstKey, err = req.encodeKey(i.FirstKey); err != nil {
+			return nil, err
+		}
+		if res[j].LastKey, err = req.encodeKey(i.LastKey); err != nil {
+			return nil, err
+		}
+		for _, k := range i.RandomKeys {
+			encoded, err := req.encodeKey(k)
+			if err != nil {
+				return nil, err
+			}
+			res[j].RandomKeys = append(res[j].RandomKeys, encoded)
+		}
+	}
+	return res, nil
+}
+
+func (h *restHandler) get(req *restRequest, name, encodedKey string) (interface{}, error) {
+	key, err := req.decodeKey("key", encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	found, err := h.GetValuesMulti(SingleHFileKeyRequest{HfileName: name, SortedKeys: [][]byte{key}})
+	if err != nil {
+		return nil, err
+	}
+	values, ok := found.Values[0]
+	if !ok {
+		return nil, restNotFoundError(fmt.Sprintf("key %s not found", encodedKey))
+	}
+
+	res := restValues{Key: encodedKey, Values: make([]string, len(values))}
+	for i, v := range values {
+		if res.Values[i], err = req.encodeValue(key, v); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// scan returns the pairs in the range given by the request's start and end parameters (and under the prefix, if any).
+func (h *restHandler) scan(req *restRequest, scan ScanRequest, encodedPrefix string) (interface{}, error) {
+	var err error
+	if scan.Prefixes != nil {
+		if scan.Prefixes[0], err = req.decodeKey("prefix", encodedPrefix); err != nil {
+			return nil, err
+		}
+	}
+	if scan.StartKey, err = req.decodeKey("start", req.query.Get("start")); err != nil {
+		return nil, err
+	}
+	if scan.EndKey, err = req.decodeKey("end", req.query.Get("end")); err != nil {
+		return nil, err
+	}
+	if scan.KeysOnly, err = strconv.ParseBool(req.query.Get("keys_only")); err != nil && req.query.Get("keys_only") != "" {
+		return nil, BadRequestError(fmt.Sprintf("bad keys_only %q", req.query.Get("keys_only")))
+	}
+	limit, err := req.intParam("limit", restDefaultScanLimit)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > restMaxScanLimit {
+		return nil, BadRequestError(fmt.Sprintf("limit must be between 1 and %d", restMaxScanLimit))
+	}
+	// Scan one more than requested to see if there are more.
+	scan.Limit = limit + 1
+
+	res := restScan{Pairs: []restPair{}}
+	err = h.Scan(scan, func(k, v []byte) error {
+		if len(res.Pairs) == limit {
+			res.More = true
+			return nil
+		}
+		key, err := req.encodeKey(k)
+		if err != nil {
+			return err
+		}
+		pair := restPair{Key: key}
+		if !scan.KeysOnly {
+			value, err := req.encodeValue(k, v)
+			if err != nil {
+				return err
+			}
+			pair.Value = &value
+		}
+		res.Pairs = append(res.Pairs, pair)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
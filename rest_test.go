@@ -0,0 +1,113 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/foursquare/quiver/hfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func restGet(t *testing.T, srv *httptest.Server, path string, expectedStatus int, res interface{}) {
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, expectedStatus, resp.StatusCode, path)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(res), path)
+}
+
+func TestRest(t *testing.T) {
+	Setup(t)
+	srv := httptest.NewServer(RestHandler(compressed.RpcShared))
+	defer srv.Close()
+
+	var infos []restInfo
+	restGet(t, srv, "/v1/collections?encoding=hex", 200, &infos)
+	if assert.Len(t, infos, 1) {
+		assert.Equal(t, "compressed", infos[0].Name)
+		assert.Equal(t, int64(maxKey), infos[0].NumElements)
+		assert.Empty(t, infos[0].RandomKeys)
+	}
+	restGet(t, srv, "/v1/collections/compressed?encoding=int32&samples=5", 200, &infos)
+	if assert.Len(t, infos, 1) {
+		assert.Equal(t, "0", infos[0].FirstKey)
+		assert.NotEmpty(t, infos[0].RandomKeys)
+	}
+
+	var values restValues
+	restGet(t, srv, "/v1/collections/compressed/keys/42?key_encoding=int32", 200, &values)
+	assert.Equal(t, restValues{"42", []string{"value-for-42"}}, values)
+	restGet(t, srv, "/v1/collections/compressed/keys/0000002a?key_encoding=hex&value_encoding=base64", 200, &values)
+	assert.Equal(t, restValues{"0000002a", []string{"dmFsdWUtZm9yLTQy"}}, values)
+
+	var scan restScan
+	restGet(t, srv, "/v1/collections/compressed/scan?key_encoding=int32&start=10&end=13", 200, &scan)
+	assert.False(t, scan.More)
+	if assert.Len(t, scan.Pairs, 3) {
+		assert.Equal(t, "10", scan.Pairs[0].Key)
+		assert.Equal(t, "12", scan.Pairs[2].Key)
+	}
+	scan = restScan{}
+	restGet(t, srv, "/v1/collections/compressed/scan?key_encoding=int32&value_encoding=utf8&limit=2&keys_only=true", 200, &scan)
+	assert.True(t, scan.More)
+	assert.Equal(t, []restPair{{"0", nil}, {"1", nil}}, scan.Pairs)
+
+	scan = restScan{}
+	restGet(t, srv, "/v1/collections/compressed/prefixes/000001?key_encoding=hex&value_encoding=utf8&limit=300", 200, &scan)
+	assert.False(t, scan.More)
+	if assert.Len(t, scan.Pairs, 256) {
+		assert.Equal(t, "00000100", scan.Pairs[0].Key)
+		assert.Equal(t, string(hfile.MockValueInt(256)), *scan.Pairs[0].Value)
+	}
+
+	var restErr restError
+	for path, status := range map[string]int{
+		"/v1/collections":             400, // Binary keys aren't utf8.
+		"/v1/collections/nope":        404,
+		"/v1/collections/nope/keys/1": 404,
+		"/v1/collections/compressed/keys/20000000?key_encoding=int32":     404,
+		"/v1/collections/compressed/nope":                                 404,
+		"/v1/collections/compressed/keys/zz?key_encoding=hex":             400,
+		"/v1/collections/compressed/scan?encoding=bogus":                  400,
+		"/v1/collections/compressed/scan?limit=0":                         400,
+		"/v1/collections/compressed/scan?encoding=int32&limit=10000000":   400,
+		"/v1/collections/compressed/scan?value_encoding=int32&limit=1":    400,
+		"/v1/collections/compressed?encoding=int32&samples=1099511627776": 400,
+	} {
+		restErr = restError{}
+		restGet(t, srv, path, status, &restErr)
+		assert.NotEmpty(t, restErr.Error, path)
+	}
+}
+
+func TestRestSplitCollection(t *testing.T) {
+	h := &restHandler{NewRpcShared(&hfile.CollectionSet{Collections: map[string]*hfile.Reader{"a": nil, "b/1": nil, "b/keys": nil}})}
+
+	for _, tc := range []struct {
+		segments []string
+		name     string
+		action   string
+		args     []string
+	}{
+		{[]string{"a"}, "a", "", nil},
+		{[]string{"a", "keys", "k"}, "a", "keys", []string{"k"}},
+		{[]string{"b", "1", "scan"}, "b/1", "scan", []string{}},
+		{[]string{"b/1", "prefixes", "p"}, "b/1", "prefixes", []string{"p"}},
+		{[]string{"b", "keys"}, "b/keys", "", nil},
+		{[]string{"b", "keys", "keys", "k"}, "b/keys", "keys", []string{"k"}},
+		{[]string{"b"}, "b", "", nil},
+		{[]string{"c", "keys", "k"}, "c", "keys", []string{"k"}},
+	} {
+		name, action, args := h.splitCollection(tc.segments)
+		assert.Equal(t, tc.name, name, "%v", tc.segments)
+		assert.Equal(t, tc.action, action, "%v", tc.segments)
+		assert.Equal(t, tc.args, args, "%v", tc.segments)
+	}
+}
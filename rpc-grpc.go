@@ -0,0 +1,218 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	pb "github.com/foursquare/quiver/gen_proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcError returns err with a status code saying whether the request or the server was at fault.
+func grpcError(err error) error {
+	switch err.(type) {
+	case BadRequestError:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case UnknownCollectionError:
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// sortedKeyValues returns the values of a map from (string) keys to values, sorted by key.
+func sortedKeyValues(m map[string][][]byte) []*pb.KeyValues {
+	res := make([]*pb.KeyValues, 0, len(m))
+	for k, v := range m {
+		res = append(res, &pb.KeyValues{Key: []byte(k), Values: v})
+	}
+	sort.Slice(res, func(i, j int) bool { return bytes.Compare(res[i].Key, res[j].Key) < 0 })
+	return res
+}
+
+func (g *GrpcImpl) GetValuesSingle(_ context.Context, req *pb.SingleHFileKeyRequest) (*pb.SingleHFileKeyResponse, error) {
+	resp, err := g.RpcShared.GetValuesSingle(SingleHFileKeyRequest{
+		HfileName:  req.HfileName,
+		SortedKeys: req.SortedKeys,
+		CountOnly:  req.CountOnly,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.SingleHFileKeyResponse{
+		Values:   resp.Values,
+		KeyCount: resp.KeyCount,
+	}, nil
+}
+
+func (g *GrpcImpl) GetValuesMulti(_ context.Context, req *pb.SingleHFileKeyRequest) (*pb.MultiHFileKeyResponse, error) {
+	resp, err := g.RpcShared.GetValuesMulti(SingleHFileKeyRequest{
+		HfileName:        req.HfileName,
+		SortedKeys:       req.SortedKeys,
+		PerKeyValueLimit: req.PerKeyValueLimit,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	res := &pb.MultiHFileKeyResponse{Values: make(map[int32]*pb.ValueList, len(resp.Values)), KeyCount: resp.KeyCount}
+	for idx, values := range resp.Values {
+		res.Values[idx] = &pb.ValueList{Values: values}
+	}
+	return res, nil
+}
+
+func (g *GrpcImpl) GetValuesForPrefixes(_ context.Context, req *pb.PrefixRequest) (*pb.PrefixResponse, error) {
+	resp, err := g.RpcShared.GetValuesForPrefixes(PrefixRequest{
+		HfileName:  req.HfileName,
+		SortedKeys: req.SortedKeys,
+		LastKey:    req.LastKey,
+		ValueLimit: req.ValueLimit,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.PrefixResponse{
+		Values:  sortedKeyValues(resp.Values),
+		LastKey: resp.LastKey,
+	}, nil
+}
+
+func (g *GrpcImpl) GetValuesMultiSplitKeys(_ context.Context, req *pb.MultiHFileSplitKeyRequest) (*pb.KeyToValuesResponse, error) {
+	splitKey := make([][][]byte, len(req.SplitKey))
+	for i, segment := range req.SplitKey {
+		splitKey[i] = segment.GetParts()
+	}
+	resp, err := g.RpcShared.GetValuesMultiSplitKeys(SplitKeyRequest{
+		HfileName: req.HfileName,
+		SplitKey:  splitKey,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.KeyToValuesResponse{Values: sortedKeyValues(resp.Values)}, nil
+}
+
+func (g *GrpcImpl) GetIterator(_ context.Context, req *pb.IteratorRequest) (*pb.IteratorResponse, error) {
+	// proto3 can't tell a missing limit from 0, so, unlike Thrift, this rejects both.
+	if req.ResponseLimit <= 0 {
+		return nil, grpcError(BadRequestError("Missing limit."))
+	}
+	shared := IteratorRequest{
+		HfileName:     req.HfileName,
+		IncludeValues: !req.KeysOnly,
+		SkipKeys:      req.SkipKeys,
+		ResponseLimit: req.ResponseLimit,
+		Reverse:       req.Reverse,
+	}
+	// An empty last or end key means none.
+	shared.LastKey, shared.EndKey = nilIfEmpty(req.LastKey), nilIfEmpty(req.EndKey)
+
+	resp, err := g.RpcShared.GetIterator(shared)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	res := &pb.IteratorResponse{
+		Values:   make([]*pb.KeyValueItem, len(resp.Values)),
+		LastKey:  resp.LastKey,
+		SkipKeys: resp.SkipKeys,
+	}
+	for i, kv := range resp.Values {
+		res.Values[i] = &pb.KeyValueItem{Key: kv.Key, Value: kv.Value}
+	}
+	return res, nil
+}
+
+// nilIfEmpty returns nil for an empty key, since unset bytes fields arrive empty rather than nil.
+func nilIfEmpty(key []byte) []byte {
+	if len(key) == 0 {
+		return nil
+	}
+	return key
+}
+
+/*
+streamScan sends each pair the request selects as a separate message. Send blocks while the client's
+flow-control window is full, so a slow client slows the scan rather than buffering it on the
+server, and the scan stops as soon as the client cancels or its deadline passes.
+*/
+func (g *GrpcImpl) streamScan(stream grpc.ServerStream, req ScanRequest, send func(*pb.KeyValueItem) error) error {
+	ctx := stream.Context()
+	err := g.RpcShared.Scan(req, func(k, v []byte) error {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		// Send marshals the item before returning, so it can point into the hfile's blocks.
+		return send(&pb.KeyValueItem{Key: k, Value: v})
+	})
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return grpcError(err)
+}
+
+func (g *GrpcImpl) Scan(req *pb.ScanRequest, stream pb.QuiverService_ScanServer) error {
+	return g.streamScan(stream, ScanRequest{
+		HfileName: req.HfileName,
+		StartKey:  nilIfEmpty(req.StartKey),
+		EndKey:    nilIfEmpty(req.EndKey),
+		KeysOnly:  req.KeysOnly,
+		Limit:     int(req.Limit),
+	}, stream.Send)
+}
+
+func (g *GrpcImpl) ScanPrefixes(req *pb.PrefixScanRequest, stream pb.QuiverService_ScanPrefixesServer) error {
+	if len(req.Prefixes) == 0 {
+		return nil
+	}
+	return g.streamScan(stream, ScanRequest{
+		HfileName: req.HfileName,
+		StartKey:  nilIfEmpty(req.StartKey),
+		EndKey:    nilIfEmpty(req.EndKey),
+		Prefixes:  req.Prefixes,
+		KeysOnly:  req.KeysOnly,
+		Limit:     int(req.Limit),
+	}, stream.Send)
+}
+
+func (g *GrpcImpl) getInfo(req *pb.InfoRequest, allowRandom bool) (*pb.InfoResponse, error) {
+	sample := 0
+	if allowRandom {
+		sample = int(req.NumRandomKeys)
+	}
+	infos, err := g.RpcShared.GetInfo(InfoRequest{HfileName: req.HfileName, NumRandomKeys: sample})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	res := &pb.InfoResponse{Infos: make([]*pb.HFileInfo, len(infos))}
+	for j, i := range infos {
+		res.Infos[j] = &pb.HFileInfo{
+			Name:              i.Name,
+			Path:              i.Path,
+			NumElements:       i.NumElements,
+			FirstKey:          i.FirstKey,
+			LastKey:           i.LastKey,
+			RandomKeys:        i.RandomKeys,
+			NumBlocks:         i.NumBlocks,
+			CompressedBytes:   i.CompressedBytes,
+			UncompressedBytes: i.UncompressedBytes,
+			Codec:             i.Codec,
+			LoadMethod:        i.LoadMethod,
+			Bloom:             i.Bloom,
+			FileInfo:          i.FileInfo,
+		}
+	}
+	return res, nil
+}
+
+func (g *GrpcImpl) GetInfo(_ context.Context, req *pb.InfoRequest) (*pb.InfoResponse, error) {
+	return g.getInfo(req, false)
+}
+
+func (g *GrpcImpl) ScanCollectionAndSampleKeys(_ context.Context, req *pb.InfoRequest) (*pb.InfoResponse, error) {
+	return g.getInfo(req, true)
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2015 Foursquare Labs Inc.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/foursquare/quiver/gen"
+)
+
+// thriftError returns err as an HFileServiceException if the request was at fault, so clients see the message.
+func thriftError(err error) error {
+	if bad, ok := err.(BadRequestError); ok {
+		msg := bad.Error()
+		return &gen.HFileServiceException{Message: &msg}
+	}
+	return err
+}
+
+func (cs *ThriftRpcImpl) GetValuesSingle(req *gen.SingleHFileKeyRequest) (*gen.SingleHFileKeyResponse, error) {
+	resp, err := cs.RpcShared.GetValuesSingle(SingleHFileKeyRequest{
+		HfileName:  req.GetHfileName(),
+		SortedKeys: req.SortedKeys,
+		CountOnly:  req.GetCountOnly(),
+	})
+	if err != nil {
+		return nil, thriftError(err)
+	}
+	return &gen.SingleHFileKeyResponse{
+		Values:   resp.Values,
+		KeyCount: &resp.KeyCount,
+	}, nil
+}
+
+func (cs *ThriftRpcImpl) GetValuesMulti(req *gen.SingleHFileKeyRequest) (*gen.MultiHFileKeyResponse, error) {
+	resp, err := cs.RpcShared.GetValuesMulti(SingleHFileKeyRequest{
+		HfileName:        req.GetHfileName(),
+		SortedKeys:       req.SortedKeys,
+		PerKeyValueLimit: req.GetPerKeyValueLimit(),
+	})
+	if err != nil {
+		return nil, thriftError(err)
+	}
+	return &gen.MultiHFileKeyResponse{
+		Values:   resp.Values,
+		KeyCount: &resp.KeyCount,
+	}, nil
+}
+
+func (cs *ThriftRpcImpl) GetValuesForPrefixes(req *gen.PrefixRequest) (*gen.PrefixResponse, error) {
+	resp, err := cs.RpcShared.GetValuesForPrefixes(PrefixRequest{
+		HfileName:  req.GetHfileName(),
+		SortedKeys: req.SortedKeys,
+		LastKey:    req.LastKey,
+		ValueLimit: req.GetValueLimit(),
+	})
+	if err != nil {
+		return nil, thriftError(err)
+	}
+	return &gen.PrefixResponse{
+		Values:  resp.Values,
+		LastKey: resp.LastKey,
+	}, nil
+}
+
+func (cs *ThriftRpcImpl) GetValuesMultiSplitKeys(req *gen.MultiHFileSplitKeyRequest) (*gen.KeyToValuesResponse, error) {
+	resp, err := cs.RpcShared.GetValuesMultiSplitKeys(SplitKeyRequest{
+		HfileName: req.GetHfileName(),
+		SplitKey:  req.SplitKey,
+	})
+	if err != nil {
+		return nil, thriftError(err)
+	}
+	return &gen.KeyToValuesResponse{Values: resp.Values}, nil
+}
+
+func (cs *ThriftRpcImpl) GetIterator(req *gen.IteratorRequest) (*gen.IteratorResponse, error) {
+	// Only a missing limit is an error: a limit of 0 (or less) gets an empty page.
+	if req.ResponseLimit == nil {
+		return nil, fmt.Errorf("Missing limit.")
+	}
+	resp, err := cs.RpcShared.GetIterator(IteratorRequest{
+		HfileName:     req.GetHfileName(),
+		IncludeValues: req.IncludeValues == nil || *req.IncludeValues,
+		LastKey:       req.LastKey,
+		SkipKeys:      req.GetSkipKeys(),
+		ResponseLimit: req.GetResponseLimit(),
+		EndKey:        req.EndKey,
+		Reverse:       req.GetReverse(),
+	})
+	if err != nil {
+		return nil, thriftError(err)
+	}
+
+	res := &gen.IteratorResponse{Values: make([]*gen.KeyValueItem, len(resp.Values))}
+	for i, kv := range resp.Values {
+		res.Values[i] = &gen.KeyValueItem{Key: kv.Key, Value: kv.Value}
+	}
+	if resp.LastKey != nil {
+		res.LastKey, res.SkipKeys = resp.LastKey, &resp.SkipKeys
+	}
+	return res, nil
+}
+
+func (cs *ThriftRpcImpl) getInfo(req *gen.InfoRequest, allowRandom bool) ([]*gen.HFileInfo, error) {
+	if req == nil {
+		return nil, fmt.Errorf("null request!")
+	}
+	sample := 0
+	if allowRandom {
+		sample = int(req.GetNumRandomKeys())
+	}
+
+	infos, err := cs.RpcShared.GetInfo(InfoRequest{HfileName: req.GetHfileName(), NumRandomKeys: sample})
+	if err != nil {
+		return nil, thriftError(err)
+	}
+	r := make([]*gen.HFileInfo, len(infos))
+	for j, i := range infos {
+		r[j] = &gen.HFileInfo{
+			Name:              &i.Name,
+			Path:              &i.Path,
+			NumElements:       &i.NumElements,
+			FirstKey:          i.FirstKey,
+			LastKey:           i.LastKey,
+			RandomKeys:        i.RandomKeys,
+			NumBlocks:         &i.NumBlocks,
+			CompressedBytes:   &i.CompressedBytes,
+			UncompressedBytes: &i.UncompressedBytes,
+			Codec:             &i.Codec,
+			LoadMethod:        &i.LoadMethod,
+			Bloom:             &i.Bloom,
+			FileInfo:          i.FileInfo,
+		}
+	}
+	return r, nil
+}
+
+func (cs *ThriftRpcImpl) GetInfo(req *gen.InfoRequest) (r []*gen.HFileInfo, err error) {
+	return cs.getInfo(req, false)
+}
+
+func (cs *ThriftRpcImpl) ScanCollectionAndSampleKeys(req *gen.InfoRequest) (r []*gen.HFileInfo, err error) {
+	return cs.getInfo(req, true)
+}
+
+func (cs *ThriftRpcImpl) TestTimeout(waitInMillis int32) (r int32, err error) {
+	return 0, fmt.Errorf("Not implemented")
+}
@@ -4,25 +4,27 @@ package main
 
 import (
 	"bytes"
-	"context"
 	"encoding/hex"
 	"fmt"
 	"log"
-	"math/rand"
+	"sort"
 	"strings"
+	"sync/atomic"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/foursquare/fsgo/net/thriftrpc"
 	"github.com/foursquare/fsgo/report"
 	"github.com/foursquare/quiver/gen"
-	pb "github.com/foursquare/quiver/gen_proto"
 	"github.com/foursquare/quiver/hfile"
 	"github.com/foursquare/quiver/util"
 )
 
+// RpcShared implements the HFileService calls, independent of any one protocol: ThriftRpcImpl and GrpcImpl translate their
+// requests and responses to and from these types.
 type (
 	RpcShared struct {
-		*hfile.CollectionSet
+		// The *hfile.CollectionSet being served, which a reload may swap at any time.
+		current atomic.Value
 	}
 	ThriftRpcImpl struct {
 		*RpcShared
@@ -32,31 +34,142 @@ type (
 	}
 )
 
-func WrapHttpRpcHandler(cs *hfile.CollectionSet, stats *report.Recorder) *thriftrpc.ThriftOverHTTPHandler {
-	return thriftrpc.NewThriftOverHTTPHandler(WrapProcessor(cs, stats), stats)
+func NewRpcShared(cs *hfile.CollectionSet) *RpcShared {
+	shared := new(RpcShared)
+	shared.current.Store(cs)
+	return shared
 }
 
-func WrapProcessor(cs *hfile.CollectionSet, stats *report.Recorder) thrift.TProcessor {
-	return thriftrpc.AddLogging(gen.NewHFileServiceProcessor(&ThriftRpcImpl{&RpcShared{cs}}), stats, Settings.debug)
+// CollectionSet returns the collections currently being served. Callers should use one set for the
+// whole of a request, rather than calling this again, as it may change in between.
+func (cs *RpcShared) CollectionSet() *hfile.CollectionSet {
+	return cs.current.Load().(*hfile.CollectionSet)
+}
+
+// swap starts serving next instead of the current set, which it returns.
+func (cs *RpcShared) swap(next *hfile.CollectionSet) *hfile.CollectionSet {
+	prev := cs.CollectionSet()
+	cs.current.Store(next)
+	return prev
+}
+
+func WrapHttpRpcHandler(shared *RpcShared, stats *report.Recorder) *thriftrpc.ThriftOverHTTPHandler {
+	return thriftrpc.NewThriftOverHTTPHandler(WrapProcessor(shared, stats), stats)
+}
+
+func WrapProcessor(shared *RpcShared, stats *report.Recorder) thrift.TProcessor {
+	return thriftrpc.AddLogging(gen.NewHFileServiceProcessor(&ThriftRpcImpl{shared}), stats, Settings.debug)
+}
+
+// A BadRequestError is a request the server refuses to serve, e.g. one missing a required parameter.
+type BadRequestError string
+
+func (e BadRequestError) Error() string { return string(e) }
+
+// An UnknownCollectionError is a request for a collection the server isn't serving.
+type UnknownCollectionError string
+
+func (e UnknownCollectionError) Error() string {
+	return fmt.Sprintf("not configured with reader for collection %s", string(e))
+}
+
+func (cs *RpcShared) readerFor(name string) (*hfile.Reader, error) {
+	if r, ok := cs.CollectionSet().Collections[name]; ok {
+		return r, nil
+	}
+	return nil, UnknownCollectionError(name)
 }
 
 type (
 	SingleHFileKeyRequest struct {
-		HfileName  string
-		SortedKeys [][]byte
-		CountOnly  bool
+		HfileName        string
+		SortedKeys       [][]byte
+		PerKeyValueLimit int32 // For GetValuesMulti, the most values to return for each key, or 0 for all of them.
+		CountOnly        bool
 	}
 	SingleHFileKeyResponse struct {
 		Values   map[int32][]byte
 		KeyCount int32
 	}
+	MultiHFileKeyResponse struct {
+		Values   map[int32][][]byte
+		KeyCount int32
+	}
+
+	PrefixRequest struct {
+		HfileName  string
+		SortedKeys [][]byte
+		LastKey    []byte
+		ValueLimit int32
+	}
+	PrefixResponse struct {
+		Values  map[string][][]byte
+		LastKey []byte
+	}
+
+	SplitKeyRequest struct {
+		HfileName string
+		SplitKey  [][][]byte
+	}
+	KeyToValuesResponse struct {
+		Values map[string][][]byte
+	}
+
+	IteratorRequest struct {
+		HfileName     string
+		IncludeValues bool
+		LastKey       []byte
+		SkipKeys      int32
+		ResponseLimit int32
+		EndKey        []byte
+		Reverse       bool
+	}
+	KeyValueItem struct {
+		Key   []byte
+		Value []byte
+	}
+	IteratorResponse struct {
+		Values []KeyValueItem
+		// Where to continue from, or nil if iteration ended before the first item.
+		LastKey  []byte
+		SkipKeys int32
+	}
+
+	ScanRequest struct {
+		HfileName string
+		StartKey  []byte   // Inclusive, or nil to start at the first key.
+		EndKey    []byte   // Exclusive, or nil to stop at the last key.
+		Prefixes  [][]byte // If not nil, only keys starting with one of these.
+		KeysOnly  bool
+		Limit     int // The most pairs to emit, or 0 for no limit.
+	}
+
+	InfoRequest struct {
+		HfileName     string
+		NumRandomKeys int
+	}
+	HFileInfo struct {
+		Name              string
+		Path              string
+		NumElements       int64
+		FirstKey          []byte
+		LastKey           []byte
+		RandomKeys        [][]byte
+		NumBlocks         int32
+		CompressedBytes   int64
+		UncompressedBytes int64
+		Codec             string
+		LoadMethod        string
+		Bloom             string
+		FileInfo          map[string]string
+	}
 )
 
 func (cs *RpcShared) GetValuesSingle(req SingleHFileKeyRequest) (*SingleHFileKeyResponse, error) {
 	if Settings.debug {
 		log.Printf("[GetValuesSingle] %s (%d keys)\n", req.HfileName, len(req.SortedKeys))
 	}
-	hfile, err := cs.ReaderFor(req.HfileName)
+	hfile, err := cs.readerFor(req.HfileName)
 	if err != nil {
 		return nil, err
 	}
@@ -105,50 +218,19 @@ func (cs *RpcShared) GetValuesSingle(req SingleHFileKeyRequest) (*SingleHFileKey
 	return res, nil
 }
 
-func (g *GrpcImpl) GetValuesSingle(_ context.Context, req *pb.SingleHFileKeyRequest) (*pb.SingleHFileKeyResponse, error) {
-	resp, err := g.RpcShared.GetValuesSingle(SingleHFileKeyRequest{
-		HfileName:  req.HfileName,
-		SortedKeys: req.SortedKeys,
-		CountOnly:  req.CountOnly,
-	})
-	if err != nil {
-		return nil, err
-	}
-	return &pb.SingleHFileKeyResponse{
-		Values:   resp.Values,
-		KeyCount: resp.KeyCount,
-	}, nil
-}
-
-func (cs *ThriftRpcImpl) GetValuesSingle(req *gen.SingleHFileKeyRequest) (*gen.SingleHFileKeyResponse, error) {
-	resp, err := cs.RpcShared.GetValuesSingle(SingleHFileKeyRequest{
-		HfileName:  *req.HfileName,
-		SortedKeys: req.SortedKeys,
-		CountOnly:  req.GetCountOnly(),
-	})
-	if err != nil {
-		return nil, err
-	}
-	return &gen.SingleHFileKeyResponse{
-		Values:   resp.Values,
-		KeyCount: &resp.KeyCount,
-	}, nil
-}
-
-func (cs *ThriftRpcImpl) GetValuesMulti(req *gen.SingleHFileKeyRequest) (r *gen.MultiHFileKeyResponse, err error) {
+func (cs *RpcShared) GetValuesMulti(req SingleHFileKeyRequest) (*MultiHFileKeyResponse, error) {
 	if Settings.debug {
 		log.Println("[GetValuesMulti]", len(req.SortedKeys))
 	}
 
-	hfile, err := cs.ReaderFor(*req.HfileName)
+	hfile, err := cs.readerFor(req.HfileName)
 	if err != nil {
 		return nil, err
 	}
 	reader := hfile.GetScanner()
 	defer reader.Release()
 
-	res := new(gen.MultiHFileKeyResponse)
-	res.Values = make(map[int32][][]byte, len(req.SortedKeys))
+	res := &MultiHFileKeyResponse{Values: make(map[int32][][]byte, len(req.SortedKeys))}
 	found := int32(0)
 
 	for idx, key := range req.SortedKeys {
@@ -161,94 +243,84 @@ func (cs *ThriftRpcImpl) GetValuesMulti(req *gen.SingleHFileKeyRequest) (r *gen.
 		}
 		if len(values) > 0 {
 			found += int32(len(values))
-			if req.PerKeyValueLimit != nil {
-				res.Values[int32(idx)] = values[:req.GetPerKeyValueLimit()]
-			} else {
-				res.Values[int32(idx)] = values
+			if limit := int(req.PerKeyValueLimit); limit > 0 && limit < len(values) {
+				values = values[:limit]
 			}
+			res.Values[int32(idx)] = values
 		}
 	}
 
-	res.KeyCount = &found
+	res.KeyCount = found
 	return res, nil
-
 }
 
-func (cs *ThriftRpcImpl) GetValuesForPrefixes(req *gen.PrefixRequest) (r *gen.PrefixResponse, err error) {
-	res := new(gen.PrefixResponse)
-	if reader, err := cs.ReaderFor(*req.HfileName); err != nil {
+func (cs *RpcShared) GetValuesForPrefixes(req PrefixRequest) (*PrefixResponse, error) {
+	reader, err := cs.readerFor(req.HfileName)
+	if err != nil {
 		return nil, err
-	} else {
-		i := reader.GetIterator()
-		defer i.Release()
-		limit := int32(0)
-		if req.ValueLimit != nil {
-			limit = *req.ValueLimit
-		}
-		if res.Values, res.LastKey, err = i.AllForPrefixes(req.SortedKeys, limit, req.LastKey); err != nil {
-			return nil, err
-		} else {
-			return res, nil
-		}
 	}
+	i := reader.GetIterator()
+	defer i.Release()
+
+	res := new(PrefixResponse)
+	if res.Values, res.LastKey, err = i.AllForPrefixes(req.SortedKeys, req.ValueLimit, req.LastKey); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
-func (cs *ThriftRpcImpl) GetValuesMultiSplitKeys(req *gen.MultiHFileSplitKeyRequest) (r *gen.KeyToValuesResponse, err error) {
-	res := make(map[string][][]byte)
-	reader, err := cs.ReaderFor(*req.HfileName)
+func (cs *RpcShared) GetValuesMultiSplitKeys(req SplitKeyRequest) (*KeyToValuesResponse, error) {
+	reader, err := cs.readerFor(req.HfileName)
 	if err != nil {
 		return nil, err
 	}
+	if max := Settings.maxSplitKeyProduct; max > 0 && util.ProductSize(req.SplitKey, max+1) > max {
+		return nil, BadRequestError(fmt.Sprintf("split key would make more than %d keys", max))
+	}
+
 	scanner := reader.GetScanner()
 	defer scanner.Release()
 
-	for _, parts := range util.RevProduct(req.SplitKey) {
-		// TODO(davidt): avoid allocing concated key by adding split-key search lower down.
-		key := bytes.Join(parts, nil)
-
-		if values, err := scanner.GetAll(key); err != nil {
-			return nil, err
-		} else if len(values) > 0 {
-			res[string(key)] = values
-		}
+	res := make(map[string][][]byte)
+	err = scanner.GetAllSplitKeys(req.SplitKey, func(key []byte, values [][]byte) {
+		res[string(key)] = values
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &gen.KeyToValuesResponse{res}, nil
+	return &KeyToValuesResponse{res}, nil
 }
 
-func (cs *ThriftRpcImpl) GetIterator(req *gen.IteratorRequest) (*gen.IteratorResponse, error) {
-	// 	HfileName     *string `thrift:"hfileName,1" json:"hfileName"`
-	// 	IncludeValues *bool   `thrift:"includeValues,2" json:"includeValues"`
-	// 	LastKey       []byte  `thrift:"lastKey,3" json:"lastKey"`
-	// 	SkipKeys      *int32  `thrift:"skipKeys,4" json:"skipKeys"`
-	// 	ResponseLimit *int32  `thrift:"responseLimit,5" json:"responseLimit"`
-	// 	EndKey        []byte  `thrift:"endKey,6" json:"endKey"`
-	var err error
+func (cs *RpcShared) GetIterator(req IteratorRequest) (*IteratorResponse, error) {
+	limit := int(req.ResponseLimit)
 
-	if req.ResponseLimit == nil {
-		return nil, fmt.Errorf("Missing limit.")
-	}
-	limit := int(*req.ResponseLimit)
-
-	reader, err := cs.ReaderFor(*req.HfileName)
+	reader, err := cs.readerFor(req.HfileName)
 	if err != nil {
 		return nil, err
 	}
 	it := reader.GetIterator()
 	defer it.Release()
 
+	// In reverse, pages run backwards from lastKey, and stop at keys before endKey.
+	next, seek, pastEnd := it.Next, it.Seek, hfile.After
+	if req.Reverse {
+		next, seek = it.Prev, it.SeekBefore
+		pastEnd = func(a, b []byte) bool { return hfile.After(b, a) }
+	}
+
 	remaining := false
 
 	if req.LastKey != nil {
-		remaining, err = it.Seek(req.LastKey)
+		remaining, err = seek(req.LastKey)
 	} else {
-		remaining, err = it.Next()
+		remaining, err = next()
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	res := new(gen.IteratorResponse)
+	res := new(IteratorResponse)
 
 	if !remaining {
 		return res, nil
@@ -257,7 +329,7 @@ func (cs *ThriftRpcImpl) GetIterator(req *gen.IteratorRequest) (*gen.IteratorRes
 	skipKeys := int32(0)
 	lastKey := it.Key()
 
-	if toSkip := req.GetSkipKeys(); toSkip > 0 {
+	if toSkip := req.SkipKeys; toSkip > 0 {
 		for i := int32(0); i < toSkip && remaining; i++ {
 			if bytes.Equal(lastKey, it.Key()) {
 				skipKeys = skipKeys + 1
@@ -267,7 +339,7 @@ func (cs *ThriftRpcImpl) GetIterator(req *gen.IteratorRequest) (*gen.IteratorRes
 
 			lastKey = it.Key()
 
-			remaining, err = it.Next()
+			remaining, err = next()
 			if err != nil {
 				return nil, err
 			}
@@ -278,16 +350,16 @@ func (cs *ThriftRpcImpl) GetIterator(req *gen.IteratorRequest) (*gen.IteratorRes
 	}
 
 	if req.EndKey != nil {
-		remaining = remaining && !hfile.After(it.Key(), req.EndKey)
+		remaining = remaining && !pastEnd(it.Key(), req.EndKey)
 	}
 
-	r := make([]*gen.KeyValueItem, 0)
+	r := make([]KeyValueItem, 0)
 	for i := 0; i < limit && remaining; i++ {
 		v := []byte{}
-		if req.IncludeValues == nil || *req.IncludeValues {
+		if req.IncludeValues {
 			v = it.Value()
 		}
-		r = append(r, &gen.KeyValueItem{it.Key(), v})
+		r = append(r, KeyValueItem{it.Key(), v})
 
 		if bytes.Equal(lastKey, it.Key()) {
 			skipKeys = skipKeys + 1
@@ -296,93 +368,128 @@ func (cs *ThriftRpcImpl) GetIterator(req *gen.IteratorRequest) (*gen.IteratorRes
 		}
 		lastKey = it.Key()
 
-		remaining, err = it.Next()
+		remaining, err = next()
 		if err != nil {
 			return nil, err
 		}
 		if req.EndKey != nil {
-			remaining = remaining && !hfile.After(it.Key(), req.EndKey)
+			remaining = remaining && !pastEnd(it.Key(), req.EndKey)
 		}
 	}
-	return &gen.IteratorResponse{r, lastKey, &skipKeys}, nil
+	return &IteratorResponse{r, lastKey, skipKeys}, nil
 }
 
-func GetCollectionInfo(r *hfile.Reader, keySampleSize int) (*gen.HFileInfo, error) {
-	i := new(gen.HFileInfo)
-	i.Name = &r.Name
-	i.Path = &r.SourcePath
-	c := int64(r.EntryCount)
-	i.NumElements = &c
-	i.FirstKey, _ = r.FirstKey()
+/*
+Scan calls emit with each pair the request selects, in key order, stopping if emit returns an error.
+Keys and values are only valid until emit returns, and values are nil if only keys were requested.
+*/
+func (cs *RpcShared) Scan(req ScanRequest, emit func(key, value []byte) error) error {
+	if Settings.debug {
+		log.Printf("[Scan] %s (%d prefixes)\n", req.HfileName, len(req.Prefixes))
+	}
+	reader, err := cs.readerFor(req.HfileName)
+	if err != nil {
+		return err
+	}
 
-	if keySampleSize > 0 {
-		it := r.GetIterator()
-		defer it.Release()
-
-		pr := float64(keySampleSize) / float64(c)
-		buf := make([][]byte, keySampleSize)
-		found := 0
-		next, err := it.Next()
-		for next && found < keySampleSize {
-			if rand.Float64() < pr {
-				buf[found] = it.Key()
-				found++
+	prefixes := [][]byte{nil}
+	if req.Prefixes != nil {
+		prefixes = disjointPrefixes(req.Prefixes)
+	}
+
+	sent := 0
+	for _, prefix := range prefixes {
+		limit := 0
+		if req.Limit > 0 {
+			if limit = req.Limit - sent; limit <= 0 {
+				break
 			}
-			next, err = it.Next()
-			if err != nil {
-				return nil, err
+		}
+		err := reader.ScanRange(hfile.KeyRange{Start: req.StartKey, End: req.EndKey, Prefix: prefix}, limit, func(k, v []byte) error {
+			sent++
+			if req.KeysOnly {
+				v = nil
 			}
+			return emit(k, v)
+		})
+		if err != nil {
+			return err
 		}
-		buf = buf[:found]
-		i.RandomKeys = buf
 	}
-	if Settings.debug {
-		log.Printf("[GetCollectionInfo] %v (%d keys)\n", i, len(i.RandomKeys))
+	return nil
+}
+
+// disjointPrefixes returns the prefixes sorted, without any that another prefix already covers (e.g. "ab" if there's also "a").
+func disjointPrefixes(prefixes [][]byte) [][]byte {
+	sorted := make([][]byte, len(prefixes))
+	copy(sorted, prefixes)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+
+	res := sorted[:0]
+	for _, p := range sorted {
+		if len(res) == 0 || !bytes.HasPrefix(p, res[len(res)-1]) {
+			res = append(res, p)
+		}
 	}
-	return i, nil
+	return res
 }
 
-func (cs *ThriftRpcImpl) getInfo(req *gen.InfoRequest, allowRandom bool) (r []*gen.HFileInfo, err error) {
-	if req == nil {
-		return nil, fmt.Errorf("null request!")
+func GetCollectionInfo(r *hfile.Reader, keySampleSize int) (*HFileInfo, error) {
+	i := &HFileInfo{
+		Name:              r.Name,
+		Path:              r.SourcePath,
+		NumElements:       int64(r.EntryCount),
+		NumBlocks:         int32(r.NumBlocks()),
+		CompressedBytes:   int64(r.CompressedDataBytes()),
+		UncompressedBytes: int64(r.TotalUncompressedDataBytes),
+		Codec:             hfile.CompressionCodecName(r.CompressionCodec),
+		LoadMethod:        r.LoadMethod.String(),
+		Bloom:             r.BloomState(),
+		FileInfo:          r.InfoFields,
 	}
-	if Settings.debug {
-		log.Println("[GetInfo]", req.GetHfileName())
+	i.FirstKey, _ = r.FirstKey()
+	if last, err := r.LastKey(); err != nil {
+		log.Printf("[GetCollectionInfo] Error finding last key of %s: %v\n", r.Name, err)
+	} else {
+		i.LastKey = last
 	}
-	require := ""
-	if req.IsSetHfileName() {
-		require := req.GetHfileName()
-		if require != "" && !strings.ContainsRune(require, '/') {
-			require = require + "/"
+
+	if keySampleSize > 0 {
+		sample, err := r.SampleKeys(keySampleSize)
+		if err != nil {
+			return nil, err
 		}
+		i.RandomKeys = sample
+	}
+	if Settings.debug {
+		log.Printf("[GetCollectionInfo] %v (%d keys)\n", i, len(i.RandomKeys))
 	}
+	return i, nil
+}
 
-	sample := 0
-	if req.IsSetNumRandomKeys() && allowRandom {
-		sample = int(*req.NumRandomKeys)
+// GetInfo describes the named collection and any under it (e.g. "name/part-1"), or every collection if no name is given.
+func (cs *RpcShared) GetInfo(req InfoRequest) ([]*HFileInfo, error) {
+	if Settings.debug {
+		log.Println("[GetInfo]", req.HfileName)
 	}
+	under := strings.TrimSuffix(req.HfileName, "/") + "/"
+	collections := cs.CollectionSet().Collections
 
-	for name, reader := range cs.Collections {
-		if require == "" || strings.HasPrefix(name, require) {
-			if i, err := GetCollectionInfo(reader, sample); err != nil {
-				return nil, err
-			} else {
-				r = append(r, i)
-			}
+	names := make([]string, 0, len(collections))
+	for name := range collections {
+		if req.HfileName == "" || name == req.HfileName || strings.HasPrefix(name, under) {
+			names = append(names, name)
 		}
 	}
+	sort.Strings(names)
 
+	var r []*HFileInfo
+	for _, name := range names {
+		i, err := GetCollectionInfo(collections[name], req.NumRandomKeys)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, i)
+	}
 	return r, nil
 }
-
-func (cs *ThriftRpcImpl) GetInfo(req *gen.InfoRequest) (r []*gen.HFileInfo, err error) {
-	return cs.getInfo(req, false)
-}
-
-func (cs *ThriftRpcImpl) ScanCollectionAndSampleKeys(req *gen.InfoRequest) (r []*gen.HFileInfo, err error) {
-	return cs.getInfo(req, true)
-}
-
-func (cs *ThriftRpcImpl) TestTimeout(waitInMillis int32) (r int32, err error) {
-	return 0, fmt.Errorf("Not implemented")
-}
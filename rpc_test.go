@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"testing"
 
+	"github.com/foursquare/quiver/gen"
 	"github.com/foursquare/quiver/hfile"
 )
 
@@ -47,6 +48,128 @@ func TestGetValuesSingle(t *testing.T) {
 
 }
 
+func TestGetInfo(t *testing.T) {
+	Setup(t)
+	name := "compressed"
+	res, err := compressed.GetInfo(&gen.InfoRequest{&name, nil})
+	if err != nil {
+		t.Fatal("error: ", err)
+	}
+	if len(res) != 1 {
+		t.Fatal("wrong number of results: ", len(res))
+	}
+	info := res[0]
+
+	if !bytes.Equal(info.GetFirstKey(), hfile.MockKeyInt(0)) {
+		t.Fatalf("wrong first key: %v", info.GetFirstKey())
+	}
+	if !bytes.Equal(info.GetLastKey(), hfile.MockKeyInt(maxKey-1)) {
+		t.Fatalf("wrong last key: %v", info.GetLastKey())
+	}
+	if info.GetNumBlocks() < 2 {
+		t.Fatal("expected multiple blocks, got ", info.GetNumBlocks())
+	}
+	if info.GetCompressedBytes() <= 0 || info.GetCompressedBytes() >= info.GetUncompressedBytes() {
+		t.Fatalf("bad sizes: %d compressed, %d uncompressed", info.GetCompressedBytes(), info.GetUncompressedBytes())
+	}
+	if info.GetCodec() != "snappy" || info.GetLoadMethod() != "CopiedToMem" || info.GetBloom() != "none" {
+		t.Fatalf("wrong codec, load method or bloom: %s, %s, %s", info.GetCodec(), info.GetLoadMethod(), info.GetBloom())
+	}
+	if _, ok := info.GetFileInfo()[hfile.FileInfoLastKey]; !ok {
+		t.Fatal("missing FileInfo fields: ", info.GetFileInfo())
+	}
+
+	// Only the named collection and those under it match, not others it happens to prefix.
+	partial := "compress"
+	if res, err := compressed.GetInfo(&gen.InfoRequest{HfileName: &partial}); err != nil || len(res) != 0 {
+		t.Fatal("expected no results for a partial name: ", res, err)
+	}
+
+	sample := int64(100)
+	res, err = compressed.ScanCollectionAndSampleKeys(&gen.InfoRequest{&name, &sample})
+	if err != nil {
+		t.Fatal("error: ", err)
+	}
+	if n := len(res[0].GetRandomKeys()); n < 50 || n > 100 {
+		t.Fatal("wrong number of random keys: ", n)
+	}
+}
+
+func TestGetIteratorReverse(t *testing.T) {
+	Setup(t)
+	name, limit, reverse := "uncompressed", int32(10), true
+	req := &gen.IteratorRequest{HfileName: &name, ResponseLimit: &limit, Reverse: &reverse}
+	req.LastKey, req.EndKey = hfile.MockKeyInt(1000), hfile.MockKeyInt(980)
+
+	expected := 1000
+	for page := 0; page < 3; page++ {
+		res, err := uncompressed.GetIterator(req)
+		if err != nil {
+			t.Fatal("error: ", err)
+		}
+		for _, kv := range res.GetValues() {
+			if !bytes.Equal(kv.GetKey(), hfile.MockKeyInt(expected)) {
+				t.Fatalf("wrong key on page %d: %v, expected %d", page, kv.GetKey(), expected)
+			}
+			expected--
+		}
+		req.LastKey, req.SkipKeys = res.GetLastKey(), res.SkipKeys
+	}
+	if expected != 979 {
+		t.Fatal("reverse iteration should stop at the end key, stopped at ", expected+1)
+	}
+}
+
+func TestGetIteratorLimit(t *testing.T) {
+	Setup(t)
+	name, limit := "uncompressed", int32(0)
+	req := &gen.IteratorRequest{HfileName: &name, ResponseLimit: &limit}
+
+	res, err := uncompressed.GetIterator(req)
+	if err != nil {
+		t.Fatal("a limit of 0 should get an empty page, not an error: ", err)
+	}
+	if len(res.GetValues()) != 0 {
+		t.Fatal("a limit of 0 should get no values, got ", len(res.GetValues()))
+	}
+
+	req.ResponseLimit = nil
+	if _, err := uncompressed.GetIterator(req); err == nil {
+		t.Fatal("a missing limit should be an error")
+	}
+}
+
+func TestGetValuesMultiSplitKeys(t *testing.T) {
+	Setup(t)
+	name := "compressed"
+	keys := make([][]byte, 40)
+	for i := range keys {
+		keys[i] = []byte{byte(i)}
+	}
+	req := &gen.MultiHFileSplitKeyRequest{HfileName: &name, SplitKey: [][][]byte{{{0}}, keys[:2], keys, keys}}
+
+	res, err := compressed.GetValuesMultiSplitKeys(req)
+	if err != nil {
+		t.Fatal("error: ", err)
+	}
+	if len(res.GetValues()) != 2*40*40 {
+		t.Fatal("wrong number of results: ", len(res.GetValues()))
+	}
+	for k, v := range res.GetValues() {
+		if expected := hfile.MockValueInt(int(binary.BigEndian.Uint32([]byte(k)))); !bytes.Equal(v[0], expected) {
+			t.Fatalf("mismatched value for key %v: found '%v' expected '%v'", []byte(k), v[0], expected)
+		}
+	}
+
+	Settings.maxSplitKeyProduct = 1000
+	defer func() { Settings.maxSplitKeyProduct = 0 }()
+	if _, err := compressed.GetValuesMultiSplitKeys(req); err == nil {
+		t.Fatal("expected an error for too many split keys")
+	} else if _, ok := err.(*gen.HFileServiceException); !ok {
+		t.Fatalf("expected an HFileServiceException, got %T: %v", err, err)
+	}
+}
+
 func BenchmarkHandlerUncompressed(b *testing.B) {
 	b.StopTimer()
 	Setup(b)
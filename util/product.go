@@ -15,3 +15,21 @@ func RevProduct(l [][][]byte) [][][]byte {
 	}
 	return ret
 }
+
+// ProductSize returns how many items RevProduct(l) would return, or limit if that is fewer.
+func ProductSize(l [][][]byte, limit int) int {
+	size := 1
+	for _, i := range l {
+		if len(i) == 0 {
+			return 0
+		}
+		if size > limit/len(i) {
+			return limit
+		}
+		size *= len(i)
+	}
+	if size > limit {
+		return limit
+	}
+	return size
+}
@@ -46,3 +46,18 @@ func TestProduct(t *testing.T) {
 		t.Fatalf("wrong product at pos 3: %v instead of %v", p[3], [][]byte{b2, b4})
 	}
 }
+
+func TestProductSize(t *testing.T) {
+	chunk := [][]byte{{1}, {2}, {3}}
+	chunks := [][][]byte{chunk, chunk, chunk}
+
+	if s := ProductSize(chunks, 100); s != 27 {
+		t.Fatalf("wrong product size: %d (expected %d)", s, 27)
+	}
+	if s := ProductSize(chunks, 10); s != 10 {
+		t.Fatalf("product size should be capped: %d (expected %d)", s, 10)
+	}
+	if s := ProductSize(append(chunks, nil), 100); s != 0 {
+		t.Fatalf("wrong product size with an empty chunk: %d (expected %d)", s, 0)
+	}
+}